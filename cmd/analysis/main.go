@@ -18,7 +18,11 @@ import (
 	"github.com/nel349/bz-findata/internal/analysis/orders"
 	"github.com/nel349/bz-findata/internal/analysis/supabase"
 	"github.com/nel349/bz-findata/internal/analysis/task"
+	pkgdatabase "github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/health"
+	"github.com/nel349/bz-findata/pkg/metrics"
 	"github.com/robfig/cron/v3"
+	supabaseclient "github.com/supabase-community/supabase-go"
 )
 
 type ScheduledTask struct {
@@ -58,6 +62,19 @@ func main() {
 		log.Fatalf("failed config init: %v", err)
 	}
 
+	// schema of the analytics database
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(ctx, cfg, os.Args[2:]); err != nil {
+			log.Fatalf("failed migration: %v", err)
+		}
+		return
+	}
+	if cfg.Database.Migrate {
+		if err := migrate(ctx, cfg, nil); err != nil {
+			log.Fatalf("failed migration: %v", err)
+		}
+	}
+
 	// Setup dependencies
 	if err := run(cfg); err != nil {
 		log.Fatalf("Application failed to start: %v", err)
@@ -67,6 +84,7 @@ func main() {
 func run(cfg *config.AnalysisConfig) error {
 	// Initialize dependencies
 	db, err := database.NewConnection(
+		cfg.Database.Driver,
 		cfg.Database.Host,
 		cfg.Database.User,
 		cfg.Database.Password,
@@ -77,10 +95,18 @@ func run(cfg *config.AnalysisConfig) error {
 	}
 	defer db.Close()
 
-	// Initialize services
-	supabaseRepo := supabase.NewSupabaseRepository()
-	analysisService := analysis.NewService(db, supabaseRepo.Client)
-	dexService := dex.NewService(db, supabaseRepo.Client)
+	// Initialize services, on postgres and sqlite the analytics read the collected data
+	// directly and nothing is copied to Supabase
+	var supabaseClient *supabaseclient.Client
+	if cfg.Database.Driver == pkgdatabase.MySQL {
+		supabaseRepo, err := supabase.NewSupabaseRepository(context.Background())
+		if err != nil {
+			return err
+		}
+		supabaseClient = supabaseRepo.Client
+	}
+	analysisService := analysis.NewService(db, supabaseClient)
+	dexService := dex.NewService(db, supabaseClient)
 	
 	// Initialize handlers
 	orderHandler := handlers.NewOrderHandler(analysisService)
@@ -96,7 +122,14 @@ func run(cfg *config.AnalysisConfig) error {
 	taskService := task.NewService(analysisService)
 	taskManager := scheduler.NewTaskManager(taskService)
 
+	// health
+	checker := health.NewChecker()
+	checker.Add("database", health.Ping(db.PingContext))
+	checker.Add("scheduler", taskManager.Check)
+
 	// Routes
+	r.Handle(metrics.Path, metrics.Handler())
+	checker.Mount(r)
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Route("/btc", func(r chi.Router) {
 
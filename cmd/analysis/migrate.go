@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/database/postgres"
+	"github.com/nel349/bz-findata/pkg/migrations"
+)
+
+// migrate runs the migrate subcommand args on the database of the analytics,
+// the database itself on postgres and sqlite or the Supabase one otherwise. No args applies the pending migrations
+func migrate(ctx context.Context, cfg *config.AnalysisConfig, args []string) error {
+	var db *sqlx.DB
+	dialect := migrations.Postgres
+	switch {
+	case cfg.Database.Driver == database.Postgres || cfg.Database.Driver == database.SQLite:
+		conn, err := database.Connect(cfg.Database.Driver, cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
+		if err != nil {
+			return err
+		}
+		db = conn
+		dialect = cfg.Database.Driver
+	case cfg.SupabaseDB != "":
+		dbClient, err := postgres.NewPostgresClient(cfg.SupabaseDB)
+		if err != nil {
+			return err
+		}
+		db = dbClient.DB
+	default:
+		return fmt.Errorf("SUPABASE_DB_URL is required to migrate")
+	}
+	defer db.Close()
+
+	return migrations.Command(ctx, db, dialect, args, os.Stdout)
+}
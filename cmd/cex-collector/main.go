@@ -23,5 +23,27 @@ func main() {
 		log.Fatalf("failed config init: %v", err)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay-dead-letters":
+			if err := app.ReplayDeadLetters(ctx, cfg); err != nil {
+				log.Fatalf("failed dead letter replay: %v", err)
+			}
+			return
+		case "replay-feed":
+			if err := app.Replay(ctx, cfg); err != nil {
+				log.Fatalf("failed feed replay: %v", err)
+			}
+			return
+		case "migrate":
+			if err := app.Migrate(ctx, cfg, os.Args[2:]); err != nil {
+				log.Fatalf("failed migration: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("unknown command %q, expected replay-dead-letters, replay-feed or migrate", os.Args[1])
+		}
+	}
+
 	app.Run(ctx, cfg)
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/dex/metrics"
+	"github.com/nel349/bz-findata/pkg/bus"
+)
+
+// chain of the swaps, their topic
+const chain = "ethereum"
+
+// newPublisher connects the publisher of the swap events to the bus of cfg,
+// the events of the embedded broker are logged so a local run shows what is published
+func newPublisher(cfg config.BusConfig) (*bus.Publisher, error) {
+	format, err := bus.ParseFormat(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	broker, err := bus.NewBroker(cfg.Protocol, cfg.URL, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if embedded, ok := broker.(*bus.EmbeddedBroker); ok {
+		events, _ := embedded.Subscribe(">", cfg.MaxPending)
+		go func() {
+			for msg := range events {
+				event, err := bus.Decode(format, msg.Payload)
+				if err != nil {
+					log.Printf("failed to decode published event: %v", err)
+					continue
+				}
+				b, _ := bus.Encode(bus.JSON, event)
+				log.Printf("published to %s: %s", msg.Topic, b)
+			}
+		}()
+	}
+
+	return bus.NewPublisher(broker, format, cfg.TopicPrefix, chain, cfg.MaxPending, func(err error) {
+		metrics.PublishFailures.Inc()
+		log.Printf("failed to publish swap event: %v", err)
+	}), nil
+}
@@ -4,20 +4,31 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/nel349/bz-findata/config"
 	"github.com/nel349/bz-findata/internal/dex/eth/uniswap/decoder"
+	"github.com/nel349/bz-findata/internal/dex/metrics"
 	"github.com/nel349/bz-findata/internal/dex/repository"
-	"github.com/nel349/bz-findata/pkg/database/mysql"
+	"github.com/nel349/bz-findata/pkg/archive"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/health"
+	pkgmetrics "github.com/nel349/bz-findata/pkg/metrics"
+	"github.com/nel349/bz-findata/pkg/migrations"
 )
 
 const (
+	// node followed for new blocks
+	nodeURL = "wss://ethereum-mainnet.core.chainstack.com/52fe0d05347a608831b02990cf1de889"
+
 	// Uniswap V2 Router address
 	UniswapRouterAddress = "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"
 
@@ -36,41 +47,140 @@ func main() {
 		cancel()
 	}()
 
-	// Connect to your WSS endpoint
-	client, err := ethclient.Dial("wss://ethereum-mainnet.core.chainstack.com/52fe0d05347a608831b02990cf1de889")
-	if err != nil {
-		log.Fatal(err)
-	}
-
 	cfg, err := config.NewDexConfig(ctx)
 	if err != nil {
 		log.Fatalf("failed config init: %v", err)
 	}
 
 	// database
-	dbClient, err := mysql.NewMysqlClient(cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
+	db, err := database.Connect(cfg.Database.Driver, cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
 	if err != nil {
 		log.Fatalf("failed database init: %v", err)
 	}
-	defer dbClient.CloseConnect()
+	defer db.Close()
+
+	// schema
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrations.Command(ctx, db, cfg.Database.Driver, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("failed migration: %v", err)
+		}
+		return
+	}
+	if cfg.Database.Migrate {
+		done, err := migrations.Up(ctx, db, cfg.Database.Driver)
+		for _, migration := range done {
+			log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("failed migration: %v", err)
+		}
+	}
+
+	// health
+	checker := health.NewChecker()
+	checker.Add("database", health.Ping(db.PingContext))
+	node := health.NewState()
+	checker.Add("node", node.Check)
+	blocks := health.NewActivity(cfg.Health.MaxIdle)
+	checker.Add("blocks", blocks.Check)
+
+	// metrics and probes
+	mux := http.NewServeMux()
+	checker.Mount(mux)
+	go func() {
+		if err := pkgmetrics.Serve(ctx, cfg.Metrics.Addr, mux); err != nil {
+			log.Printf("metrics endpoint stopped: %v", err)
+		}
+	}()
+
+	// swaps, also archived to Parquet with an archive dir
+	var sinks []repository.SwapSink
+	if cfg.Archive.Dir != "" {
+		parquet, err := archive.NewArchive(cfg.Archive.Dir, cfg.Archive.Layout, cfg.Archive.CloseDelay)
+		if err != nil {
+			log.Fatalf("failed archive init: %v", err)
+		}
+		archiveDone := make(chan struct{})
+		go func() {
+			defer close(archiveDone)
+			if err := parquet.Run(ctx); err != nil {
+				log.Printf("archive stopped: %v", err)
+			}
+		}()
+		// the open files are closed before exiting
+		defer func() { <-archiveDone }()
+		sinks = append(sinks, parquet)
+	}
+	// and published to the message bus with a bus protocol
+	if cfg.Bus.Protocol != "" {
+		publisher, err := newPublisher(cfg.Bus)
+		if err != nil {
+			log.Fatalf("failed bus init: %v", err)
+		}
+		busDone := make(chan struct{})
+		go func() {
+			defer close(busDone)
+			if err := publisher.Run(ctx); err != nil {
+				log.Printf("bus publisher stopped: %v", err)
+			}
+		}()
+		// the queued events are published before exiting
+		defer func() { <-busDone }()
+		sinks = append(sinks, publisher)
+	}
+	dexRepositories := repository.NewDexRepositories(db, sinks...)
+
+	fmt.Println("Starting to monitor Uniswap swaps...")
+
+	// a lost node is reported degraded and followed again after a backoff instead of exiting
+	backoff := exchange.NewBackoff(time.Second, time.Minute)
+	for attempt := 1; ; attempt++ {
+		err := followHeads(ctx, dexRepositories, blocks, func() { node.Set(health.OK, nil); attempt = 0 })
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := backoff.Delay(attempt)
+		node.Set(health.Degraded, err)
+		log.Printf("Lost the node, following again in %s: %v", delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// followHeads processes every new block until the subscription fails or ctx is done,
+// subscribed is called once the node is followed
+func followHeads(ctx context.Context, dexRepositories *repository.DexRepositories, blocks *health.Activity, subscribed func()) error {
+	// Connect to your WSS endpoint
+	client, err := ethclient.DialContext(ctx, nodeURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
 
 	// Create a channel for new headers
 	headers := make(chan *types.Header)
 
 	// Subscribe to new block headers
-	sub, err := client.SubscribeNewHead(context.Background(), headers)
+	sub, err := client.SubscribeNewHead(ctx, headers)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-
-	fmt.Println("Starting to monitor Uniswap swaps...")
+	defer sub.Unsubscribe()
+	subscribed()
 
 	for {
 		select {
 		case err := <-sub.Err():
-			log.Fatal(err)
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
 		case header := <-headers:
-			dexRepositories := repository.NewDexRepositories(dbClient.DB)
+			blocks.Touch()
 			go processBlock(client, header, dexRepositories)
 		}
 	}
@@ -84,6 +194,7 @@ func processBlock(client *ethclient.Client, header *types.Header, dexRepositorie
 	}
 
 	fmt.Printf("Processing block: %d\n", block.Number().Uint64())
+	defer metrics.BlocksProcessed.Inc()
 
 	// Process each transaction in the block
 	for _, tx := range block.Transactions() {
@@ -115,7 +226,9 @@ func processBlock(client *ethclient.Client, header *types.Header, dexRepositorie
 			fmt.Println("-----------------------------------------------------")
 			if ethValue >= threshold {
 				// Save to database
-				dexRepositories.SaveSwap(context.Background(), tx, version)
+				if err := dexRepositories.SaveSwap(context.Background(), tx, version); err != nil {
+					log.Printf("Error saving swap %s: %v", tx.Hash().Hex(), err)
+				}
 			}
 
 			// fmt.Println("Chain ID: ", tx.ChainId().Uint64())
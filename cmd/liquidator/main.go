@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	// "math/big"
 	"strings"
@@ -14,6 +18,8 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/nel349/bz-findata/pkg/health"
+	"github.com/nel349/bz-findata/pkg/metrics"
 )
 
 // Contract ABIs as strings
@@ -35,18 +41,63 @@ const poolAddress = "0x794a61358D6845594F94dc1DB02A252b5b4814aD"
 func main() {
 	log.Println("Starting liquidator service")
 
-	client, err := ethclient.Dial(os.Getenv("ARBITRUM_RPC_URL"))
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer cancel()
+
+	scanInterval := time.Minute
+	if value := os.Getenv("SCAN_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid SCAN_INTERVAL %q: %v", value, err)
+		}
+		scanInterval = interval
+	}
+
+	// health of the scans, a failed scan is reported and retried instead of exiting
+	checker := health.NewChecker()
+	scan := health.NewState()
+	checker.Add("scan", scan.Check)
+
+	// metrics and probes, served while the positions are monitored
+	metricsAddr, ok := os.LookupEnv("METRICS_ADDR")
+	if !ok {
+		metricsAddr = ":2112"
+	}
+	mux := http.NewServeMux()
+	checker.Mount(mux)
+	go func() {
+		if err := metrics.Serve(ctx, metricsAddr, mux); err != nil {
+			log.Printf("metrics endpoint stopped: %v", err)
+		}
+	}()
+
+	for {
+		if err := scanPositions(ctx); err != nil {
+			scan.Set(health.Degraded, err)
+			log.Printf("Error monitoring liquidatable positions: %v", err)
+		} else {
+			scan.Set(health.OK, nil)
+		}
+
+		select {
+		case <-time.After(scanInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scanPositions connects to the network and checks the positions once
+func scanPositions(ctx context.Context) error {
+	client, err := ethclient.DialContext(ctx, os.Getenv("ARBITRUM_RPC_URL"))
 	if err != nil {
-		log.Fatalf("Failed to connect to the Arbitrum network: %v", err)
+		return fmt.Errorf("failed to connect to the Arbitrum network: %w", err)
 	}
 	defer client.Close()
 
 	log.Println("Connected to Arbitrum network")
 
-	err = monitorLiquidatablePositions(client)
-	if err != nil {
-		log.Fatalf("Error monitoring liquidatable positions: %v", err)
-	}
+	return monitorLiquidatablePositions(client)
 }
 
 type ReserveConfiguration struct {
@@ -125,6 +176,7 @@ func monitorLiquidatablePositions(client *ethclient.Client) error {
 		}, nil)
 		if err != nil {
 			log.Printf("Error calling getReserveData for asset %s: %v", assetAddress.Hex(), err)
+			callErrors.WithLabelValues("getReserveData").Inc()
 			continue
 		}
 
@@ -212,6 +264,7 @@ func monitorLiquidatablePositions(client *ethclient.Client) error {
 			}, nil)
 			if err != nil {
 				log.Printf("Error calling getUserAccountData for user %s: %v", userAddress.Hex(), err)
+				callErrors.WithLabelValues("getUserAccountData").Inc()
 				continue
 			}
 
@@ -229,6 +282,7 @@ func monitorLiquidatablePositions(client *ethclient.Client) error {
 				log.Printf("Error unpacking getUserAccountData for user %s: %v", userAddress.Hex(), err)
 				continue
 			}
+			positionsChecked.Inc()
 
 			// To compare with 1, we multiply by 10^18
 			one := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
@@ -240,6 +294,7 @@ func monitorLiquidatablePositions(client *ethclient.Client) error {
 			// Less than 1.07
 			// liquidationThreshold107 := new(big.Int).Mul(big.NewInt(107), new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil))
 			if userData.HealthFactor.Cmp(one) < 0 {
+				positionsLiquidatable.Inc()
 				// Convert health factor to human-readable form (division by 10^18)
 				healthFactorFloat := new(big.Float).Quo(
 					new(big.Float).SetInt(userData.HealthFactor),
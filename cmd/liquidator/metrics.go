@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// positionsChecked counts the health factors read
+	positionsChecked = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "liquidator_positions_checked_total",
+		Help: "User positions whose health factor was read.",
+	})
+
+	// positionsLiquidatable counts the positions found with a health factor below 1
+	positionsLiquidatable = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "liquidator_positions_liquidatable_total",
+		Help: "User positions found with a health factor below 1.",
+	})
+
+	// callErrors counts the failed contract calls
+	callErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "liquidator_call_errors_total",
+		Help: "Failed contract calls by method.",
+	}, []string{"method"})
+)
@@ -2,28 +2,53 @@ package config
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"os"
+	"time"
 
-	awslocal "github.com/nel349/bz-findata/pkg/aws"
+	"github.com/nel349/bz-findata/pkg/secrets"
 	"github.com/sethvargo/go-envconfig"
 )
 
 // Config default config structure
 type Config struct {
 	Exchange ExchangeConfig `env:",prefix=EXCHANGE_,required"`
+	Binance  BinanceConfig  `env:",prefix=BINANCE_"`
 	Database DatabaseConfig `env:",prefix=DB_,required"`
 	Logger   LoggerConfig   `env:",prefix=LOGGER_"`
+	Filter   FilterConfig   `env:",prefix=FILTER_"`
+	Book     BookConfig     `env:",prefix=BOOK_"`
+	Candle   CandleConfig   `env:",prefix=CANDLE_"`
+	Batch    BatchConfig    `env:",prefix=BATCH_"`
+
+	DeadLetter DeadLetterConfig `env:",prefix=DEAD_LETTER_"`
+	Record     RecordConfig     `env:",prefix=RECORD_"`
+	Replay     ReplayConfig     `env:",prefix=REPLAY_"`
+	Metrics    MetricsConfig    `env:",prefix=METRICS_"`
+	Health     HealthConfig     `env:",prefix=HEALTH_"`
+	Archive    ArchiveConfig    `env:",prefix=ARCHIVE_"`
+	Bus        BusConfig        `env:",prefix=BUS_"`
+	Secrets    SecretsConfig    `env:",prefix=SECRETS_"`
 }
 
 // AnalysisConfig for analysis configuration
 type AnalysisConfig struct {
 	Database DatabaseConfig `env:",prefix=DB_,required"`
+	// SupabaseDB is the postgres connection string of the Supabase database, needed by its migrations
+	// when Database is mysql
+	SupabaseDB string        `env:"SUPABASE_DB_URL"`
+	Secrets    SecretsConfig `env:",prefix=SECRETS_"`
 }
 
 // DexConfig for dex configuration
 type DexConfig struct {
 	Database DatabaseConfig `env:",prefix=DB_,required"`
+	Metrics  MetricsConfig  `env:",prefix=METRICS_"`
+	Health   HealthConfig   `env:",prefix=HEALTH_"`
+	Archive  ArchiveConfig  `env:",prefix=ARCHIVE_"`
+	Bus      BusConfig      `env:",prefix=BUS_"`
+	Secrets  SecretsConfig  `env:",prefix=SECRETS_"`
 }
 
 // LoggerConfig for logger configuration
@@ -35,19 +60,140 @@ type LoggerConfig struct {
 
 // ExchangeConfig for exchange configuration
 type ExchangeConfig struct {
-	Url      string   `env:"URL,required"`
-	Origin   string   `env:"ORIGIN,required"`
-	Protocol string   `env:"PROTOCOL,default="`
-	Symbols  []string `env:"SYMBOLS,required"`
-	Channels []string `env:"CHANNELS,required"`
+	Url          string   `env:"URL,required"`
+	Origin       string   `env:"ORIGIN,required"`
+	Protocol     string   `env:"PROTOCOL,default="`
+	Symbols      []string `env:"SYMBOLS,required"`
+	Channels     []string `env:"CHANNELS,required"`
+	RestUrl      string   `env:"REST_URL,default=https://api.exchange.coinbase.com"`
+	MaxFrameSize int      `env:"MAX_FRAME_SIZE,default=1048576"` // bytes
+
+	// reconnect backoff doubles from ReconnectDelay up to ReconnectMaxDelay,
+	// ReconnectAttempts before the feed is reported down, 0 never reports it down.
+	// Reconnecting goes on either way
+	ReconnectDelay    time.Duration `env:"RECONNECT_DELAY,default=1s"`
+	ReconnectMaxDelay time.Duration `env:"RECONNECT_MAX_DELAY,default=1m"`
+	ReconnectAttempts int           `env:"RECONNECT_ATTEMPTS,default=0"`
+
+	// products moved to the Advanced Trade feed, the other Symbols stay on the Exchange feed
+	AdvancedSymbols  []string `env:"ADVANCED_SYMBOLS"`
+	AdvancedUrl      string   `env:"ADVANCED_URL,default=wss://advanced-trade-ws.coinbase.com"`
+	AdvancedChannels []string `env:"ADVANCED_CHANNELS,default=market_trades,level2,ticker,candles"`
+}
+
+// BinanceConfig for the Binance spot feed, the feed is off without Symbols
+type BinanceConfig struct {
+	Symbols      []string `env:"SYMBOLS"` // product ids such as BTC-USDT
+	Url          string   `env:"URL,default=wss://stream.binance.com:9443/stream"`
+	Streams      []string `env:"STREAMS,default=trade,bookTicker,depth20@100ms"`
+	MaxFrameSize int      `env:"MAX_FRAME_SIZE,default=1048576"` // bytes
+
+	// a ping is sent every PingInterval and the connection is dropped after PongTimeout
+	// without reading anything, pongs included
+	PingInterval time.Duration `env:"PING_INTERVAL,default=20s"`
+	PongTimeout  time.Duration `env:"PONG_TIMEOUT,default=1m"`
+
+	ReconnectDelay    time.Duration `env:"RECONNECT_DELAY,default=1s"`
+	ReconnectMaxDelay time.Duration `env:"RECONNECT_MAX_DELAY,default=1m"`
+	ReconnectAttempts int           `env:"RECONNECT_ATTEMPTS,default=0"`
+}
+
+// FilterConfig for order filter rules configuration
+type FilterConfig struct {
+	RulesFile      string        `env:"RULES_FILE"`
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL,default=30s"`
+	MaxRateAge     time.Duration `env:"MAX_RATE_AGE,default=5m"` // older USD rates are not used, 0 keeps them
+}
+
+// BookConfig for level2 book snapshots configuration
+type BookConfig struct {
+	SnapshotInterval time.Duration `env:"SNAPSHOT_INTERVAL,default=1m"`
+	Levels           int           `env:"LEVELS,default=50"`
+	DepthBps         float64       `env:"DEPTH_BPS,default=10"`
+	L3               bool          `env:"L3,default=false"` // build books from the full channel
+}
+
+// CandleConfig for candles built from matches
+type CandleConfig struct {
+	Granularities []string      `env:"GRANULARITIES,default=1m,5m,1h,1d"` // bar lengths, days as 1d
+	FlushInterval time.Duration `env:"FLUSH_INTERVAL,default=5s"`
+}
+
+// BatchConfig for batched tick and order writes
+type BatchConfig struct {
+	Size       int           `env:"SIZE,default=500"`
+	Interval   time.Duration `env:"INTERVAL,default=1s"`
+	MaxPending int           `env:"MAX_PENDING,default=50000"` // rows queued before writers block
+	Retries    int           `env:"RETRIES,default=3"`
+	RetryDelay time.Duration `env:"RETRY_DELAY,default=500ms"`
+	Timeout    time.Duration `env:"TIMEOUT,default=5s"`
+}
+
+// DeadLetterConfig for the queue of ticks and orders that could not be stored
+type DeadLetterConfig struct {
+	Path string `env:"PATH,default=dead-letters.jsonl"`
+}
+
+// RecordConfig for recording the raw exchange feed, recording is off without Dir
+type RecordConfig struct {
+	Dir            string        `env:"DIR"`
+	MaxBytes       int64         `env:"MAX_BYTES,default=268435456"` // uncompressed bytes per file
+	RotateInterval time.Duration `env:"ROTATE_INTERVAL,default=1h"`
+}
+
+// ReplayConfig for replaying a recorded feed
+type ReplayConfig struct {
+	Path  string `env:"PATH"`                   // recorded file, directory or glob
+	Speed string `env:"SPEED,default=realtime"` // realtime, max or a multiplier like 10x
+	Feed  string `env:"FEED,default=exchange"`  // feed recorded, exchange, advanced or binance
+}
+
+// MetricsConfig for the Prometheus and health endpoints, the endpoints are off without Addr
+type MetricsConfig struct {
+	Addr string `env:"ADDR,default=:2112"`
+}
+
+// HealthConfig for the readiness checks
+type HealthConfig struct {
+	MaxIdle time.Duration `env:"MAX_IDLE,default=1m"` // without messages or blocks the service is degraded
+}
+
+// ArchiveConfig for the Parquet archive of ticks, orders and swaps, the archive is off without Dir
+type ArchiveConfig struct {
+	Dir        string        `env:"DIR"`
+	Layout     string        `env:"LAYOUT,default={table}/{product}/{date}/{hour}"` // partition directories
+	CloseDelay time.Duration `env:"CLOSE_DELAY,default=5m"`                         // an hour stays open that long for late rows
+}
+
+// BusConfig for publishing the events to a message bus, publishing is off without Protocol
+type BusConfig struct {
+	Protocol    string        `env:"PROTOCOL"`                    // nats, kafka, kafka-rest or embedded
+	URL         string        `env:"URL"`                         // NATS server, Kafka brokers or Kafka REST Proxy
+	Format      string        `env:"FORMAT,default=json"`         // json or protobuf
+	TopicPrefix string        `env:"TOPIC_PREFIX,default=market"` // topics are the prefix and the product or chain
+	MaxPending  int           `env:"MAX_PENDING,default=10000"`   // events queued before new ones are dropped
+	Timeout     time.Duration `env:"TIMEOUT,default=5s"`
+}
+
+// SecretsConfig for the providers of the credentials, looked up in the order of Providers:
+// env, file, mounted (Docker or Kubernetes secret files) and aws
+type SecretsConfig struct {
+	Providers  []string      `env:"PROVIDERS,default=env,mounted,aws"`
+	File       string        `env:"FILE"`                     // JSON object of names to values
+	Dir        string        `env:"DIR,default=/run/secrets"` // a file per secret
+	AWSRegion  string        `env:"AWS_REGION,default=us-east-2"`
+	AWSSecrets []string      `env:"AWS_SECRETS"`        // ids, PREFIX=id prefixes their keys. None turns the aws provider off
+	Refresh    time.Duration `env:"REFRESH,default=5m"` // rotated secrets are picked up after Refresh
 }
 
 // DatabaseConfig for db config
 type DatabaseConfig struct {
-	Host     string `env:"HOST,required"`
-	User     string `env:"USER,required"`
+	Driver   string `env:"DRIVER,default=mysql"` // mysql, postgres or sqlite
+	Host     string `env:"HOST"`                 // host:port, unused by sqlite
+	User     string `env:"USER"`                 // unused by sqlite
 	Password string `env:"PASSWORD"`
-	Base     string `env:"BASE"`
+	Base     string `env:"BASE"`                  // database, the file of sqlite
+	Migrate  bool   `env:"MIGRATE,default=false"` // apply the pending migrations on startup
 }
 
 // NewConfig init default config for application
@@ -57,7 +203,9 @@ func NewConfig(ctx context.Context) (*Config, error) {
 	if err := envconfig.Process(ctx, &cfg); err != nil {
 		return nil, err
 	}
-	setDBPassword(&cfg)
+	if err := setSecrets(ctx, &cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -67,7 +215,9 @@ func NewAnalysisConfig(ctx context.Context) (*AnalysisConfig, error) {
 	if err := envconfig.Process(ctx, &cfg); err != nil {
 		return nil, err
 	}
-	setDBPassword(&cfg)
+	if err := setSecrets(ctx, &cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
@@ -77,30 +227,50 @@ func NewDexConfig(ctx context.Context) (*DexConfig, error) {
 	if err := envconfig.Process(ctx, &cfg); err != nil {
 		return nil, err
 	}
-	setDBPassword(&cfg)
+	if err := setSecrets(ctx, &cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
-func setDBPassword(cfg interface{}) {
+// setSecrets makes the providers of cfg the default ones of the services and looks
+// the database password up
+func setSecrets(ctx context.Context, cfg interface{}) error {
 	var dbConfig *DatabaseConfig
+	var secretsConfig SecretsConfig
 	switch c := cfg.(type) {
 	case *Config:
-		dbConfig = &c.Database
+		dbConfig, secretsConfig = &c.Database, c.Secrets
 	case *AnalysisConfig:
-		dbConfig = &c.Database
+		dbConfig, secretsConfig = &c.Database, c.Secrets
 	case *DexConfig:
-		dbConfig = &c.Database
+		dbConfig, secretsConfig = &c.Database, c.Secrets
 	default:
-		log.Fatal("unsupported config type")
+		return fmt.Errorf("unsupported config type %T", cfg)
 	}
 
-	if os.Getenv("IS_LOCAL") == "true" {
-		dbConfig.Password = os.Getenv("DB_PASSWORD")
-	} else {
-		dbSecret, err := awslocal.GetDefaultDBSecret()
-		if err != nil {
-			log.Fatalf("failed to retrieve DB secret: %v", err)
-		}
-		dbConfig.Password = dbSecret.DB_PASSWORD
+	provider, err := secrets.New(secretsConfig.Providers, secrets.Options{
+		File:       secretsConfig.File,
+		Dir:        secretsConfig.Dir,
+		AWSRegion:  secretsConfig.AWSRegion,
+		AWSSecrets: secretsConfig.AWSSecrets,
+		Refresh:    secretsConfig.Refresh,
+	})
+	if err != nil {
+		return err
+	}
+	secrets.SetDefault(provider)
+
+	// a sqlite file has no password, a local database takes DB_PASSWORD as is
+	if dbConfig.Driver == "sqlite" || os.Getenv("IS_LOCAL") == "true" {
+		return nil
+	}
+	password, err := provider.Get(ctx, "DB_PASSWORD")
+	switch {
+	case err == nil:
+		dbConfig.Password = password
+	case !errors.Is(err, secrets.ErrNotFound):
+		return fmt.Errorf("failed to retrieve DB secret: %w", err)
 	}
+	return nil
 }
@@ -2,8 +2,11 @@ package config
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -36,13 +39,32 @@ func TestNewConfig(t *testing.T) {
 	}{
 		{name: testing.CoverMode(), args: args{ctx: context.Background()}, want: &Config{
 			Exchange: ExchangeConfig{
-				Url:      "wss://ws-feed.exchange.coinbase.com",
-				Origin:   "https://coinbase.com",
-				Protocol: "",
-				Symbols:  []string{"ETH-BTC", "BTC-USD", "BTC-EUR"},
-				Channels: []string{"ticker"},
+				Url:          "wss://ws-feed.exchange.coinbase.com",
+				Origin:       "https://coinbase.com",
+				Protocol:     "",
+				Symbols:      []string{"ETH-BTC", "BTC-USD", "BTC-EUR"},
+				Channels:     []string{"ticker"},
+				RestUrl:      "https://api.exchange.coinbase.com",
+				MaxFrameSize: 1048576,
+
+				ReconnectDelay:    time.Second,
+				ReconnectMaxDelay: time.Minute,
+				ReconnectAttempts: 0,
+
+				AdvancedUrl:      "wss://advanced-trade-ws.coinbase.com",
+				AdvancedChannels: []string{"market_trades", "level2", "ticker", "candles"},
+			},
+			Binance: BinanceConfig{
+				Url:               "wss://stream.binance.com:9443/stream",
+				Streams:           []string{"trade", "bookTicker", "depth20@100ms"},
+				MaxFrameSize:      1048576,
+				PingInterval:      20 * time.Second,
+				PongTimeout:       time.Minute,
+				ReconnectDelay:    time.Second,
+				ReconnectMaxDelay: time.Minute,
 			},
 			Database: DatabaseConfig{
+				Driver:   "mysql",
 				Host:     "localhost:3306",
 				User:     "test_mysql",
 				Password: "a2s_kjlasjd",
@@ -53,6 +75,65 @@ func TestNewConfig(t *testing.T) {
 				DisableStacktrace: true,
 				Level:             "debug",
 			},
+			Filter: FilterConfig{
+				RulesFile:      "",
+				ReloadInterval: 30 * time.Second,
+				MaxRateAge:     5 * time.Minute,
+			},
+			Book: BookConfig{
+				SnapshotInterval: time.Minute,
+				Levels:           50,
+				DepthBps:         10,
+				L3:               false,
+			},
+			Candle: CandleConfig{
+				Granularities: []string{"1m", "5m", "1h", "1d"},
+				FlushInterval: 5 * time.Second,
+			},
+			Batch: BatchConfig{
+				Size:       500,
+				Interval:   time.Second,
+				MaxPending: 50000,
+				Retries:    3,
+				RetryDelay: 500 * time.Millisecond,
+				Timeout:    5 * time.Second,
+			},
+			DeadLetter: DeadLetterConfig{
+				Path: "dead-letters.jsonl",
+			},
+			Record: RecordConfig{
+				Dir:            "",
+				MaxBytes:       268435456,
+				RotateInterval: time.Hour,
+			},
+			Replay: ReplayConfig{
+				Path:  "",
+				Speed: "realtime",
+				Feed:  "exchange",
+			},
+			Metrics: MetricsConfig{
+				Addr: ":2112",
+			},
+			Health: HealthConfig{
+				MaxIdle: time.Minute,
+			},
+			Archive: ArchiveConfig{
+				Dir:        "",
+				Layout:     "{table}/{product}/{date}/{hour}",
+				CloseDelay: 5 * time.Minute,
+			},
+			Bus: BusConfig{
+				Format:      "json",
+				TopicPrefix: "market",
+				MaxPending:  10000,
+				Timeout:     5 * time.Second,
+			},
+			Secrets: SecretsConfig{
+				Providers: []string{"env", "mounted", "aws"},
+				Dir:       "/run/secrets",
+				AWSRegion: "us-east-2",
+				Refresh:   5 * time.Minute,
+			},
 		}, wantErr: false},
 	}
 
@@ -69,3 +150,41 @@ func TestNewConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDexConfig_sqlite(t *testing.T) {
+	// neither IS_LOCAL nor AWS credentials, a sqlite file needs no password
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_BASE", "findata.db")
+
+	got, err := NewDexConfig(context.Background())
+	if err != nil {
+		t.Fatalf("NewDexConfig() error = %v", err)
+	}
+	want := DatabaseConfig{Driver: "sqlite", Base: "findata.db"}
+	if !reflect.DeepEqual(got.Database, want) {
+		t.Errorf("NewDexConfig() database = %v, want %v", got.Database, want)
+	}
+}
+
+func TestNewDexConfig_secretsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(file, []byte(`{"DB_PASSWORD":"from-file"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_DRIVER", "postgres")
+	t.Setenv("SECRETS_PROVIDERS", "file")
+	t.Setenv("SECRETS_FILE", file)
+
+	got, err := NewDexConfig(context.Background())
+	if err != nil {
+		t.Fatalf("NewDexConfig() error = %v", err)
+	}
+	if got.Database.Password != "from-file" {
+		t.Errorf("NewDexConfig() password = %q, want from-file", got.Database.Password)
+	}
+
+	t.Setenv("SECRETS_PROVIDERS", "vault")
+	if _, err := NewDexConfig(context.Background()); err == nil {
+		t.Error("NewDexConfig() with an unknown provider, want error")
+	}
+}
@@ -1,13 +1,11 @@
 package database
 
 import (
-	"fmt"
-
 	"github.com/jmoiron/sqlx"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/nel349/bz-findata/pkg/database"
 )
 
-func NewConnection(host, user, password, dbname string) (*sqlx.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true", user, password, host, dbname)
-	return sqlx.Connect("mysql", dsn)
-}
\ No newline at end of file
+// NewConnection opens the mysql or postgres database of driver
+func NewConnection(driver, host, user, password, dbname string) (*sqlx.DB, error) {
+	return database.Connect(driver, host, user, password, dbname)
+}
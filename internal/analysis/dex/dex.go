@@ -15,6 +15,8 @@ type Service struct {
 	supabaseClient *supabase.Client
 }
 
+// NewService creates the dex service, supabaseClient is nil when the swaps
+// are already stored in the postgres database the analytics read
 func NewService(db *sqlx.DB, supabaseClient *supabase.Client) *Service {
 	return &Service{
 		db: db,
@@ -30,12 +32,19 @@ func (s *Service) GetLargestSwapsInLastNHours(
 	limit int,
 )([] entity.SwapTransaction, error) {
 
-	query := `
+	since := "FROM_UNIXTIME(?)"
+	switch s.db.DriverName() {
+	case "pgx":
+		since = "to_timestamp(?)"
+	case "sqlite3":
+		since = "datetime(?, 'unixepoch')"
+	}
+	query := s.db.Rebind(`
 		SELECT * FROM swap_transactions
-		WHERE last_updated > FROM_UNIXTIME(?)
+		WHERE last_updated > ` + since + `
 		ORDER BY value DESC
 		LIMIT ?
-	`
+	`)
 	var swaps []entity.SwapTransaction
 	// Log the query parameters for debugging
 	log.Printf("Executing query with hours: %d, limit: %d", hours, limit)
@@ -61,6 +70,10 @@ func (s *Service) StoreLargestSwapsInLastNHours(ctx context.Context, hours, limi
 }
 
 func (s *Service) storeSwapsInSupabase(swaps interface{}, tableName string) error {
+	if s.supabaseClient == nil {
+		return nil // nothing to copy
+	}
+
 	_, err := s.supabaseClient.From(tableName).Insert(swaps, false, "", "", "").ExecuteTo(&swaps)
 	if err != nil {
 		log.Println("error inserting swaps to supabase table", err)
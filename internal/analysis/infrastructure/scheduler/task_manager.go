@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/nel349/bz-findata/internal/analysis/application/ports"
 	"github.com/nel349/bz-findata/internal/analysis/task"
+	"github.com/nel349/bz-findata/pkg/health"
 	"github.com/robfig/cron/v3"
 )
 
@@ -22,6 +23,10 @@ type TaskManager struct {
 	tasks   map[cron.EntryID]scheduler.Task
 	mutex   sync.RWMutex
 	service *task.Service
+
+	// outcome of the last task run
+	lastRun time.Time
+	lastErr error
 }
 
 func NewTaskManager(service *task.Service) *TaskManager {
@@ -58,9 +63,14 @@ func (tm *TaskManager) StartTask(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		if err := tm.service.StoreMatchOrders(ctx, req.Hours, req.Limit); err != nil {
+		err := tm.service.StoreMatchOrders(ctx, req.Hours, req.Limit)
+		if err != nil {
 			log.Printf("Error executing scheduled task: %v", err)
 		}
+
+		tm.mutex.Lock()
+		tm.lastRun, tm.lastErr = time.Now(), err
+		tm.mutex.Unlock()
 	})
 
 	if err != nil {
@@ -120,3 +130,23 @@ func (tm *TaskManager) ListTasks(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tasks)
 }
+
+// Check reports the scheduler health, degraded while the last task run failed
+func (tm *TaskManager) Check(context.Context) health.Result {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	result := health.Result{
+		Status:  health.OK,
+		Details: map[string]interface{}{"tasks": len(tm.tasks)},
+	}
+	if !tm.lastRun.IsZero() {
+		result.Details["last_run"] = tm.lastRun.UTC().Format(time.RFC3339)
+	}
+	if tm.lastErr != nil {
+		result.Status = health.Degraded
+		result.Error = tm.lastErr.Error()
+	}
+
+	return result
+}
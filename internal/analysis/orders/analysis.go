@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 	"github.com/supabase-community/supabase-go"
 )
 
@@ -16,7 +17,7 @@ type Service struct {
 
 type Order struct {
 	OrderID   string    `json:"order_id" db:"order_id"` // Use both 'json' and 'db' tags
-	Price     float64   `json:"price" db:"price"`
+	Price     decimal.Decimal   `json:"price" db:"price"`
 	ProductID string    `json:"product_id,omitempty" db:"product_id,omitempty"`
 	Type      string    `json:"type,omitempty" db:"type,omitempty"`
 	Timestamp int64     `json:"timestamp,omitempty" db:"timestamp,omitempty"`
@@ -25,30 +26,32 @@ type Order struct {
 type ReceivedOrder struct {
 	Order
 	OrderType string `json:"order_type,omitempty"`
-	Size      float64   `json:"size" db:"size"`
+	Size      decimal.Decimal   `json:"size" db:"size"`
 	Side      string    `json:"side" db:"side"`
 }
 
 type OpenOrder struct {
 	Order
-	RemainingSize float64 `json:"remaining_size" db:"remaining_size"`
+	RemainingSize decimal.Decimal `json:"remaining_size" db:"remaining_size"`
 	Side          string  `json:"side" db:"side"`
 }
 
 type DoneOrder struct {
 	Order
-	RemainingSize float64 `json:"remaining_size"`
+	RemainingSize decimal.Decimal `json:"remaining_size"`
 	Side          string  `json:"side" db:"side"`
 	Reason        string  `json:"reason" db:"reason"`
 }
 
 type MatchOrder struct {
 	Order
-	Size          float64 `json:"size" db:"size"`
-	RemainingSize float64 `json:"remaining_size" db:"remaining_size"`
+	Size          decimal.Decimal `json:"size" db:"size"`
+	RemainingSize decimal.Decimal `json:"remaining_size" db:"remaining_size"`
 	Side          string  `json:"side" db:"side"`
 }
 
+// NewService creates the analysis service, supabaseClient is nil when the orders
+// are already stored in the postgres database the analytics read
 func NewService(db *sqlx.DB, supabaseClient *supabase.Client) *Service {
 	return &Service{db: db, supabaseClient: supabaseClient}
 }
@@ -63,7 +66,7 @@ func (s *Service) GetLargestReceivedOrdersInLastNHours(ctx context.Context, hour
 		LIMIT ?
 	`
 	var orders []ReceivedOrder
-	err := s.db.SelectContext(ctx, &orders, query, time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
+	err := s.db.SelectContext(ctx, &orders, s.db.Rebind(query), time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
 	if err != nil {
 		log.Println("error selecting orders from db", err)
 	}
@@ -81,7 +84,7 @@ func (s *Service) GetLargestOpenOrdersInLastNHours(ctx context.Context, hours in
 		LIMIT ?
 	`
 	var orders []OpenOrder
-	err := s.db.SelectContext(ctx, &orders, query, time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
+	err := s.db.SelectContext(ctx, &orders, s.db.Rebind(query), time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
 	return orders, err
 }
 
@@ -97,13 +100,17 @@ func (s *Service) GetLargestMatchOrdersInLastNHours(ctx context.Context, hours,
 		LIMIT ?
 	`
 	var orders []MatchOrder
-	err := s.db.SelectContext(ctx, &orders, query, time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
+	err := s.db.SelectContext(ctx, &orders, s.db.Rebind(query), time.Now().Add(-time.Duration(hours)*time.Hour).UnixNano(), limit)
 
 	return orders, err
 }
 
 // Generic store method that handles different order types
 func (s *Service) storeOrdersInSupabase(orders interface{}, tableName string) error {
+    if s.supabaseClient == nil {
+        return nil // nothing to copy
+    }
+
     // Store in Supabase with the specified table
     _, err := s.supabaseClient.From(tableName).Insert(orders, false, "", "", "").ExecuteTo(&orders)
     if err != nil {
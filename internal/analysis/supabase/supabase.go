@@ -4,9 +4,8 @@ import (
 	"context"
 	"fmt"
 
-	// "github.com/aws/aws-sdk-go-v2/aws"
-	awslocal "github.com/nel349/bz-findata/pkg/aws"
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/secrets"
 	"github.com/supabase-community/supabase-go"
 )
 
@@ -14,39 +13,25 @@ type supabaseRepo struct {
 	Client *supabase.Client
 }
 
-// // NewSupabaseRepository created supabase repository
-func NewSupabaseRepository() *supabaseRepo {
-	// projectURL := os.Getenv("SUPABASE_URL")
-	// serviceRoleKey := os.Getenv("SERVICE_ROLE_KEY")
-
-	secret, err := awslocal.GetDefaultCoinbaseSecret()
+// NewSupabaseRepository created supabase repository, its URL and service role key
+// are looked up in the default secret provider
+func NewSupabaseRepository(ctx context.Context) (*supabaseRepo, error) {
+	values, err := secrets.GetAll(ctx, "SUPABASE_URL", "SERVICE_ROLE_KEY")
 	if err != nil {
-		fmt.Println("Failed to retrieve secret", err)
-	}
-
-	fmt.Println("Secret retrieved successfully: ")
-	// fmt.Println("COINBASE_WS_API_KEY", secret.COINBASE_WS_API_KEY)
-	// fmt.Println("COINBASE_WS_API_SECRET", secret.COINBASE_WS_API_SECRET)
-	// fmt.Println("COINBASE_WS_API_PASSPHRASE", secret.COINBASE_WS_API_PASSPHRASE)
-	// fmt.Println("SUPABASE_URL", secret.SUPABASE_URL)
-	// fmt.Println("SERVICE_ROLE_KEY", secret.SERVICE_ROLE_KEY)
-
-
-	// Lets check if the projectURL and anonKey are set
-	if secret.SUPABASE_URL == "" || secret.SERVICE_ROLE_KEY == "" {
-		fmt.Println("SUPABASE_URL or SERVICE_ROLE_KEY is not set")
+		return nil, fmt.Errorf("supabase credentials: %w", err)
 	}
+	projectURL, serviceRoleKey := values[0], values[1]
 
-	client, err := supabase.NewClient(secret.SUPABASE_URL, secret.SERVICE_ROLE_KEY, &supabase.ClientOptions{
+	client, err := supabase.NewClient(projectURL, serviceRoleKey, &supabase.ClientOptions{
 		Headers: map[string]string{
-			"Authorization": "Bearer " + secret.SERVICE_ROLE_KEY,
-			"apikey":        secret.SERVICE_ROLE_KEY,
+			"Authorization": "Bearer " + serviceRoleKey,
+			"apikey":        serviceRoleKey,
 		},
 	})
 	if err != nil {
-		fmt.Println("cannot initalize client", err)
+		return nil, fmt.Errorf("cannot initalize client: %w", err)
 	}
-	return &supabaseRepo{client}
+	return &supabaseRepo{client}, nil
 }
 
 // Create the order in supabase
@@ -2,15 +2,29 @@ package app
 
 import (
 	"context"
-	"os"
+	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/nel349/bz-findata/config"
-	"github.com/nel349/bz-findata/internal/cex-collector/delivery/websocket"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
 	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
-	"github.com/nel349/bz-findata/pkg/database/mysql"
+	"github.com/nel349/bz-findata/pkg/archive"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/deadletter"
+	"github.com/nel349/bz-findata/pkg/exchange/binance"
 	"github.com/nel349/bz-findata/pkg/exchange/coinbase"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase/advanced"
+	"github.com/nel349/bz-findata/pkg/feedlog"
+	"github.com/nel349/bz-findata/pkg/health"
+	"github.com/nel349/bz-findata/pkg/logger"
 	"github.com/nel349/bz-findata/pkg/logger/zap"
+	pkgmetrics "github.com/nel349/bz-findata/pkg/metrics"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
 )
 
 // Run started application
@@ -19,42 +33,265 @@ func Run(ctx context.Context, cfg *config.Config) {
 	loggerProvider := zap.NewZapLogger(cfg.Logger.Level, cfg.Logger.DisableCaller, cfg.Logger.DisableStacktrace)
 	loggerProvider.InitLogger()
 
+	if err := checkVenues(cfg); err != nil {
+		loggerProvider.Fatal(err)
+	}
+
+	checker := health.NewChecker()
+	messages := health.NewActivity(cfg.Health.MaxIdle)
+	checker.Add("messages", messages.Check)
+
+	// the products left on the Exchange feed
+	var feeds []feed
+	if exchangeCfg := exchangeConfig(cfg); len(exchangeCfg.Exchange.Symbols) > 0 {
+		exchangeClient, err := coinbase.NewCoinbaseClient(exchangeCfg)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer exchangeClient.CloseConnection()
+
+		conn, closeRecording, err := watch(cfg, loggerProvider, checker, messages, "feed", "coinbase", exchangeClient)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer closeRecording()
+		feeds = append(feeds, feed{kind: exchangeFeed, conn: conn})
+	}
+
+	// the products moved to Advanced Trade
+	if len(cfg.Exchange.AdvancedSymbols) > 0 {
+		advancedClient, err := advanced.NewAdvancedTradeClient(cfg)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer advancedClient.CloseConnection()
+
+		conn, closeRecording, err := watch(cfg, loggerProvider, checker, messages, "advanced_feed", "coinbase-advanced", advancedClient)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer closeRecording()
+		feeds = append(feeds, feed{kind: advancedFeed, conn: conn})
+	}
+
+	// the products read from Binance
+	if len(cfg.Binance.Symbols) > 0 {
+		binanceClient, err := binance.NewBinanceClient(cfg.Binance)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer binanceClient.CloseConnection()
+
+		conn, closeRecording, err := watch(cfg, loggerProvider, checker, messages, "binance_feed", "binance", binanceClient)
+		if err != nil {
+			loggerProvider.Fatal(err)
+		}
+		defer closeRecording()
+		feeds = append(feeds, feed{kind: binanceFeed, conn: conn})
+	}
+
+	loggerProvider.Info("socket starting...")
+	if err := serve(ctx, cfg, loggerProvider, feeds, checker); err != nil {
+		loggerProvider.Fatal(err)
+	}
+}
+
+// Replay runs a recorded feed through the application instead of the exchange,
+// it returns once the whole feed is stored
+func Replay(ctx context.Context, cfg *config.Config) error {
+	loggerProvider := zap.NewZapLogger(cfg.Logger.Level, cfg.Logger.DisableCaller, cfg.Logger.DisableStacktrace)
+	loggerProvider.InitLogger()
+
+	speed, err := feedlog.ParseSpeed(cfg.Replay.Speed)
+	if err != nil {
+		return err
+	}
+	if cfg.Replay.Path == "" {
+		return fmt.Errorf("REPLAY_PATH is required")
+	}
+
+	player, err := feedlog.NewPlayer(cfg.Replay.Path, speed)
+	if err != nil {
+		return err
+	}
+	defer player.CloseConnection()
+
+	loggerProvider.Info(fmt.Sprintf("replaying %s feed from %s at %s speed", cfg.Replay.Feed, cfg.Replay.Path, speed))
+	if err := serve(ctx, cfg, loggerProvider, []feed{{kind: cfg.Replay.Feed, conn: player}}, health.NewChecker()); err != nil {
+		return err
+	}
+	loggerProvider.Info(fmt.Sprintf("replayed %d frames", player.Frames()))
+
+	return nil
+}
+
+// serve processes the feeds until ctx is done or a feed ends,
+// checker reports the health of the feeds along with the database
+func serve(ctx context.Context, cfg *config.Config, loggerProvider logger.Logger, feeds []feed, checker *health.Checker) error {
 	// database
-	dbClient, err := mysql.NewMysqlClient(cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
+	db, err := database.Connect(cfg.Database.Driver, cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
 	if err != nil {
-		loggerProvider.Fatal(err)
+		return err
+	}
+	defer db.Close()
+	checker.Add("database", health.Ping(db.PingContext))
+
+	if cfg.Database.Migrate {
+		if err := migrateUp(ctx, db, cfg.Database.Driver, loggerProvider); err != nil {
+			return err
+		}
 	}
-	defer dbClient.CloseConnect()
 
-	// exchange
-	exchangeClient, err := coinbase.NewCoinbaseClient(cfg)
+	// metrics and probes
+	mux := http.NewServeMux()
+	checker.Mount(mux)
+	go func() {
+		if err := pkgmetrics.Serve(ctx, cfg.Metrics.Addr, mux); err != nil {
+			loggerProvider.Error(fmt.Sprintf("metrics endpoint stopped: %v", err))
+		}
+	}()
+
+	// order filter rules
+	orderRules, err := rules.NewEngine(cfg.Filter.RulesFile, loggerProvider)
 	if err != nil {
-		loggerProvider.Fatal(err)
+		return err
+	}
+	go orderRules.Watch(ctx, cfg.Filter.ReloadInterval)
+
+	// full channel books
+	var bookSource orderbook.SnapshotSource
+	if cfg.Book.L3 {
+		bookSource = coinbase.NewSnapshotSource(cfg.Exchange.RestUrl)
+	}
+
+	// candles of every match
+	granularities, err := candle.ParseGranularities(cfg.Candle.Granularities)
+	if err != nil {
+		return err
 	}
-	defer exchangeClient.CloseConnection()
 
 	// repositories & business logic
-	repo := repository.NewRepositories(dbClient.DB)
+	deadLetters, err := deadletter.NewQueue(cfg.DeadLetter.Path)
+	if err != nil {
+		return err
+	}
+	var sinks []repository.Sink
+	if cfg.Archive.Dir != "" {
+		parquet, err := archive.NewArchive(cfg.Archive.Dir, cfg.Archive.Layout, cfg.Archive.CloseDelay)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, parquet)
+		loggerProvider.Info(fmt.Sprintf("archiving ticks and orders to %s", cfg.Archive.Dir))
+	}
+	repo := repository.NewRepositories(db, cfg.Batch, deadLetters, loggerProvider, sinks...)
+
+	// stopped once the feed is done so the writer flushes before the database is closed
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	// every parsed ticker, order and heartbeat, published until the feed is done
+	var publisher repository.Publisher
+	busDone := make(chan struct{})
+	if cfg.Bus.Protocol != "" {
+		busPublisher, err := newPublisher(cfg.Bus, loggerProvider)
+		if err != nil {
+			return err
+		}
+		publisher = busPublisher
+		go func() {
+			defer close(busDone)
+			if err := busPublisher.Run(runCtx); err != nil {
+				loggerProvider.Error(err)
+			}
+		}()
+		loggerProvider.Info(fmt.Sprintf("publishing events to %s bus", cfg.Bus.Protocol))
+	} else {
+		close(busDone)
+	}
+
+	// batched writes and archive
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		if err := repo.Writer.Run(runCtx); err != nil {
+			loggerProvider.Error(err)
+		}
+	}()
 	uc := usecase.NewUseCase(repo, &usecase.Packages{
-		Logger: loggerProvider,
+		Logger:     loggerProvider,
+		OrderRules: orderRules,
+		Book:       cfg.Book,
+		BookSource: bookSource,
+		Sequences:  sequence.NewTracker(),
+		Candle:     cfg.Candle,
+		Candles:    candle.NewBuilder(granularities),
+		Rates:      currency.NewConverter(cfg.Filter.MaxRateAge),
+
+		DeadLetters: deadLetters,
+		Publisher:   publisher,
 	})
 
-	// init client
-	client, err := websocket.NewSocketClient(exchangeClient, uc, loggerProvider, cfg.Exchange)
-	if err != nil {
-		loggerProvider.Fatal(err)
+	// init clients
+	var clients []feedClient
+	for _, f := range feeds {
+		client, err := newFeedClient(f, cfg, uc, loggerProvider)
+		if err != nil {
+			return err
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		return errors.New("no feed to read")
 	}
 
-	// run
+	// book snapshots
 	go func() {
-		loggerProvider.Info("socket starting...")
-		if err = client.Run(ctx); err != nil {
-			loggerProvider.Fatal(err)
-			os.Exit(1)
+		if err := uc.Book.RunSnapshots(runCtx); err != nil && runCtx.Err() == nil {
+			loggerProvider.Error(err)
 		}
 	}()
 
-	<-ctx.Done()
+	// candles, written before the database is closed
+	candlesDone := make(chan struct{})
+	go func() {
+		defer close(candlesDone)
+		if err := uc.Candle.RunCandles(runCtx); err != nil && !errors.Is(err, context.Canceled) {
+			loggerProvider.Error(err)
+		}
+	}()
+
+	// run, the first feed to stop stops the others
+	done := make(chan error, len(clients))
+	for _, client := range clients {
+		go func() {
+			done <- client.Run(runCtx)
+		}()
+	}
+
+	pending := len(clients)
+	select {
+	case err = <-done:
+		pending--
+	case <-ctx.Done():
+		loggerProvider.Info("socket stopping...")
+	}
+	// unblocks the readers
+	for _, f := range feeds {
+		_ = f.conn.CloseConnection()
+	}
+	for ; pending > 0; pending-- {
+		<-done
+	}
+	if ctx.Err() != nil {
+		// stopped, not failed
+		err = nil
+	}
+
+	stop()
+	<-writerDone
+	<-candlesDone
+	<-busDone
 
-	loggerProvider.Info("socket stopping...")
+	return err
 }
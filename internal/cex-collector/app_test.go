@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase/coinbasetest"
+	"github.com/nel349/bz-findata/pkg/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_fakeFeed runs the collector against a local feed and a sqlite database file
+func TestRun_fakeFeed(t *testing.T) {
+	testRunFakeFeed(t, config.DatabaseConfig{
+		Driver:  database.SQLite,
+		Base:    filepath.Join(t.TempDir(), "findata.db"),
+		Migrate: true,
+	})
+}
+
+// TestRun_fakeFeed_mysql runs the collector against a local feed, it needs the MySQL
+// database at TEST_DB_HOST (e.g. localhost:3306 from docker-compose)
+func TestRun_fakeFeed_mysql(t *testing.T) {
+	host := os.Getenv("TEST_DB_HOST")
+	if host == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+
+	testRunFakeFeed(t, config.DatabaseConfig{
+		Driver:   database.MySQL,
+		Host:     host,
+		User:     "root",
+		Password: "root",
+		Base:     "findata",
+		Migrate:  true,
+	})
+}
+
+// testRunFakeFeed stores the tickers of a local feed in the database of dbCfg,
+// migrated by pkg/migrations, before and after the feed drops the connection
+func testRunFakeFeed(t *testing.T, dbCfg config.DatabaseConfig) {
+	creds := &coinbasetest.Credentials{
+		Key:        "test-key",
+		Secret:     base64.StdEncoding.EncodeToString([]byte("test-secret")),
+		Passphrase: "test-passphrase",
+	}
+	t.Setenv("COINBASE_WS_API_KEY", creds.Key)
+	t.Setenv("COINBASE_WS_API_SECRET", creds.Secret)
+	t.Setenv("COINBASE_WS_API_PASSPHRASE", creds.Passphrase)
+
+	server := coinbasetest.NewServer(coinbasetest.Options{Credentials: creds, HeartbeatInterval: time.Second})
+	defer server.Close()
+
+	cfg := &config.Config{
+		Exchange: config.ExchangeConfig{
+			Url:               server.URL,
+			Origin:            server.Origin,
+			Symbols:           []string{"BTC-USD"},
+			Channels:          []string{"ticker"},
+			ReconnectDelay:    10 * time.Millisecond,
+			ReconnectMaxDelay: 100 * time.Millisecond,
+		},
+		Database:   dbCfg,
+		Logger:     config.LoggerConfig{Level: "error"},
+		Batch:      config.BatchConfig{Size: 10, Interval: 50 * time.Millisecond},
+		DeadLetter: config.DeadLetterConfig{Path: t.TempDir() + "/dead-letters.jsonl"},
+	}
+
+	db, err := database.Connect(dbCfg.Driver, dbCfg.Host, dbCfg.User, dbCfg.Password, dbCfg.Base)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = migrations.Up(context.Background(), db, dbCfg.Driver)
+	require.NoError(t, err)
+
+	ticks := func() int {
+		var count int
+		require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM ticks WHERE symbol = 'BTC-USD'"))
+		return count
+	}
+	before := ticks()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, cfg)
+	}()
+
+	require.NoError(t, server.WaitSubscriptions(2, 5*time.Second))
+	server.SendTicker("BTC-USD", 100, 101)
+
+	// stored again after the exchange drops the connection
+	server.Disconnect()
+	require.NoError(t, server.WaitSubscriptions(4, 5*time.Second))
+	server.SendTicker("BTC-USD", 102, 103)
+
+	assert.Eventually(t, func() bool {
+		return ticks() == before+2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/pkg/bus"
+	"github.com/nel349/bz-findata/pkg/logger"
+)
+
+// newPublisher connects the publisher of the coinbase events to the bus of cfg,
+// the events of the embedded broker are logged so a local run shows what is published
+func newPublisher(cfg config.BusConfig, loggerProvider logger.Logger) (*bus.Publisher, error) {
+	format, err := bus.ParseFormat(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	broker, err := bus.NewBroker(cfg.Protocol, cfg.URL, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if embedded, ok := broker.(*bus.EmbeddedBroker); ok {
+		events, _ := embedded.Subscribe(">", cfg.MaxPending)
+		go func() {
+			for msg := range events {
+				event, err := bus.Decode(format, msg.Payload)
+				if err != nil {
+					loggerProvider.Error(fmt.Sprintf("failed to decode published event: %v", err))
+					continue
+				}
+				b, _ := bus.Encode(bus.JSON, event)
+				loggerProvider.Debug(fmt.Sprintf("published to %s: %s", msg.Topic, b))
+			}
+		}()
+	}
+
+	return bus.NewPublisher(broker, format, cfg.TopicPrefix, "coinbase", cfg.MaxPending, func(err error) {
+		metrics.PublishFailures.Inc()
+		loggerProvider.Error(fmt.Sprintf("failed to publish event: %v", err))
+	}), nil
+}
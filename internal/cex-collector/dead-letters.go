@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/deadletter"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/logger/zap"
+)
+
+// ReplayDeadLetters stores the queued dead letters again, the ones still failing stay queued
+func ReplayDeadLetters(ctx context.Context, cfg *config.Config) error {
+	loggerProvider := zap.NewZapLogger(cfg.Logger.Level, cfg.Logger.DisableCaller, cfg.Logger.DisableStacktrace)
+	loggerProvider.InitLogger()
+
+	// fails unless the database is reachable
+	db, err := database.Connect(cfg.Database.Driver, cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
+	if err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+	defer db.Close()
+
+	queue, err := deadletter.NewQueue(cfg.DeadLetter.Path)
+	if err != nil {
+		return err
+	}
+
+	loggerProvider.Info(fmt.Sprintf("replaying dead letters from %s", queue.Path()))
+
+	stats, err := queue.Replay(ctx, storeDeadLetter(repository.NewExchange(db)))
+
+	loggerProvider.Info(fmt.Sprintf(
+		"dead letters replayed:%d, failed:%d, invalid:%d",
+		stats.Replayed,
+		stats.Failed,
+		stats.Invalid,
+	))
+	if err != nil {
+		return err
+	}
+	if stats.Failed > 0 {
+		return fmt.Errorf("%d dead letters still failing", stats.Failed)
+	}
+
+	return nil
+}
+
+// storeDeadLetter writes an entry through the idempotent bulk inserts,
+// a message stored before it failed is not duplicated
+func storeDeadLetter(store repository.BulkExchange) func(ctx context.Context, entry deadletter.Entry) error {
+	return func(ctx context.Context, entry deadletter.Entry) error {
+		switch msg := entry.Message; {
+		case msg.Ticker != nil:
+			return store.CreateTicks(ctx, []entity.Ticker{*msg.Ticker})
+		case msg.Order != nil:
+			return store.CreateOrders(ctx, []entity.Order{*msg.Order})
+		default:
+			return errors.New("dead letter is neither a tick nor an order")
+		}
+	}
+}
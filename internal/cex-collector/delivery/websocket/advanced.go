@@ -0,0 +1,200 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase/advanced"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+type advancedClient struct {
+	logger       logger.Logger
+	conn         exchange.Manager
+	uc           *usecase.Services
+	products     []string
+	channels     []string
+	maxFrameSize int
+	backoff      exchange.Backoff
+	// lastTrades is the latest trade id sent of each product, the market_trades snapshot
+	// sent after every subscribe repeats the trades already sent
+	lastTrades map[string]int64
+}
+
+// NewAdvancedTradeClient init websocket client of the Advanced Trade feed from delivery layout,
+// its products are cfg.AdvancedSymbols
+func NewAdvancedTradeClient(conn exchange.Manager, uc *usecase.Services, logger logger.Logger, cfg config.ExchangeConfig) (*advancedClient, error) {
+	if len(cfg.AdvancedSymbols) == 0 {
+		return nil, errors.New("not found advanced trade symbols for subscribes")
+	}
+	if len(cfg.AdvancedChannels) == 0 {
+		return nil, errors.New("not found advanced trade channels for subscribes")
+	}
+
+	return &advancedClient{
+		logger,
+		conn,
+		uc,
+		cfg.AdvancedSymbols,
+		cfg.AdvancedChannels,
+		cfg.MaxFrameSize,
+		exchange.NewBackoff(cfg.ReconnectDelay, cfg.ReconnectMaxDelay),
+		nil,
+	}, nil
+}
+
+// Run websocket listener
+func (c *advancedClient) Run(ctx context.Context) error {
+	// one subscription per channel, signed again on every reconnect
+	for _, channel := range c.channels {
+		err := c.conn.Subscribe(func() ([]byte, error) {
+			return advanced.NewSubscribe(ctx, channel, c.products)
+		})
+		if err != nil {
+			c.logger.Error(err)
+			return err
+		}
+	}
+
+	decoder := exchange.NewDecoder(c.conn, c.maxFrameSize)
+
+	// a rejected subscription is the first message, a replayed feed may start with data
+	message, err := decoder.Next()
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	first, err := advanced.ParseMessage(message)
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	if err := first.Err(); err != nil {
+		return fmt.Errorf("subscription error: %w", err)
+	}
+	if first.Channel == advanced.Subscriptions {
+		c.logger.Info(fmt.Sprintf("started advanced trade subscription on products [%s]", strings.Join(c.products, ",")))
+	}
+
+	var g = errgroup.Group{}
+	hMap := startWriters(ctx, &g, c.uc, c.products)
+
+	if err := c.handle(ctx, first, decoder.ReceivedAt(), hMap); err != nil {
+		closeChannels(hMap)
+		return err
+	}
+
+	// Subscribe to heartbeats
+	if err := c.conn.SubscribeToHeartbeats(ctx); err != nil {
+		c.logger.Error(err)
+		closeChannels(hMap)
+		return err
+	}
+
+	// monitor heartbeat
+	go c.conn.MonitorHeartbeat(ctx, 10*time.Second)
+
+	// single reader routing every message to the writer of its product
+	g.Go(func() error {
+		defer closeChannels(hMap)
+		return c.responseReader(ctx, decoder, hMap)
+	})
+
+	return g.Wait()
+}
+
+func (c *advancedClient) responseReader(ctx context.Context, decoder *exchange.Decoder, hMap map[string]chan entity.Message) error {
+	return readObjects(ctx, c.logger, decoder, c.backoff, func(message json.RawMessage) error {
+		m, err := advanced.ParseMessage(message)
+		if err != nil {
+			c.logger.Error("Failed to parse response: ", err)
+			metrics.ParseErrors.WithLabelValues("response").Inc()
+			return nil
+		}
+
+		return c.handle(ctx, m, decoder.ReceivedAt(), hMap)
+	})
+}
+
+// handle sends the messages of m received at receivedAt to the writers of their products,
+// a heartbeat of the connection goes to every product. Trades at or below the latest trade
+// id sent of their product are dropped, they have no sequence the usecase could dedup them by
+func (c *advancedClient) handle(ctx context.Context, m *advanced.Message, receivedAt time.Time, hMap map[string]chan entity.Message) error {
+	if err := m.Err(); err != nil {
+		c.logger.Error(err)
+		return nil
+	}
+	if m.Channel == advanced.Subscriptions {
+		// heartbeats and subscriptions replayed after a reconnect
+		c.logger.Info("subscriptions confirmed")
+		return nil
+	}
+
+	messages, err := m.ToMessages(receivedAt)
+	if err != nil {
+		c.logger.Error(err)
+		metrics.ParseErrors.WithLabelValues("message").Inc()
+		return nil
+	}
+
+	for _, msg := range messages {
+		if msg.Heartbeat != nil {
+			c.conn.UpdateHeartbeat()
+			for _, productID := range c.products {
+				heartbeat := *msg.Heartbeat
+				heartbeat.ProductID = productID
+				metrics.HeartbeatAge.Touch(productID)
+				metrics.Messages.WithLabelValues("heartbeat", productID).Inc()
+				if err := dispatch(ctx, hMap, productID, entity.Message{Heartbeat: &heartbeat}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		productID := messageProduct(msg)
+		if msg.Order != nil {
+			if c.lastTrades == nil {
+				c.lastTrades = make(map[string]int64)
+			}
+			if msg.Order.TradeID <= c.lastTrades[productID] {
+				continue
+			}
+			c.lastTrades[productID] = msg.Order.TradeID
+		}
+		metrics.Messages.WithLabelValues(messageType(msg), productID).Inc()
+		if err := dispatch(ctx, hMap, productID, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// messageProduct is the product of msg
+func messageProduct(msg entity.Message) string {
+	switch {
+	case msg.Ticker != nil:
+		return msg.Ticker.Symbol
+	case msg.Order != nil:
+		return msg.Order.ProductID
+	case msg.Book != nil:
+		return msg.Book.ProductID
+	case msg.Heartbeat != nil:
+		return msg.Heartbeat.ProductID
+	case msg.Candle != nil:
+		return msg.Candle.ProductID
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdvancedTradeClient(t *testing.T) {
+	_, err := NewAdvancedTradeClient(nil, nil, nopLogger{}, config.ExchangeConfig{AdvancedChannels: []string{"ticker"}})
+	assert.Error(t, err)
+	_, err = NewAdvancedTradeClient(nil, nil, nopLogger{}, config.ExchangeConfig{AdvancedSymbols: []string{"BTC-USD"}})
+	assert.Error(t, err)
+
+	c, err := NewAdvancedTradeClient(nil, nil, nopLogger{}, config.ExchangeConfig{
+		Symbols:          []string{"ETH-USD"},
+		AdvancedSymbols:  []string{"BTC-USD"},
+		AdvancedChannels: []string{"ticker", "candles"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTC-USD"}, c.products)
+	assert.Equal(t, []string{"ticker", "candles"}, c.channels)
+}
+
+func Test_advancedClient_responseReader_routesByProduct(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"channel":"subscriptions","events":[{"subscriptions":{"ticker":["BTC-USD","ETH-USD"]}}]}`),
+		[]byte(`{"channel":"ticker","timestamp":"2024-01-02T03:04:05Z","sequence_num":1,"events":[{"type":"update","tickers":[{"product_id":"BTC-USD","best_bid":"1","best_ask":"2"},{"product_id":"SOL-USD","best_bid":"3","best_ask":"4"}]}]}`),
+		[]byte(`{"channel":"heartbeats","timestamp":"2024-01-02T03:04:06Z","sequence_num":2,"events":[{"heartbeat_counter":1}]}`),
+		[]byte(`{"channel":"market_trades","timestamp":"2024-01-02T03:04:07Z","sequence_num":3,"events":[{"type":"update","trades":[{"trade_id":"5","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:04:07Z"}]}]}`),
+		[]byte(`{"channel":"status","events":[]}`),
+		nil,
+		[]byte(`{"type":"error","message":"rate limited"}`),
+	}}
+	c := &advancedClient{logger: nopLogger{}, conn: conn, products: []string{"BTC-USD", "ETH-USD"}}
+
+	hMap := map[string]chan entity.Message{
+		"BTC-USD": make(chan entity.Message, 10),
+		"ETH-USD": make(chan entity.Message, 10),
+	}
+
+	// unknown channels, failed reconnects and errors do not stop the reader
+	require.NoError(t, c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap))
+
+	require.Len(t, hMap["BTC-USD"], 2)
+	assert.Equal(t, "BTC-USD", (<-hMap["BTC-USD"]).Ticker.Symbol)
+	assert.Equal(t, "BTC-USD", (<-hMap["BTC-USD"]).Heartbeat.ProductID)
+
+	// the heartbeat of the connection goes to every product
+	require.Len(t, hMap["ETH-USD"], 2)
+	assert.Equal(t, "ETH-USD", (<-hMap["ETH-USD"]).Heartbeat.ProductID)
+	assert.Equal(t, int64(5), (<-hMap["ETH-USD"]).Order.TradeID)
+}
+
+func Test_advancedClient_responseReader_dropsReplayedTrades(t *testing.T) {
+	// the snapshot of a resubscribe repeats trades 5 and 6, newest first
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"channel":"market_trades","timestamp":"2024-01-02T03:04:07Z","sequence_num":1,"events":[{"type":"snapshot","trades":[{"trade_id":"6","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:04:07Z"},{"trade_id":"5","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:04:06Z"}]}]}`),
+		[]byte(`{"channel":"market_trades","timestamp":"2024-01-02T03:05:07Z","sequence_num":1,"events":[{"type":"snapshot","trades":[{"trade_id":"7","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:05:07Z"},{"trade_id":"6","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:04:07Z"},{"trade_id":"5","product_id":"ETH-USD","price":"1","size":"1","side":"BUY","time":"2024-01-02T03:04:06Z"}]}]}`),
+	}}
+	c := &advancedClient{logger: nopLogger{}, conn: conn, products: []string{"ETH-USD"}}
+	hMap := map[string]chan entity.Message{"ETH-USD": make(chan entity.Message, 10)}
+
+	require.NoError(t, c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap))
+
+	require.Len(t, hMap["ETH-USD"], 3)
+	for _, tradeID := range []int64{5, 6, 7} {
+		assert.Equal(t, tradeID, (<-hMap["ETH-USD"]).Order.TradeID)
+	}
+}
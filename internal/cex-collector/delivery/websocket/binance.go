@@ -0,0 +1,166 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/exchange/binance"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+type binanceClient struct {
+	logger       logger.Logger
+	conn         exchange.Manager
+	uc           *usecase.Services
+	products     []string
+	streams      []string
+	maxFrameSize int
+	pongTimeout  time.Duration
+	backoff      exchange.Backoff
+}
+
+// NewBinanceClient init websocket client of the Binance feed from delivery layout,
+// its products are cfg.Symbols normalized to product ids such as BTC-USDT
+func NewBinanceClient(conn exchange.Manager, uc *usecase.Services, logger logger.Logger, cfg config.BinanceConfig) (*binanceClient, error) {
+	if len(cfg.Symbols) == 0 {
+		return nil, errors.New("not found binance symbols for subscribes")
+	}
+	if len(cfg.Streams) == 0 {
+		return nil, errors.New("not found binance streams for subscribes")
+	}
+	for _, stream := range cfg.Streams {
+		if !binance.Supported(stream) {
+			return nil, fmt.Errorf("unsupported binance stream %q", stream)
+		}
+	}
+
+	products := make([]string, 0, len(cfg.Symbols))
+	for _, symbol := range cfg.Symbols {
+		stream, ok := binance.Symbol(symbol)
+		if !ok {
+			return nil, fmt.Errorf("invalid binance product id %q", symbol)
+		}
+		productID, ok := binance.ProductID(stream)
+		if !ok {
+			return nil, fmt.Errorf("unknown quote asset of binance product %q", symbol)
+		}
+		products = append(products, productID)
+	}
+
+	return &binanceClient{
+		logger,
+		conn,
+		uc,
+		products,
+		cfg.Streams,
+		cfg.MaxFrameSize,
+		cfg.PongTimeout,
+		exchange.NewBackoff(cfg.ReconnectDelay, cfg.ReconnectMaxDelay),
+	}, nil
+}
+
+// Run websocket listener
+func (c *binanceClient) Run(ctx context.Context) error {
+	err := c.conn.Subscribe(func() ([]byte, error) {
+		return binance.NewSubscribe(1, c.products, c.streams)
+	})
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+
+	decoder := exchange.NewDecoder(c.conn, c.maxFrameSize)
+
+	// a rejected subscription is the first message, a replayed feed may start with data
+	message, err := decoder.Next()
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	first, err := binance.ParseMessage(message)
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	if err := first.Err(); err != nil {
+		return fmt.Errorf("subscription error: %w", err)
+	}
+	if first.IsResponse() {
+		c.logger.Info(fmt.Sprintf("started binance subscription on products [%s]", strings.Join(c.products, ",")))
+	}
+
+	var g = errgroup.Group{}
+	hMap := startWriters(ctx, &g, c.uc, c.products)
+
+	if err := c.handle(ctx, first, decoder.ReceivedAt(), hMap); err != nil {
+		closeChannels(hMap)
+		return err
+	}
+
+	// keepalive, the feed has no heartbeats
+	if err := c.conn.SubscribeToHeartbeats(ctx); err != nil {
+		c.logger.Error(err)
+		closeChannels(hMap)
+		return err
+	}
+	go c.conn.MonitorHeartbeat(ctx, c.pongTimeout)
+
+	// single reader routing every message to the writer of its product
+	g.Go(func() error {
+		defer closeChannels(hMap)
+		return c.responseReader(ctx, decoder, hMap)
+	})
+
+	return g.Wait()
+}
+
+func (c *binanceClient) responseReader(ctx context.Context, decoder *exchange.Decoder, hMap map[string]chan entity.Message) error {
+	return readObjects(ctx, c.logger, decoder, c.backoff, func(message json.RawMessage) error {
+		m, err := binance.ParseMessage(message)
+		if err != nil {
+			c.logger.Error("Failed to parse response: ", err)
+			metrics.ParseErrors.WithLabelValues("response").Inc()
+			return nil
+		}
+
+		return c.handle(ctx, m, decoder.ReceivedAt(), hMap)
+	})
+}
+
+// handle sends the message of m received at receivedAt to the writer of its product
+func (c *binanceClient) handle(ctx context.Context, m *binance.Message, receivedAt time.Time, hMap map[string]chan entity.Message) error {
+	if err := m.Err(); err != nil {
+		c.logger.Error(err)
+		return nil
+	}
+	if m.IsResponse() {
+		// subscriptions replayed after a reconnect
+		c.logger.Info("subscriptions confirmed")
+		return nil
+	}
+
+	msg, ok, err := m.ToMessage(receivedAt)
+	if err != nil {
+		c.logger.Error(err)
+		metrics.ParseErrors.WithLabelValues("message").Inc()
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	productID := messageProduct(msg)
+	metrics.Messages.WithLabelValues(messageType(msg), productID).Inc()
+
+	return dispatch(ctx, hMap, productID, msg)
+}
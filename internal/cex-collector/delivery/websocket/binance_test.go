@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBinanceClient(t *testing.T) {
+	_, err := NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Streams: []string{"trade"}})
+	assert.Error(t, err)
+	_, err = NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Symbols: []string{"BTC-USDT"}})
+	assert.Error(t, err)
+	_, err = NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Symbols: []string{"BTCUSDT"}, Streams: []string{"trade"}})
+	assert.Error(t, err)
+	_, err = NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Symbols: []string{"BTC-XYZ"}, Streams: []string{"trade"}})
+	assert.Error(t, err)
+	_, err = NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Symbols: []string{"BTC-USDT"}, Streams: []string{"depth@100ms"}})
+	assert.ErrorContains(t, err, "unsupported binance stream")
+
+	c, err := NewBinanceClient(nil, nil, nopLogger{}, config.BinanceConfig{Symbols: []string{"btc-usdt", "ETH-BTC"}, Streams: []string{"trade"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTC-USDT", "ETH-BTC"}, c.products)
+}
+
+func Test_binanceClient_responseReader_routesByProduct(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"result":null,"id":1}`),
+		[]byte(`{"stream":"btcusdt@bookTicker","data":{"u":1,"s":"BTCUSDT","b":"1","B":"1","a":"2","A":"1"}}`),
+		[]byte(`{"stream":"ethbtc@trade","data":{"e":"trade","s":"ETHBTC","t":7,"p":"0.05","q":"2","T":1704164645000,"m":true}}`),
+		[]byte(`{"stream":"solusdt@trade","data":{"e":"trade","s":"SOLUSDT","t":1,"p":"1","q":"1","T":1704164645000,"m":true}}`),
+		[]byte(`{"stream":"btcusdt@aggTrade","data":{}}`),
+		nil,
+		[]byte(`{"error":{"code":1,"msg":"too many requests"},"id":2}`),
+		[]byte(`{"stream":"btcusdt@depth5","data":{"lastUpdateId":1,"bids":[["1","1"]],"asks":[]}}`),
+	}}
+	c := &binanceClient{logger: nopLogger{}, conn: conn, products: []string{"BTC-USDT", "ETH-BTC"}}
+
+	hMap := map[string]chan entity.Message{
+		"BTC-USDT": make(chan entity.Message, 10),
+		"ETH-BTC":  make(chan entity.Message, 10),
+	}
+
+	// unsupported streams, failed reconnects and errors do not stop the reader
+	require.NoError(t, c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap))
+
+	require.Len(t, hMap["BTC-USDT"], 2)
+	assert.Equal(t, "BTC-USDT", (<-hMap["BTC-USDT"]).Ticker.Symbol)
+	assert.True(t, (<-hMap["BTC-USDT"]).Book.Snapshot)
+
+	require.Len(t, hMap["ETH-BTC"], 1)
+	msg := <-hMap["ETH-BTC"]
+	assert.Equal(t, int64(7), msg.Order.TradeID)
+	assert.Equal(t, "binance", msg.Source)
+}
@@ -1,16 +1,16 @@
 package websocket
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
 	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
 	"github.com/nel349/bz-findata/pkg/entity"
 	"github.com/nel349/bz-findata/pkg/exchange"
@@ -19,12 +19,17 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// productBuffer is the number of messages queued for the writer of a product
+const productBuffer = 1024
+
 type client struct {
-	logger   logger.Logger
-	conn     exchange.Manager
-	uc       *usecase.Services
-	products []string
-	channels []string
+	logger       logger.Logger
+	conn         exchange.Manager
+	uc           *usecase.Services
+	products     []string
+	channels     []string
+	maxFrameSize int
+	backoff      exchange.Backoff // between the reads of a feed reported down
 }
 
 // NewSocketClient init websocket client from delivery layout
@@ -39,48 +44,45 @@ func NewSocketClient(conn exchange.Manager, uc *usecase.Services, logger logger.
 		uc,
 		cfg.Symbols,
 		cfg.Channels,
+		cfg.MaxFrameSize,
+		exchange.NewBackoff(cfg.ReconnectDelay, cfg.ReconnectMaxDelay),
 	}, nil
 }
 
 // Run websocket listener
 func (c *client) Run(ctx context.Context) error {
+	// subscribe to products, the subscription is signed again on every reconnect.
+	// Credentials are loaded on use, a replayed feed never needs them and a reconnect
+	// picks rotated ones up.
+	err := c.conn.Subscribe(func() ([]byte, error) {
+		auth, err := coinbase.NewAuth(ctx)
+		if err != nil {
+			return nil, err
+		}
 
-	var g = errgroup.Group{}
-	var hMap = make(map[string]chan entity.Message)
-
-	for _, symbol := range c.products {
-		hMap[symbol] = make(chan entity.Message)
+		signature, timestamp, err := auth.GenerateSignature()
+		if err != nil {
+			return nil, fmt.Errorf("error generate signature: %w", err)
+		}
 
-		// run readers
-		g.Go(func() error {
-			return c.uc.Exchange.ProcessStream(ctx, hMap[symbol])
+		return json.Marshal(map[string]interface{}{
+			"type":        "subscribe",
+			"product_ids": c.products,
+			"channels":    c.channels,
+			"signature":   signature,
+			"timestamp":   timestamp,
+			"key":         auth.Key,
+			"passphrase":  auth.Passphrase,
 		})
-	}
-
-	auth := coinbase.NewAuth()
-	signature, timestamp, err := auth.GenerateSignature()
-	if err != nil {
-		c.logger.Error("error generate signature: ", err)
-		return err
-	}
-	// subscribe to products
-	sData, _ := json.Marshal(map[string]interface{}{
-		"type":        "subscribe",
-		"product_ids": c.products,
-		"channels":    c.channels,
-		"signature":   signature,
-		"timestamp":   timestamp,
-		"key":         auth.Key,
-		"passphrase":  auth.Passphrase,
 	})
-
-	_, err = c.conn.WriteData(sData)
 	if err != nil {
 		c.logger.Error(err)
 		return err
 	}
 
-	message, err := c.conn.ReadData()
+	decoder := exchange.NewDecoder(c.conn, c.maxFrameSize)
+
+	message, err := decoder.Next()
 	if err != nil {
 		c.logger.Error(err)
 		return err
@@ -91,30 +93,45 @@ func (c *client) Run(ctx context.Context) error {
 		return err
 	}
 
+	// a feed replayed from the middle of a recording starts without the subscriptions
+	var first interface{}
 	switch v := result.(type) {
 	case *coinbase.Response:
 		if v.Type == coinbase.Error.String() {
-			c.logger.Fatal(fmt.Sprintf("Error: %s:%s", v.Message, v.Reason))
+			return fmt.Errorf("subscription error: %s:%s", v.Message, v.Reason)
 		}
 		if v.Type == coinbase.Subscriptions.String() {
 			c.logger.Info(fmt.Sprintf("started subscription on products [%s]", strings.Join(c.products, ",")))
 		}
 	default:
-		c.logger.Error(fmt.Sprintf("unknown response type: %T", result))
+		first = result
+	}
+
+	var g = errgroup.Group{}
+	hMap := startWriters(ctx, &g, c.uc, c.products)
+
+	if productID, msg, ok := c.toMessage(first, decoder.ReceivedAt()); ok {
+		if err := dispatch(ctx, hMap, productID, msg); err != nil {
+			closeChannels(hMap)
+			return err
+		}
 	}
 
 	// Subscribe to heartbeats
-	c.conn.SubscribeToHeartbeats(ctx)
+	if err := c.conn.SubscribeToHeartbeats(ctx); err != nil {
+		c.logger.Error(err)
+		closeChannels(hMap)
+		return err
+	}
 
 	// monitor heartbeat
 	go c.conn.MonitorHeartbeat(ctx, 10*time.Second)
 
-	// writers
-	for _, symbol := range c.products {
-		g.Go(func() error {
-			return c.responseReader(symbol, hMap)
-		})
-	}
+	// single reader routing every message to the writer of its product
+	g.Go(func() error {
+		defer closeChannels(hMap)
+		return c.responseReader(ctx, decoder, hMap)
+	})
 
 	if err = g.Wait(); err != nil {
 		return err
@@ -123,102 +140,178 @@ func (c *client) Run(ctx context.Context) error {
 	return nil
 }
 
-func (c *client) responseReader(symbol string, hMap map[string]chan entity.Message) error {
-	var mu = sync.Mutex{}
-	var buffer []byte
+// startWriters runs one writer per product on g, closeChannels stops them
+func startWriters(ctx context.Context, g *errgroup.Group, uc *usecase.Services, products []string) map[string]chan entity.Message {
+	hMap := make(map[string]chan entity.Message)
+	for _, symbol := range products {
+		ch := make(chan entity.Message, productBuffer)
+		hMap[symbol] = ch
 
-	for {
-		message, err := c.conn.ReadData()
+		g.Go(func() error {
+			return uc.Exchange.ProcessStream(ctx, ch)
+		})
+	}
+
+	return hMap
+}
+
+func closeChannels(hMap map[string]chan entity.Message) {
+	for _, ch := range hMap {
+		close(ch)
+	}
+}
+
+func (c *client) responseReader(ctx context.Context, decoder *exchange.Decoder, hMap map[string]chan entity.Message) error {
+	return readObjects(ctx, c.logger, decoder, c.backoff, func(message json.RawMessage) error {
+		response, err := coinbase.ParseResponse(message)
 		if err != nil {
-			return fmt.Errorf("failed to read message: %w", err)
+			c.logger.Error("Failed to parse response: ", err)
+			metrics.ParseErrors.WithLabelValues("response").Inc()
+			return nil
 		}
 
-		// Append new data to buffer
-		buffer = append(buffer, message...)
+		productID, msg, ok := c.toMessage(response, decoder.ReceivedAt())
+		if !ok {
+			return nil
+		}
+		metrics.Messages.WithLabelValues(messageType(msg), productID).Inc()
 
-		// Process complete JSON objects from buffer
-		for len(buffer) > 0 {
-			// Find first opening brace
-			start := bytes.IndexByte(buffer, '{')
-			if start == -1 {
-				buffer = nil
-				break
-			}
+		return dispatch(ctx, hMap, productID, msg)
+	})
+}
 
-			// Find matching closing brace
-			end := -1
-			depth := 0
-			for i := start; i < len(buffer); i++ {
-				switch buffer[i] {
-				case '{':
-					depth++
-				case '}':
-					depth--
-					if depth == 0 {
-						end = i + 1
-					}
-				}
+// readObjects hands every JSON object of the feed to handle until the feed ends, handle fails
+// or ctx is done. Frames that can't be decoded are skipped and a feed reported down is read
+// again after a delay of backoff
+func readObjects(ctx context.Context, log logger.Logger, decoder *exchange.Decoder, backoff exchange.Backoff, handle func(message json.RawMessage) error) error {
+	failures := 0
+	for {
+		message, err := decoder.Next()
+		if exchange.IsFrameError(err) {
+			log.Error("skipped frame: ", err)
+			metrics.ParseErrors.WithLabelValues("frame").Inc()
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			// end of a replayed feed
+			log.Info("feed ended")
+			return nil
+		}
+		if errors.Is(err, exchange.ErrReconnectFailed) {
+			// the feed is reported down, the next read starts reconnecting again
+			failures++
+			delay := backoff.Delay(failures)
+			log.Error(fmt.Sprintf("%v, reading again in %s", err, delay))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
 			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+		failures = 0
 
-			if end == -1 {
-				// No complete JSON object found
-				if start > 0 {
-					buffer = buffer[start:]
-				}
-				break
-			}
+		if err := handle(message); err != nil {
+			return err
+		}
+	}
+}
 
-			// Extract and parse the complete JSON object
-			jsonData := buffer[start:end]
-			buffer = buffer[end:]
+// dispatch sends the message to the writer of its product,
+// messages of products without a writer are dropped
+func dispatch(ctx context.Context, hMap map[string]chan entity.Message, productID string, msg entity.Message) error {
+	ch, ok := hMap[productID]
+	if !ok {
+		return nil
+	}
 
-			var rawJSON json.RawMessage
-			if err := json.Unmarshal(jsonData, &rawJSON); err != nil {
-				c.logger.Error("Error unmarshalling JSON: ", err)
-				continue
-			}
+	select {
+	case ch <- msg:
+		metrics.Backlog.WithLabelValues(productID).Set(float64(len(ch)))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-			response, err := coinbase.ParseResponse(rawJSON)
-			if err != nil {
-				c.logger.Error("Failed to parse response: ", err)
-				continue
-			}
+// messageType names the kind of msg for the metrics
+func messageType(msg entity.Message) string {
+	switch {
+	case msg.Ticker != nil:
+		return "ticker"
+	case msg.Order != nil:
+		return msg.Order.Type
+	case msg.Book != nil && msg.Book.Snapshot:
+		return "snapshot"
+	case msg.Book != nil:
+		return "l2update"
+	case msg.Heartbeat != nil:
+		return "heartbeat"
+	case msg.Candle != nil:
+		return "candle"
+	}
 
-			switch r := response.(type) {
-			case *coinbase.TickerResponse:
-				ticker, err := r.ToTicker()
-				if err != nil {
-					c.logger.Error(err)
-					continue
-				}
-				mu.Lock()
-				hMap[symbol] <- entity.Message{Ticker: ticker}
-				mu.Unlock()
-			case *coinbase.OrderResponse:
-				order, err := r.ToOrderResponse()
-				if err != nil {
-					c.logger.Error(err)
-					continue
-				}
-				mu.Lock()
-				hMap[symbol] <- entity.Message{Order: order}
-				mu.Unlock()
-			case *coinbase.HeartbeatResponse:
-				// update heartbeat
-				c.conn.UpdateHeartbeat()
-				heartbeat, err := r.ToHeartbeat()
-				if err != nil {
-					c.logger.Error(err)
-					continue
-				}
-				mu.Lock()
-				hMap[symbol] <- entity.Message{Heartbeat: heartbeat}
-				mu.Unlock()
-			case *coinbase.Response:
-				if r.Type == coinbase.Error.String() {
-					c.logger.Error(fmt.Errorf("API error: %s - %s", r.Message, r.Reason))
-				}
-			}
+	return "unknown"
+}
+
+// toMessage converts a parsed response received at receivedAt to an entity message of a product
+func (c *client) toMessage(response interface{}, receivedAt time.Time) (string, entity.Message, bool) {
+	switch r := response.(type) {
+	case *coinbase.TickerResponse:
+		ticker, err := r.ToTicker(receivedAt.UnixNano())
+		if err != nil {
+			c.logger.Error(err)
+			metrics.ParseErrors.WithLabelValues("message").Inc()
+			return "", entity.Message{}, false
+		}
+		return r.ProductID, entity.Message{Ticker: ticker}, true
+	case *coinbase.OrderResponse:
+		order, err := r.ToOrderResponse()
+		if err != nil {
+			c.logger.Error(err)
+			metrics.ParseErrors.WithLabelValues("message").Inc()
+			return "", entity.Message{}, false
+		}
+		return r.ProductID, entity.Message{Order: order}, true
+	case *coinbase.SnapshotResponse:
+		book, err := r.ToBookUpdate()
+		if err != nil {
+			c.logger.Error(err)
+			metrics.ParseErrors.WithLabelValues("message").Inc()
+			return "", entity.Message{}, false
+		}
+		return r.ProductID, entity.Message{Book: book}, true
+	case *coinbase.L2UpdateResponse:
+		book, err := r.ToBookUpdate()
+		if err != nil {
+			c.logger.Error(err)
+			metrics.ParseErrors.WithLabelValues("message").Inc()
+			return "", entity.Message{}, false
+		}
+		return r.ProductID, entity.Message{Book: book}, true
+	case *coinbase.HeartbeatResponse:
+		// update heartbeat
+		c.conn.UpdateHeartbeat()
+		metrics.HeartbeatAge.Touch(r.ProductID)
+		heartbeat, err := r.ToHeartbeat()
+		if err != nil {
+			c.logger.Error(err)
+			metrics.ParseErrors.WithLabelValues("message").Inc()
+			return "", entity.Message{}, false
+		}
+		return r.ProductID, entity.Message{Heartbeat: heartbeat}, true
+	case *coinbase.Response:
+		if r.Type == coinbase.Error.String() {
+			c.logger.Error(fmt.Errorf("API error: %s - %s", r.Message, r.Reason))
+		}
+		if r.Type == coinbase.Subscriptions.String() {
+			// heartbeats and subscriptions replayed after a reconnect
+			c.logger.Info("subscriptions confirmed")
 		}
 	}
+
+	return "", entity.Message{}, false
 }
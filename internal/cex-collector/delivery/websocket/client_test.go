@@ -1,13 +1,28 @@
 package websocket
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
 	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
+	"github.com/nel349/bz-findata/pkg/entity"
 	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase/coinbasetest"
 	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSocketClient(t *testing.T) {
@@ -23,7 +38,12 @@ func TestNewSocketClient(t *testing.T) {
 		want    *client
 		wantErr bool
 	}{
-		{name: testing.CoverMode(), args: args{conn: nil, uc: nil, logger: nil, cfg: config.ExchangeConfig{}}, want: nil, wantErr: true},
+		{name: "without symbols", args: args{conn: nil, uc: nil, logger: nil, cfg: config.ExchangeConfig{}}, want: nil, wantErr: true},
+		{
+			name: "with symbols",
+			args: args{logger: nopLogger{}, cfg: config.ExchangeConfig{Symbols: []string{"BTC-USD"}, Channels: []string{"ticker"}, MaxFrameSize: 1024}},
+			want: &client{logger: nopLogger{}, products: []string{"BTC-USD"}, channels: []string{"ticker"}, maxFrameSize: 1024, backoff: exchange.NewBackoff(0, 0)},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -38,3 +58,295 @@ func TestNewSocketClient(t *testing.T) {
 		})
 	}
 }
+
+// scriptedConn is an exchange.Manager replaying prepared frames,
+// a nil frame is a failed reconnect
+type scriptedConn struct {
+	frames [][]byte
+}
+
+func (s *scriptedConn) Subscribe(func() ([]byte, error)) error          { return nil }
+func (s *scriptedConn) OnEvent(func(exchange.Event))                    {}
+func (s *scriptedConn) SubscribeToHeartbeats(context.Context) error     { return nil }
+func (s *scriptedConn) MonitorHeartbeat(context.Context, time.Duration) {}
+func (s *scriptedConn) UpdateHeartbeat()                                {}
+func (s *scriptedConn) CloseConnection() error                          { return nil }
+func (s *scriptedConn) WriteData(message []byte) (int, error)           { return len(message), nil }
+func (s *scriptedConn) ReadData() ([]byte, error) {
+	if len(s.frames) == 0 {
+		return nil, io.EOF
+	}
+	frame := s.frames[0]
+	s.frames = s.frames[1:]
+	if frame == nil {
+		return nil, fmt.Errorf("%w after 3 attempts: connection refused", exchange.ErrReconnectFailed)
+	}
+	return frame, nil
+}
+
+func Test_client_responseReader_routesByProduct(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"type":"ticker","product_id":"BTC-USD","best_bid":"1","best_ask":"2"}`),
+		[]byte(`{"type":"ticker","product_id":"ETH-USD","best_bid":"3","best_ask":"4"}{"type":"heartbeat","product_id":"ETH-USD","sequence":7}`),
+		[]byte(`{"type":"ticker","product_id":"BTC-`),
+		[]byte(`{"type":"ticker","product_id":"SOL-USD","best_bid":"5","best_ask":"6"}`),
+	}}
+	c := &client{logger: nopLogger{}, conn: conn}
+
+	hMap := map[string]chan entity.Message{
+		"BTC-USD": make(chan entity.Message, 10),
+		"ETH-USD": make(chan entity.Message, 10),
+	}
+
+	// the end of the feed is not an error
+	err := c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap)
+	assert.NoError(t, err)
+
+	require.Len(t, hMap["BTC-USD"], 1)
+	assert.Equal(t, "BTC-USD", (<-hMap["BTC-USD"]).Ticker.Symbol)
+
+	require.Len(t, hMap["ETH-USD"], 2)
+	assert.Equal(t, "ETH-USD", (<-hMap["ETH-USD"]).Ticker.Symbol)
+	assert.Equal(t, "ETH-USD", (<-hMap["ETH-USD"]).Heartbeat.ProductID)
+}
+
+func Test_client_responseReader_reconnectFailed(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"type":"ticker","product_id":"BTC-USD","best_bid":"1","best_ask":"2"}`),
+		nil,
+		[]byte(`{"type":"ticker","product_id":"BTC-USD","best_bid":"3","best_ask":"4"}`),
+	}}
+	c := &client{logger: nopLogger{}, conn: conn}
+	hMap := map[string]chan entity.Message{"BTC-USD": make(chan entity.Message, 10)}
+
+	// the reader keeps going once the connection is back
+	require.NoError(t, c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap))
+	assert.Len(t, hMap["BTC-USD"], 2)
+}
+
+func Test_client_responseReader_reconnectFailedBackoff(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{nil, nil}}
+	c := &client{logger: nopLogger{}, conn: conn, backoff: exchange.NewBackoff(time.Hour, time.Hour)}
+
+	// a feed staying down is not read again before the delay
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.responseReader(ctx, exchange.NewDecoder(conn, 0), map[string]chan entity.Message{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, conn.frames, 1)
+}
+
+func Test_client_responseReader_metrics(t *testing.T) {
+	conn := &scriptedConn{frames: [][]byte{
+		[]byte(`{"type":"ticker","product_id":"ADA-USD","best_bid":"1","best_ask":"2"}`),
+		[]byte(`{"type":"received","product_id":"ADA-USD","size":"1","price":"2","side":"buy"}`),
+		[]byte(`{"type":"heartbeat","product_id":"ADA-USD","sequence":7}`),
+		[]byte(`{"type":"ticker","product_id":"ADA-`),
+	}}
+	c := &client{logger: nopLogger{}, conn: conn}
+	hMap := map[string]chan entity.Message{"ADA-USD": make(chan entity.Message, 10)}
+
+	frameErrors := testutil.ToFloat64(metrics.ParseErrors.WithLabelValues("frame"))
+	require.NoError(t, c.responseReader(context.Background(), exchange.NewDecoder(conn, 0), hMap))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Messages.WithLabelValues("ticker", "ADA-USD")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Messages.WithLabelValues("received", "ADA-USD")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Messages.WithLabelValues("heartbeat", "ADA-USD")))
+	assert.Equal(t, frameErrors+1, testutil.ToFloat64(metrics.ParseErrors.WithLabelValues("frame")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.Backlog.WithLabelValues("ADA-USD")))
+}
+
+func Test_dispatch_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hMap := map[string]chan entity.Message{"BTC-USD": make(chan entity.Message)}
+	err := dispatch(ctx, hMap, "BTC-USD", entity.Message{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// unknown products are dropped
+	assert.NoError(t, dispatch(ctx, hMap, "SOL-USD", entity.Message{}))
+}
+
+// memoryExchange is a repository.Exchange keeping everything in memory
+type memoryExchange struct {
+	mu     sync.Mutex
+	ticks  []entity.Ticker
+	orders []entity.Order
+	gaps   []entity.FeedGap
+}
+
+func (m *memoryExchange) CreateTick(_ context.Context, message entity.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ticks = append(m.ticks, *message.Ticker)
+	return nil
+}
+
+func (m *memoryExchange) CreateOrder(_ context.Context, message entity.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders = append(m.orders, *message.Order)
+	return nil
+}
+
+func (m *memoryExchange) CreateBookSnapshot(context.Context, entity.BookSnapshot) error { return nil }
+
+func (m *memoryExchange) CreateFeedGap(_ context.Context, gap entity.FeedGap) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gaps = append(m.gaps, gap)
+	return nil
+}
+
+func (m *memoryExchange) CreateCandles(context.Context, []entity.Candle) error { return nil }
+
+func (m *memoryExchange) counts() (ticks, orders int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.ticks), len(m.orders)
+}
+
+func (m *memoryExchange) symbols() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	symbols := make(map[string]int)
+	for _, tick := range m.ticks {
+		symbols[tick.Symbol]++
+	}
+	return symbols
+}
+
+// feedCredentials sets the websocket credentials read by coinbase.NewAuth
+func feedCredentials(t *testing.T) *coinbasetest.Credentials {
+	creds := &coinbasetest.Credentials{
+		Key:        "test-key",
+		Secret:     base64.StdEncoding.EncodeToString([]byte("test-secret")),
+		Passphrase: "test-passphrase",
+	}
+	t.Setenv("COINBASE_WS_API_KEY", creds.Key)
+	t.Setenv("COINBASE_WS_API_SECRET", creds.Secret)
+	t.Setenv("COINBASE_WS_API_PASSPHRASE", creds.Passphrase)
+
+	return creds
+}
+
+// startCollector runs the socket client against the feed of server
+func startCollector(t *testing.T, server *coinbasetest.Server, store repository.Exchange) (exchange.Manager, <-chan error, context.CancelFunc) {
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Url:               server.URL,
+		Origin:            server.Origin,
+		Symbols:           []string{"BTC-USD", "ETH-USD"},
+		Channels:          []string{"ticker", "full"},
+		ReconnectDelay:    10 * time.Millisecond,
+		ReconnectMaxDelay: 50 * time.Millisecond,
+		ReconnectAttempts: 10,
+	}}
+
+	conn, err := coinbase.NewCoinbaseClient(cfg)
+	require.NoError(t, err)
+
+	orderRules, err := rules.NewEngine("", nil)
+	require.NoError(t, err)
+
+	uc := usecase.NewUseCase(&repository.Repositories{Exchange: store}, &usecase.Packages{
+		Logger:     nopLogger{},
+		OrderRules: orderRules,
+		Book:       config.BookConfig{SnapshotInterval: time.Minute},
+	})
+
+	c, err := NewSocketClient(conn, uc, nopLogger{}, cfg.Exchange)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run(ctx)
+	}()
+
+	return conn, done, cancel
+}
+
+func Test_client_Run_fakeFeed(t *testing.T) {
+	creds := feedCredentials(t)
+	server := coinbasetest.NewServer(coinbasetest.Options{Credentials: creds, HeartbeatInterval: 100 * time.Millisecond})
+	defer server.Close()
+
+	store := &memoryExchange{}
+	conn, done, cancel := startCollector(t, server, store)
+	defer func() {
+		cancel()
+		_ = conn.CloseConnection()
+		<-done
+	}()
+
+	// signed products subscription, then heartbeats
+	require.NoError(t, server.WaitSubscriptions(2, 5*time.Second))
+	subs := server.Subscriptions()
+	assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, subs[0].ProductIDs)
+	assert.Equal(t, creds.Key, subs[0].Key)
+	assert.NotEmpty(t, subs[0].Signature)
+	assert.Equal(t, []string{"heartbeat"}, subs[1].Channels)
+
+	server.SendTicker("BTC-USD", 100, 101)
+	server.SendTicker("ETH-USD", 10, 11)
+	server.SendTicker("SOL-USD", 1, 2) // not subscribed
+	server.SendError("Temporary failure", "test")
+	server.SendFrame(`{"type":"ticker","product_id":`)
+	server.SendMatch("BTC-USD", "buy", 1, 30000)
+	server.SendMatch("ETH-USD", "sell", 1, 10) // below the default rules
+
+	assert.Eventually(t, func() bool {
+		ticks, orders := store.counts()
+		return ticks == 2 && orders == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"BTC-USD": 1, "ETH-USD": 1}, store.symbols())
+
+	// the exchange drops the connection, both subscriptions are replayed
+	server.Disconnect()
+	require.NoError(t, server.WaitSubscriptions(4, 5*time.Second))
+	assert.Equal(t, 2, server.Connections())
+	subs = server.Subscriptions()
+	assert.Equal(t, creds.Key, subs[2].Key)
+	assert.Equal(t, []string{"heartbeat"}, subs[3].Channels)
+
+	server.SendTicker("BTC-USD", 102, 103)
+
+	assert.Eventually(t, func() bool {
+		ticks, _ := store.counts()
+		return ticks == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"BTC-USD": 2, "ETH-USD": 1}, store.symbols())
+}
+
+func Test_client_Run_fakeFeedAuthFailed(t *testing.T) {
+	feedCredentials(t)
+	server := coinbasetest.NewServer(coinbasetest.Options{Credentials: &coinbasetest.Credentials{
+		Key:        "other-key",
+		Secret:     base64.StdEncoding.EncodeToString([]byte("other-secret")),
+		Passphrase: "other-passphrase",
+	}})
+	defer server.Close()
+
+	conn, done, cancel := startCollector(t, server, &memoryExchange{})
+	defer cancel()
+	defer conn.CloseConnection()
+
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "Authentication Failed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not rejected")
+	}
+	assert.Empty(t, server.Subscriptions())
+}
+
+type nopLogger struct{}
+
+func (nopLogger) InitLogger()          {}
+func (nopLogger) Debug(...interface{}) {}
+func (nopLogger) Info(...interface{})  {}
+func (nopLogger) Error(...interface{}) {}
+func (nopLogger) Fatal(...interface{}) {}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/delivery/websocket"
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/internal/cex-collector/usecase"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/feedlog"
+	"github.com/nel349/bz-findata/pkg/health"
+	"github.com/nel349/bz-findata/pkg/logger"
+)
+
+// Feeds of Coinbase
+const (
+	// exchangeFeed is the deprecated Exchange feed
+	exchangeFeed = "exchange"
+	// advancedFeed is the Advanced Trade feed of the products of EXCHANGE_ADVANCED_SYMBOLS
+	advancedFeed = "advanced"
+	// binanceFeed is the Binance spot feed of the products of BINANCE_SYMBOLS
+	binanceFeed = "binance"
+)
+
+// feed is a connection and the kind of feed it reads
+type feed struct {
+	kind string
+	conn exchange.Manager
+}
+
+// exchangeConfig is cfg with the Symbols left on the Exchange feed
+func exchangeConfig(cfg *config.Config) *config.Config {
+	exchangeCfg := *cfg
+	exchangeCfg.Exchange.Symbols = nil
+	for _, symbol := range cfg.Exchange.Symbols {
+		if !slices.Contains(cfg.Exchange.AdvancedSymbols, symbol) {
+			exchangeCfg.Exchange.Symbols = append(exchangeCfg.Exchange.Symbols, symbol)
+		}
+	}
+
+	return &exchangeCfg
+}
+
+// checkVenues fails when a product is read from both Coinbase and Binance,
+// their rows could not be told apart
+func checkVenues(cfg *config.Config) error {
+	for _, symbol := range cfg.Binance.Symbols {
+		for _, coinbaseSymbol := range slices.Concat(cfg.Exchange.Symbols, cfg.Exchange.AdvancedSymbols) {
+			if strings.EqualFold(symbol, coinbaseSymbol) {
+				return fmt.Errorf("product %s is read from both coinbase and binance", coinbaseSymbol)
+			}
+		}
+	}
+
+	return nil
+}
+
+// feedClient is the websocket client reading f
+type feedClient interface {
+	Run(ctx context.Context) error
+}
+
+func newFeedClient(f feed, cfg *config.Config, uc *usecase.Services, loggerProvider logger.Logger) (feedClient, error) {
+	switch f.kind {
+	case exchangeFeed:
+		return websocket.NewSocketClient(f.conn, uc, loggerProvider, exchangeConfig(cfg).Exchange)
+	case advancedFeed:
+		return websocket.NewAdvancedTradeClient(f.conn, uc, loggerProvider, cfg.Exchange)
+	case binanceFeed:
+		return websocket.NewBinanceClient(f.conn, uc, loggerProvider, cfg.Binance)
+	}
+
+	return nil, fmt.Errorf("unknown feed %q", f.kind)
+}
+
+// watch reports the health of conn as check and touches activity on every frame,
+// the frames are recorded with prefix when recording is on. The returned close
+// stops the recording
+func watch(cfg *config.Config, loggerProvider logger.Logger, checker *health.Checker, activity *health.Activity, check, prefix string, client exchange.Manager) (exchange.Manager, func() error, error) {
+	// a failed reconnect is reported and retried instead of exiting
+	state := health.NewState()
+	checker.Add(check, state.Check)
+
+	client.OnEvent(func(event exchange.Event) {
+		state.Set(feedStatus(event), event.Err)
+		if event.State == exchange.Reconnected {
+			metrics.Reconnects.Inc()
+		}
+		if event.State == exchange.ReconnectFailed {
+			loggerProvider.Error(event)
+			return
+		}
+		loggerProvider.Info(event)
+	})
+
+	var conn exchange.Manager = activeConn{Manager: client, activity: activity}
+
+	// raw feed recording
+	if cfg.Record.Dir == "" {
+		return conn, func() error { return nil }, nil
+	}
+	recordWriter, err := feedlog.NewWriter(cfg.Record.Dir, prefix, cfg.Record.MaxBytes, cfg.Record.RotateInterval)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn = feedlog.NewRecorder(conn, recordWriter, func(err error) {
+		loggerProvider.Error(fmt.Sprintf("failed to record frame: %v", err))
+	})
+	loggerProvider.Info(fmt.Sprintf("recording %s feed to %s", check, cfg.Record.Dir))
+
+	return conn, recordWriter.Close, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeConfig(t *testing.T) {
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Symbols:         []string{"BTC-USD", "ETH-USD", "SOL-USD"},
+		AdvancedSymbols: []string{"ETH-USD"},
+	}}
+
+	assert.Equal(t, []string{"BTC-USD", "SOL-USD"}, exchangeConfig(cfg).Exchange.Symbols)
+	assert.Equal(t, []string{"BTC-USD", "ETH-USD", "SOL-USD"}, cfg.Exchange.Symbols)
+
+	// every product on Advanced Trade
+	cfg.Exchange.AdvancedSymbols = cfg.Exchange.Symbols
+	assert.Empty(t, exchangeConfig(cfg).Exchange.Symbols)
+}
+
+func TestNewFeedClient(t *testing.T) {
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Symbols:          []string{"BTC-USD", "ETH-USD"},
+		Channels:         []string{"ticker"},
+		AdvancedSymbols:  []string{"ETH-USD"},
+		AdvancedChannels: []string{"ticker"},
+	}, Binance: config.BinanceConfig{
+		Symbols: []string{"BTC-USDT"},
+		Streams: []string{"trade"},
+	}}
+
+	for _, kind := range []string{exchangeFeed, advancedFeed, binanceFeed} {
+		client, err := newFeedClient(feed{kind: kind}, cfg, nil, nil)
+		require.NoError(t, err, kind)
+		assert.NotNil(t, client)
+	}
+
+	_, err := newFeedClient(feed{kind: "kraken"}, cfg, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCheckVenues(t *testing.T) {
+	cfg := &config.Config{
+		Exchange: config.ExchangeConfig{Symbols: []string{"BTC-USD"}, AdvancedSymbols: []string{"ETH-USDT"}},
+		Binance:  config.BinanceConfig{Symbols: []string{"BTC-USDT"}},
+	}
+	assert.NoError(t, checkVenues(cfg))
+
+	cfg.Binance.Symbols = append(cfg.Binance.Symbols, "eth-usdt")
+	assert.ErrorContains(t, checkVenues(cfg), "ETH-USDT")
+}
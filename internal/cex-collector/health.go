@@ -0,0 +1,33 @@
+package app
+
+import (
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/health"
+)
+
+// feedStatus is the health of the feed after event
+func feedStatus(event exchange.Event) health.Status {
+	switch event.State {
+	case exchange.Disconnected, exchange.Reconnecting:
+		return health.Degraded
+	case exchange.ReconnectFailed:
+		return health.Down
+	}
+
+	return health.OK
+}
+
+// activeConn is a connection touching activity on every frame read
+type activeConn struct {
+	exchange.Manager
+	activity *health.Activity
+}
+
+func (c activeConn) ReadData() ([]byte, error) {
+	frame, err := c.Manager.ReadData()
+	if err == nil {
+		c.activity.Touch()
+	}
+
+	return frame, err
+}
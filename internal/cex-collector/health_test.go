@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedStatus(t *testing.T) {
+	assert.Equal(t, health.Degraded, feedStatus(exchange.Event{State: exchange.Disconnected}))
+	assert.Equal(t, health.Degraded, feedStatus(exchange.Event{State: exchange.Reconnecting, Attempt: 2}))
+	assert.Equal(t, health.Down, feedStatus(exchange.Event{State: exchange.ReconnectFailed}))
+	assert.Equal(t, health.OK, feedStatus(exchange.Event{State: exchange.Reconnected}))
+}
+
+type frameConn struct {
+	exchange.Manager
+	err error
+}
+
+func (c frameConn) ReadData() ([]byte, error) { return []byte(`{}`), c.err }
+
+func TestActiveConn(t *testing.T) {
+	activity := health.NewActivity(time.Minute)
+
+	_, _ = activeConn{Manager: frameConn{err: exchange.ErrReconnectFailed}, activity: activity}.ReadData()
+	assert.Equal(t, health.Degraded, activity.Check(context.Background()).Status)
+
+	_, _ = activeConn{Manager: frameConn{}, activity: activity}.ReadData()
+	assert.Equal(t, health.OK, activity.Check(context.Background()).Status)
+}
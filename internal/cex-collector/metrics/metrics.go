@@ -0,0 +1,97 @@
+// Package metrics holds the Prometheus metrics of the collector
+package metrics
+
+import (
+	"github.com/nel349/bz-findata/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "cex_collector"
+
+var (
+	// Messages counts the messages received per type and product
+	Messages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_received_total",
+		Help:      "Messages received from the feed by type and product.",
+	}, []string{"type", "product"})
+
+	// ParseErrors counts the frames and messages skipped per stage: frame, response or message
+	ParseErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "parse_errors_total",
+		Help:      "Frames and messages of the feed skipped because they could not be parsed.",
+	}, []string{"stage"})
+
+	// FeedGaps counts the sequence gaps detected per product
+	FeedGaps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_gaps_total",
+		Help:      "Sequence gaps detected in the feed by product.",
+	}, []string{"product"})
+
+	// FeedGapMissing counts the messages missed in the sequence gaps per product
+	FeedGapMissing = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feed_gap_missing_messages_total",
+		Help:      "Messages missing from the sequence gaps of the feed by product.",
+	}, []string{"product"})
+
+	// InsertDuration observes the writes per table
+	InsertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "insert_duration_seconds",
+		Help:      "Duration of the writes to the storage by table.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"table"})
+
+	// InsertFailures counts the failed writes per table
+	InsertFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "insert_failures_total",
+		Help:      "Failed writes to the storage by table.",
+	}, []string{"table"})
+
+	// SinkFailures counts the failed copies to the sinks, like the Parquet archive, per table
+	SinkFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sink_failures_total",
+		Help:      "Failed copies of ticks and orders to the sinks by table.",
+	}, []string{"table"})
+
+	// PublishFailures counts the events dropped or rejected by the message bus
+	PublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bus_publish_failures_total",
+		Help:      "Events dropped by the publisher or rejected by the message bus.",
+	})
+
+	// WriteAge is the time since the last successful write per table
+	WriteAge = metrics.NewAge(
+		prometheus.BuildFQName(namespace, "", "last_write_age_seconds"),
+		"Seconds since the last successful write to the storage by table.",
+		"table",
+	)
+
+	// Reconnects counts the reconnections to the feed
+	Reconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconnects_total",
+		Help:      "Reconnections to the feed.",
+	})
+
+	// HeartbeatAge is the time since the last heartbeat per product
+	HeartbeatAge = metrics.NewAge(
+		prometheus.BuildFQName(namespace, "", "heartbeat_age_seconds"),
+		"Seconds since the last heartbeat by product.",
+		"product",
+	)
+
+	// Backlog is the number of messages waiting for the writer of each product
+	Backlog = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "channel_backlog",
+		Help:      "Messages waiting to be processed by product.",
+	}, []string{"product"})
+)
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/nel349/bz-findata/pkg/migrations"
+)
+
+// Migrate runs the migrate subcommand args on the database
+func Migrate(ctx context.Context, cfg *config.Config, args []string) error {
+	db, err := database.Connect(cfg.Database.Driver, cfg.Database.Host, cfg.Database.User, cfg.Database.Password, cfg.Database.Base)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return migrations.Command(ctx, db, cfg.Database.Driver, args, os.Stdout)
+}
+
+// migrateUp applies the pending migrations of the database on startup
+func migrateUp(ctx context.Context, db *sqlx.DB, driver string, loggerProvider logger.Logger) error {
+	done, err := migrations.Up(ctx, db, driver)
+	for _, migration := range done {
+		loggerProvider.Info(fmt.Sprintf("applied migration %04d_%s", migration.Version, migration.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate the database: %w", err)
+	}
+
+	return nil
+}
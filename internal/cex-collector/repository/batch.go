@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nel349/bz-findata/pkg/batch"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+// BulkExchange is an Exchange storage able to write many ticks and orders at once
+type BulkExchange interface {
+	Exchange
+	// CreateTicks write in storage a batch of ticker data
+	CreateTicks(ctx context.Context, ticks []entity.Ticker) error
+	// CreateOrders write in storage a batch of order data
+	CreateOrders(ctx context.Context, orders []entity.Order) error
+}
+
+// DeadLetters keeps the messages that could not be stored
+type DeadLetters interface {
+	// Add keeps message with the error that failed it
+	Add(message entity.Message, cause error) error
+}
+
+// Writer runs the background writes of a repository
+type Writer interface {
+	// Run writes until ctx is done, then flushes what is buffered
+	Run(ctx context.Context) error
+}
+
+// batchExchange buffers ticks and orders and writes them in batches,
+// other writes go straight to the storage
+type batchExchange struct {
+	BulkExchange
+	ticks  *batch.Batcher[entity.Ticker]
+	orders *batch.Batcher[entity.Order]
+}
+
+// NewBatchExchange init exchange repository batching the ticks and orders written to store,
+// batches failing every retry go to deadLetters, or are dropped when it is nil
+func NewBatchExchange(store BulkExchange, opts batch.Options, deadLetters DeadLetters, logger logger.Logger) *batchExchange {
+	return &batchExchange{
+		BulkExchange: store,
+		ticks: batch.NewBatcher(opts, store.CreateTicks, func(ticks []entity.Ticker, err error) {
+			logger.Error(fmt.Sprintf("failed to write batch of %d ticks: %v", len(ticks), err))
+			for i := range ticks {
+				deadLetter(deadLetters, logger, entity.Message{Ticker: &ticks[i]}, err)
+			}
+		}),
+		orders: batch.NewBatcher(opts, store.CreateOrders, func(orders []entity.Order, err error) {
+			logger.Error(fmt.Sprintf("failed to write batch of %d orders: %v", len(orders), err))
+			for i := range orders {
+				deadLetter(deadLetters, logger, entity.Message{Order: &orders[i]}, err)
+			}
+		}),
+	}
+}
+
+func deadLetter(deadLetters DeadLetters, logger logger.Logger, message entity.Message, cause error) {
+	if deadLetters == nil {
+		return
+	}
+	if err := deadLetters.Add(message, cause); err != nil {
+		logger.Error(fmt.Sprintf("lost message, failed to write dead letter: %v", err))
+	}
+}
+
+func (b *batchExchange) CreateTick(ctx context.Context, message entity.Message) error {
+	if message.Ticker == nil {
+		return fmt.Errorf("message should be ticker")
+	}
+
+	return b.ticks.Add(ctx, *message.Ticker)
+}
+
+func (b *batchExchange) CreateOrder(ctx context.Context, message entity.Message) error {
+	if message.Order == nil {
+		return fmt.Errorf("message should be order")
+	}
+
+	return b.orders.Add(ctx, *message.Order)
+}
+
+// Run writes the batches until ctx is done, then flushes the buffered ticks and orders
+func (b *batchExchange) Run(ctx context.Context) error {
+	var g errgroup.Group
+
+	g.Go(func() error { return b.ticks.Run(ctx) })
+	g.Go(func() error { return b.orders.Run(ctx) })
+
+	return g.Wait()
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/batch"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bulkStore struct {
+	mu     sync.Mutex
+	ticks  [][]entity.Ticker
+	orders [][]entity.Order
+	gaps   []entity.FeedGap
+}
+
+func (s *bulkStore) CreateTick(context.Context, entity.Message) error  { return nil }
+func (s *bulkStore) CreateOrder(context.Context, entity.Message) error { return nil }
+func (s *bulkStore) CreateBookSnapshot(context.Context, entity.BookSnapshot) error {
+	return nil
+}
+func (s *bulkStore) CreateFeedGap(_ context.Context, gap entity.FeedGap) error {
+	s.gaps = append(s.gaps, gap)
+	return nil
+}
+func (s *bulkStore) CreateCandles(context.Context, []entity.Candle) error { return nil }
+func (s *bulkStore) CreateTicks(_ context.Context, ticks []entity.Ticker) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ticks = append(s.ticks, ticks)
+	return nil
+}
+func (s *bulkStore) CreateOrders(_ context.Context, orders []entity.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders = append(s.orders, orders)
+	return nil
+}
+
+type deadLetters struct {
+	messages []entity.Message
+}
+
+func (d *deadLetters) Add(message entity.Message, _ error) error {
+	d.messages = append(d.messages, message)
+	return nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) InitLogger()          {}
+func (nopLogger) Debug(...interface{}) {}
+func (nopLogger) Info(...interface{})  {}
+func (nopLogger) Error(...interface{}) {}
+func (nopLogger) Fatal(...interface{}) {}
+
+func TestBatchExchange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := &bulkStore{}
+	exchange := NewBatchExchange(store, batch.Options{Size: 2, Interval: time.Hour}, &deadLetters{}, nopLogger{})
+	done := make(chan error)
+	go func() { done <- exchange.Run(ctx) }()
+
+	require.NoError(t, exchange.CreateTick(ctx, entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD", Timestamp: 1}}))
+	require.NoError(t, exchange.CreateOrder(ctx, entity.Message{Order: &entity.Order{ProductID: "BTC-USD", Sequence: 1}}))
+	require.NoError(t, exchange.CreateOrder(ctx, entity.Message{Order: &entity.Order{ProductID: "BTC-USD", Sequence: 2}}))
+	require.NoError(t, exchange.CreateOrder(ctx, entity.Message{Order: &entity.Order{ProductID: "BTC-USD", Sequence: 3}}))
+
+	assert.Error(t, exchange.CreateTick(ctx, entity.Message{}))
+	assert.Error(t, exchange.CreateOrder(ctx, entity.Message{}))
+
+	// not batched
+	require.NoError(t, exchange.CreateFeedGap(ctx, entity.FeedGap{ProductID: "BTC-USD"}))
+	assert.Len(t, store.gaps, 1)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, [][]entity.Ticker{{{Symbol: "BTC-USD", Timestamp: 1}}}, store.ticks)
+	require.Len(t, store.orders, 2)
+	assert.Len(t, store.orders[0], 2)
+	assert.Len(t, store.orders[1], 1)
+}
+
+func Test_deadLetter_nil(t *testing.T) {
+	// without a dead letter queue the failed batch is only logged
+	assert.NotPanics(t, func() {
+		deadLetter(nil, nopLogger{}, entity.Message{Order: &entity.Order{ProductID: "BTC-USD"}}, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// meteredExchange times the writes to store and counts the failed ones per table
+type meteredExchange struct {
+	store BulkExchange
+}
+
+// NewMeteredExchange init exchange repository recording the metrics of the writes to store
+func NewMeteredExchange(store BulkExchange) *meteredExchange {
+	return &meteredExchange{store: store}
+}
+
+// CreateTick write in storage ticker data
+func (m *meteredExchange) CreateTick(ctx context.Context, message entity.Message) error {
+	return observe("ticks", func() error { return m.store.CreateTick(ctx, message) })
+}
+
+// CreateOrder write in storage order data
+func (m *meteredExchange) CreateOrder(ctx context.Context, message entity.Message) error {
+	return observe("orders", func() error { return m.store.CreateOrder(ctx, message) })
+}
+
+// CreateTicks write in storage a batch of ticker data
+func (m *meteredExchange) CreateTicks(ctx context.Context, ticks []entity.Ticker) error {
+	return observe("ticks", func() error { return m.store.CreateTicks(ctx, ticks) })
+}
+
+// CreateOrders write in storage a batch of order data
+func (m *meteredExchange) CreateOrders(ctx context.Context, orders []entity.Order) error {
+	return observe("orders", func() error { return m.store.CreateOrders(ctx, orders) })
+}
+
+// CreateBookSnapshot write in storage level2 book snapshot
+func (m *meteredExchange) CreateBookSnapshot(ctx context.Context, snapshot entity.BookSnapshot) error {
+	return observe("book_snapshots", func() error { return m.store.CreateBookSnapshot(ctx, snapshot) })
+}
+
+// CreateFeedGap write in storage a range of sequences missing from the feed
+func (m *meteredExchange) CreateFeedGap(ctx context.Context, gap entity.FeedGap) error {
+	return observe("feed_gaps", func() error { return m.store.CreateFeedGap(ctx, gap) })
+}
+
+// CreateCandles merges in storage candles holding part of the trades of their bar
+func (m *meteredExchange) CreateCandles(ctx context.Context, candles []entity.Candle) error {
+	return observe("candles", func() error { return m.store.CreateCandles(ctx, candles) })
+}
+
+// observe records the duration and the outcome of the write to table
+func observe(table string, write func() error) error {
+	start := time.Now()
+	err := write()
+	metrics.InsertDuration.WithLabelValues(table).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.InsertFailures.WithLabelValues(table).Inc()
+		return err
+	}
+	metrics.WriteAge.Touch(table)
+
+	return nil
+}
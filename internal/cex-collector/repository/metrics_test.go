@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct {
+	bulkStore
+}
+
+func (s *failingStore) CreateCandles(context.Context, []entity.Candle) error {
+	return errors.New("deadlock")
+}
+
+func TestMeteredExchange(t *testing.T) {
+	store := &failingStore{}
+	exchange := NewMeteredExchange(store)
+	ctx := context.Background()
+
+	failures := testutil.ToFloat64(metrics.InsertFailures.WithLabelValues("candles"))
+	writes := testutil.CollectAndCount(metrics.InsertDuration, "cex_collector_insert_duration_seconds")
+
+	assert.NoError(t, exchange.CreateTicks(ctx, []entity.Ticker{{Symbol: "BTC-USD"}}))
+	assert.NoError(t, exchange.CreateFeedGap(ctx, entity.FeedGap{ProductID: "BTC-USD"}))
+	assert.Error(t, exchange.CreateCandles(ctx, []entity.Candle{{ProductID: "BTC-USD"}}))
+
+	// the writes reach the store
+	assert.Len(t, store.ticks, 1)
+	assert.Len(t, store.gaps, 1)
+
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.InsertFailures.WithLabelValues("candles")))
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.InsertDuration, "cex_collector_insert_duration_seconds"), writes+1)
+	assert.GreaterOrEqual(t, testutil.CollectAndCount(metrics.WriteAge), 2)
+}
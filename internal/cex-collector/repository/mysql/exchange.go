@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -25,7 +26,8 @@ func (e *exchangeRepo) CreateTick(ctx context.Context, message entity.Message) e
 	if message.Ticker != nil {
 		_, err := e.db.NamedExecContext(
 			ctxReq,
-			"INSERT INTO ticks (symbol, timestamp, bid, ask) VALUES (:symbol, :timestamp, :bid, :ask)",
+			`INSERT INTO ticks (symbol, timestamp, received_at, sequence, price, last_size, side, trade_id, bid, bid_size, ask, ask_size, open_24h, high_24h, low_24h, volume_24h)
+			 VALUES (:symbol, :timestamp, :received_at, :sequence, :price, :last_size, :side, :trade_id, :bid, :bid_size, :ask, :ask_size, :open_24h, :high_24h, :low_24h, :volume_24h)`,
 			message.Ticker,
 		)
 		return err
@@ -41,8 +43,8 @@ func (e *exchangeRepo) CreateOrder(ctx context.Context, message entity.Message)
 	if message.Order != nil {
 		_, err := e.db.NamedExecContext(
 			ctxReq,
-			`INSERT INTO orders (type, product_id, timestamp, order_id, funds, side, size, price, order_type, client_oid, sequence, remaining_size, reason, trade_id, maker_order_id, taker_order_id) 
-			 VALUES (:type, :product_id, :timestamp, :order_id, :funds, :side, :size, :price, :order_type, :client_oid, IFNULL(:sequence, 0), :remaining_size, :reason, :trade_id, :maker_order_id, :taker_order_id)`,
+			`INSERT INTO orders (type, product_id, timestamp, order_id, funds, side, size, price, order_type, client_oid, sequence, remaining_size, reason, trade_id, maker_order_id, taker_order_id, usd_value) 
+			 VALUES (:type, :product_id, :timestamp, :order_id, :funds, :side, :size, :price, :order_type, :client_oid, IFNULL(:sequence, 0), :remaining_size, :reason, :trade_id, :maker_order_id, :taker_order_id, :usd_value)`,
 			message.Order)
 		if err != nil {
 			fmt.Println("Error inserting order", "error", err)
@@ -54,3 +56,147 @@ func (e *exchangeRepo) CreateOrder(ctx context.Context, message entity.Message)
 
 	return fmt.Errorf("message should be order")
 }
+
+func (e *exchangeRepo) CreateBookSnapshot(ctx context.Context, snapshot entity.BookSnapshot) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	_, err := e.db.NamedExecContext(
+		ctxReq,
+		`INSERT INTO book_snapshots (timestamp, product_id, best_bid, best_ask, mid, spread, depth_bps, bid_depth, ask_depth, bids, asks)
+		 VALUES (:timestamp, :product_id, :best_bid, :best_ask, :mid, :spread, :depth_bps, :bid_depth, :ask_depth, :bids, :asks)`,
+		snapshot,
+	)
+
+	return err
+}
+
+func (e *exchangeRepo) CreateFeedGap(ctx context.Context, gap entity.FeedGap) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	_, err := e.db.NamedExecContext(
+		ctxReq,
+		`INSERT INTO feed_gaps (product_id, from_sequence, to_sequence, missing, start_time, end_time, source, detected_at)
+		 VALUES (:product_id, :from_sequence, :to_sequence, :missing, :start_time, :end_time, :source, :detected_at)`,
+		gap,
+	)
+
+	return err
+}
+
+// candleColumns are the columns of candles, a candle already stored for the bar
+// is merged with the written one by candleMerge
+var (
+	candleColumns = []string{
+		"product_id", "granularity", "timestamp", "open", "high", "low", "close", "volume", "quote_volume",
+		"vwap", "trades", "open_time", "open_trade_id", "close_time", "close_trade_id",
+	}
+	// open and close are assigned before the times they are compared with
+	candleMerge = ` ON DUPLICATE KEY UPDATE
+		open = IF((VALUES(open_time), VALUES(open_trade_id)) < (open_time, open_trade_id), VALUES(open), open),
+		open_trade_id = IF((VALUES(open_time), VALUES(open_trade_id)) < (open_time, open_trade_id), VALUES(open_trade_id), open_trade_id),
+		open_time = LEAST(open_time, VALUES(open_time)),
+		close = IF((VALUES(close_time), VALUES(close_trade_id)) >= (close_time, close_trade_id), VALUES(close), close),
+		close_trade_id = IF((VALUES(close_time), VALUES(close_trade_id)) >= (close_time, close_trade_id), VALUES(close_trade_id), close_trade_id),
+		close_time = GREATEST(close_time, VALUES(close_time)),
+		high = GREATEST(high, VALUES(high)),
+		low = LEAST(low, VALUES(low)),
+		volume = volume + VALUES(volume),
+		quote_volume = quote_volume + VALUES(quote_volume),
+		vwap = IF(volume > 0, quote_volume / volume, vwap),
+		trades = trades + VALUES(trades)`
+)
+
+// CreateCandles merges in storage candles with a single multi-row upsert,
+// a candle of a bar already stored adds its trades to the stored bar
+func (e *exchangeRepo) CreateCandles(ctx context.Context, candles []entity.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	ctxReq, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	args := make([]interface{}, 0, len(candles)*len(candleColumns))
+	for _, c := range candles {
+		args = append(args,
+			c.ProductID, c.Granularity, c.Timestamp, c.Open, c.High, c.Low, c.Close, c.Volume, c.QuoteVolume,
+			c.VWAP, c.Trades, c.OpenTime, c.OpenTradeID, c.CloseTime, c.CloseTradeID,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctxReq, insertQuery("candles", candleColumns, len(candles))+candleMerge, args...)
+
+	return err
+}
+
+// tickColumns and orderColumns are the columns written by the multi-row inserts
+var (
+	tickColumns  = []string{"symbol", "timestamp", "received_at", "sequence", "price", "last_size", "side", "trade_id", "bid", "bid_size", "ask", "ask_size", "open_24h", "high_24h", "low_24h", "volume_24h"}
+	orderColumns = []string{"type", "product_id", "timestamp", "order_id", "funds", "side", "size", "price", "order_type", "client_oid", "sequence", "remaining_size", "reason", "trade_id", "maker_order_id", "taker_order_id", "usd_value"}
+)
+
+// CreateTicks write in storage ticker data with a single multi-row insert
+func (e *exchangeRepo) CreateTicks(ctx context.Context, ticks []entity.Ticker) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(ticks)*len(tickColumns))
+	for _, t := range ticks {
+		args = append(args,
+			t.Symbol, t.Timestamp, t.ReceivedAt, t.Sequence, t.Price, t.LastSize, t.Side, t.TradeID,
+			t.Bid, t.BidSize, t.Ask, t.AskSize, t.Open24h, t.High24h, t.Low24h, t.Volume24h,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctx, bulkInsertQuery("ticks", tickColumns, len(ticks)), args...)
+
+	return err
+}
+
+// CreateOrders write in storage order data with a single multi-row insert
+func (e *exchangeRepo) CreateOrders(ctx context.Context, orders []entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(orders)*len(orderColumns))
+	for _, o := range orders {
+		args = append(args,
+			o.Type, o.ProductID, o.Timestamp, o.OrderID, o.Funds, o.Side, o.Size, o.Price, o.OrderType,
+			o.ClientOID, o.Sequence, o.RemainingSize, o.Reason, o.TradeID, o.MakerOrderID, o.TakerOrderID, o.USDValue,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctx, bulkInsertQuery("orders", orderColumns, len(orders)), args...)
+
+	return err
+}
+
+// bulkInsertQuery builds a multi-row insert of rows, rows already stored are kept
+// so a batch retried after a timeout does not fail on its own rows
+func bulkInsertQuery(table string, columns []string, rows int) string {
+	return insertQuery(table, columns, rows) + " ON DUPLICATE KEY UPDATE timestamp = timestamp"
+}
+
+// insertQuery builds a multi-row insert of rows
+func insertQuery(table string, columns []string, rows int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(table)
+	b.WriteString(" (")
+	b.WriteString(strings.Join(columns, ", "))
+	b.WriteString(") VALUES ")
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(row)
+	}
+
+	return b.String()
+}
@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jmoiron/sqlx"
@@ -56,3 +57,34 @@ func Test_exchangeRepo_CreateTick(t *testing.T) {
 		})
 	}
 }
+
+func Test_bulkInsertQuery(t *testing.T) {
+	got := bulkInsertQuery("ticks", []string{"symbol", "timestamp"}, 3)
+	want := "INSERT INTO ticks (symbol, timestamp) VALUES (?, ?), (?, ?), (?, ?) ON DUPLICATE KEY UPDATE timestamp = timestamp"
+
+	if got != want {
+		t.Errorf("bulkInsertQuery() = %v, want %v", got, want)
+	}
+}
+
+func Test_candleQuery(t *testing.T) {
+	got := insertQuery("candles", candleColumns, 2) + candleMerge
+
+	// open and close must be compared before their times are updated
+	open := strings.Index(got, "open = IF(")
+	openTime := strings.Index(got, "open_time = LEAST(")
+	closePrice := strings.Index(got, "close = IF(")
+	closeTime := strings.Index(got, "close_time = GREATEST(")
+	vwap := strings.Index(got, "vwap = ")
+	volume := strings.Index(got, "volume = volume + ")
+
+	if open < 0 || openTime < open || closePrice < 0 || closeTime < closePrice {
+		t.Errorf("open/close merged after their times: %s", got)
+	}
+	if vwap < volume {
+		t.Errorf("vwap merged before volume: %s", got)
+	}
+	if n := strings.Count(got, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"); n != 2 {
+		t.Errorf("insertQuery() rows = %d, want 2", n)
+	}
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+type exchangeRepo struct {
+	db          *sqlx.DB
+	candleMerge string
+}
+
+// NewExchangeRepository created exchange repository of Postgres, SQLite shares its
+// upserts and only names LEAST and GREATEST MIN and MAX
+func NewExchangeRepository(db *sqlx.DB) *exchangeRepo {
+	if db.DriverName() == "sqlite3" {
+		return &exchangeRepo{db, candleMerge("MIN", "MAX")}
+	}
+	return &exchangeRepo{db, candleMerge("LEAST", "GREATEST")}
+}
+
+func (e *exchangeRepo) CreateTick(ctx context.Context, message entity.Message) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	if message.Ticker != nil {
+		_, err := e.db.NamedExecContext(
+			ctxReq,
+			`INSERT INTO ticks (symbol, timestamp, received_at, sequence, price, last_size, side, trade_id, bid, bid_size, ask, ask_size, open_24h, high_24h, low_24h, volume_24h)
+			 VALUES (:symbol, :timestamp, :received_at, :sequence, :price, :last_size, :side, :trade_id, :bid, :bid_size, :ask, :ask_size, :open_24h, :high_24h, :low_24h, :volume_24h)`,
+			message.Ticker,
+		)
+		return err
+	}
+
+	return fmt.Errorf("message should be ticker")
+}
+
+func (e *exchangeRepo) CreateOrder(ctx context.Context, message entity.Message) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	if message.Order != nil {
+		_, err := e.db.NamedExecContext(
+			ctxReq,
+			`INSERT INTO orders (type, product_id, timestamp, order_id, funds, side, size, price, order_type, client_oid, sequence, remaining_size, reason, trade_id, maker_order_id, taker_order_id, usd_value)
+			 VALUES (:type, :product_id, :timestamp, :order_id, :funds, :side, :size, :price, :order_type, :client_oid, :sequence, :remaining_size, :reason, :trade_id, :maker_order_id, :taker_order_id, :usd_value)`,
+			message.Order)
+		return err
+	}
+
+	return fmt.Errorf("message should be order")
+}
+
+func (e *exchangeRepo) CreateBookSnapshot(ctx context.Context, snapshot entity.BookSnapshot) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	_, err := e.db.NamedExecContext(
+		ctxReq,
+		`INSERT INTO book_snapshots (timestamp, product_id, best_bid, best_ask, mid, spread, depth_bps, bid_depth, ask_depth, bids, asks)
+		 VALUES (:timestamp, :product_id, :best_bid, :best_ask, :mid, :spread, :depth_bps, :bid_depth, :ask_depth, :bids, :asks)`,
+		snapshot,
+	)
+
+	return err
+}
+
+func (e *exchangeRepo) CreateFeedGap(ctx context.Context, gap entity.FeedGap) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	_, err := e.db.NamedExecContext(
+		ctxReq,
+		`INSERT INTO feed_gaps (product_id, from_sequence, to_sequence, missing, start_time, end_time, source, detected_at)
+		 VALUES (:product_id, :from_sequence, :to_sequence, :missing, :start_time, :end_time, :source, :detected_at)`,
+		gap,
+	)
+
+	return err
+}
+
+// candleColumns are the columns of candles, a candle already stored for the bar
+// is merged with the written one by candleMerge
+var candleColumns = []string{
+	"product_id", "granularity", "timestamp", "open", "high", "low", "close", "volume", "quote_volume",
+	"vwap", "trades", "open_time", "open_trade_id", "close_time", "close_trade_id",
+}
+
+// candleMerge is the upsert of candles with the least and greatest functions of the database,
+// every expression reads the stored row, so vwap is computed from the merged sums
+func candleMerge(least, greatest string) string {
+	return ` ON CONFLICT (product_id, granularity, timestamp) DO UPDATE SET
+		open = CASE WHEN (EXCLUDED.open_time, EXCLUDED.open_trade_id) < (candles.open_time, candles.open_trade_id) THEN EXCLUDED.open ELSE candles.open END,
+		open_trade_id = CASE WHEN (EXCLUDED.open_time, EXCLUDED.open_trade_id) < (candles.open_time, candles.open_trade_id) THEN EXCLUDED.open_trade_id ELSE candles.open_trade_id END,
+		open_time = ` + least + `(candles.open_time, EXCLUDED.open_time),
+		close = CASE WHEN (EXCLUDED.close_time, EXCLUDED.close_trade_id) >= (candles.close_time, candles.close_trade_id) THEN EXCLUDED.close ELSE candles.close END,
+		close_trade_id = CASE WHEN (EXCLUDED.close_time, EXCLUDED.close_trade_id) >= (candles.close_time, candles.close_trade_id) THEN EXCLUDED.close_trade_id ELSE candles.close_trade_id END,
+		close_time = ` + greatest + `(candles.close_time, EXCLUDED.close_time),
+		high = ` + greatest + `(candles.high, EXCLUDED.high),
+		low = ` + least + `(candles.low, EXCLUDED.low),
+		volume = candles.volume + EXCLUDED.volume,
+		quote_volume = candles.quote_volume + EXCLUDED.quote_volume,
+		vwap = COALESCE((candles.quote_volume + EXCLUDED.quote_volume) / NULLIF(candles.volume + EXCLUDED.volume, 0), candles.vwap),
+		trades = candles.trades + EXCLUDED.trades`
+}
+
+// CreateCandles merges in storage candles with a single multi-row upsert,
+// a candle of a bar already stored adds its trades to the stored bar
+func (e *exchangeRepo) CreateCandles(ctx context.Context, candles []entity.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	ctxReq, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	args := make([]interface{}, 0, len(candles)*len(candleColumns))
+	for _, c := range candles {
+		args = append(args,
+			c.ProductID, c.Granularity, c.Timestamp, c.Open, c.High, c.Low, c.Close, c.Volume, c.QuoteVolume,
+			c.VWAP, c.Trades, c.OpenTime, c.OpenTradeID, c.CloseTime, c.CloseTradeID,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctxReq, e.db.Rebind(insertQuery("candles", candleColumns, len(candles))+e.candleMerge), args...)
+
+	return err
+}
+
+// tickColumns and orderColumns are the columns written by the multi-row inserts
+var (
+	tickColumns  = []string{"symbol", "timestamp", "received_at", "sequence", "price", "last_size", "side", "trade_id", "bid", "bid_size", "ask", "ask_size", "open_24h", "high_24h", "low_24h", "volume_24h"}
+	orderColumns = []string{"type", "product_id", "timestamp", "order_id", "funds", "side", "size", "price", "order_type", "client_oid", "sequence", "remaining_size", "reason", "trade_id", "maker_order_id", "taker_order_id", "usd_value"}
+)
+
+// CreateTicks write in storage ticker data with a single multi-row insert
+func (e *exchangeRepo) CreateTicks(ctx context.Context, ticks []entity.Ticker) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(ticks)*len(tickColumns))
+	for _, t := range ticks {
+		args = append(args,
+			t.Symbol, t.Timestamp, t.ReceivedAt, t.Sequence, t.Price, t.LastSize, t.Side, t.TradeID,
+			t.Bid, t.BidSize, t.Ask, t.AskSize, t.Open24h, t.High24h, t.Low24h, t.Volume24h,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctx, e.db.Rebind(bulkInsertQuery("ticks", tickColumns, len(ticks))), args...)
+
+	return err
+}
+
+// CreateOrders write in storage order data with a single multi-row insert
+func (e *exchangeRepo) CreateOrders(ctx context.Context, orders []entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(orders)*len(orderColumns))
+	for _, o := range orders {
+		args = append(args,
+			o.Type, o.ProductID, o.Timestamp, o.OrderID, o.Funds, o.Side, o.Size, o.Price, o.OrderType,
+			o.ClientOID, o.Sequence, o.RemainingSize, o.Reason, o.TradeID, o.MakerOrderID, o.TakerOrderID, o.USDValue,
+		)
+	}
+
+	_, err := e.db.ExecContext(ctx, e.db.Rebind(bulkInsertQuery("orders", orderColumns, len(orders))), args...)
+
+	return err
+}
+
+// bulkInsertQuery builds a multi-row insert of rows, rows already stored are kept
+// so a batch retried after a timeout does not fail on its own rows
+func bulkInsertQuery(table string, columns []string, rows int) string {
+	return insertQuery(table, columns, rows) + " ON CONFLICT DO NOTHING"
+}
+
+// insertQuery builds a multi-row insert of rows, its placeholders are bound to the
+// database with Rebind
+func insertQuery(table string, columns []string, rows int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(table)
+	b.WriteString(" (")
+	b.WriteString(strings.Join(columns, ", "))
+	b.WriteString(") VALUES ")
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(row)
+	}
+
+	return b.String()
+}
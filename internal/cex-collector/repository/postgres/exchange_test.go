@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/migrations"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func Test_bulkInsertQuery(t *testing.T) {
+	got := sqlx.Rebind(sqlx.DOLLAR, bulkInsertQuery("ticks", []string{"symbol", "timestamp"}, 3))
+
+	assert.Equal(t, "INSERT INTO ticks (symbol, timestamp) VALUES ($1, $2), ($3, $4), ($5, $6) ON CONFLICT DO NOTHING", got)
+}
+
+func Test_candleQuery(t *testing.T) {
+	got := sqlx.Rebind(sqlx.DOLLAR, insertQuery("candles", candleColumns, 2)+candleMerge("LEAST", "GREATEST"))
+
+	assert.True(t, strings.HasSuffix(sqlx.Rebind(sqlx.DOLLAR, insertQuery("candles", candleColumns, 2)), "$30)"))
+	assert.Contains(t, got, "ON CONFLICT (product_id, granularity, timestamp) DO UPDATE SET")
+	// the stored row is read before the update, vwap must divide the merged sums
+	assert.Contains(t, got, "(candles.quote_volume + EXCLUDED.quote_volume) / NULLIF(candles.volume + EXCLUDED.volume, 0)")
+}
+
+// the repository runs on sqlite in memory, the upserts are those of postgres
+func openDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// every connection of an in memory database is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = migrations.Up(context.Background(), db, migrations.SQLite)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestExchangeRepo_bulk(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := NewExchangeRepository(db)
+
+	ticks := []entity.Ticker{
+		{Timestamp: 1, Symbol: "BTC-USD", Bid: decimal.NewFromInt(64000), Ask: decimal.NewFromInt(64001)},
+		{Timestamp: 2, Symbol: "BTC-USD", Bid: decimal.NewFromInt(64002), Ask: decimal.NewFromInt(64003)},
+	}
+	require.NoError(t, repo.CreateTicks(ctx, ticks))
+	// a retried batch keeps the rows already stored
+	require.NoError(t, repo.CreateTicks(ctx, ticks))
+
+	orders := []entity.Order{{Timestamp: 1, ProductID: "BTC-USD", Type: "match", Sequence: 1, Size: decimal.RequireFromString("2.5")}}
+	require.NoError(t, repo.CreateOrders(ctx, orders))
+	require.NoError(t, repo.CreateOrder(ctx, entity.Message{Order: &entity.Order{Timestamp: 2, ProductID: "BTC-USD", Type: "open", Sequence: 2}}))
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM ticks"))
+	assert.Equal(t, 2, count)
+	// the tickers have no trade
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM ticks WHERE price IS NULL AND last_size IS NULL"))
+	assert.Equal(t, 2, count)
+	var size decimal.Decimal
+	require.NoError(t, db.Get(&size, "SELECT size FROM orders WHERE type = 'match'"))
+	assert.Equal(t, "2.5", size.String())
+}
+
+func TestExchangeRepo_CreateOrders_unsequenced(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := NewExchangeRepository(db)
+
+	// trades without a sequence in the same millisecond only differ by their trade id
+	orders := []entity.Order{
+		{Timestamp: 1, ProductID: "BTCUSDT", Type: "match", TradeID: 10},
+		{Timestamp: 1, ProductID: "BTCUSDT", Type: "match", TradeID: 11},
+	}
+	require.NoError(t, repo.CreateOrders(ctx, orders))
+	require.NoError(t, repo.CreateOrders(ctx, orders))
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM orders"))
+	assert.Equal(t, 2, count)
+}
+
+func TestExchangeRepo_CreateFeedGap(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := NewExchangeRepository(db)
+
+	gap := entity.FeedGap{ProductID: "BTC-USD", FromSequence: 10, ToSequence: 12, Missing: 3, Source: "match", DetectedAt: 1}
+	require.NoError(t, repo.CreateFeedGap(ctx, gap))
+	// the same sequences missed again after a reset
+	gap.DetectedAt = 2
+	require.NoError(t, repo.CreateFeedGap(ctx, gap))
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM feed_gaps"))
+	assert.Equal(t, 2, count)
+}
+
+func TestExchangeRepo_CreateCandles(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	repo := NewExchangeRepository(db)
+
+	bar := entity.Candle{
+		ProductID: "BTC-USD", Granularity: 60, Timestamp: 0,
+		Open: decimal.NewFromInt(100), High: decimal.NewFromInt(110), Low: decimal.NewFromInt(95), Close: decimal.NewFromInt(105),
+		Volume: decimal.NewFromInt(2), QuoteVolume: decimal.NewFromInt(200), VWAP: decimal.NewFromInt(100), Trades: 2,
+		OpenTime: 10, OpenTradeID: 1, CloseTime: 20, CloseTradeID: 2,
+	}
+	require.NoError(t, repo.CreateCandles(ctx, []entity.Candle{bar}))
+
+	// the rest of the trades of the bar, one of them earlier
+	rest := bar
+	rest.Open, rest.High, rest.Low, rest.Close = decimal.NewFromInt(98), decimal.NewFromInt(120), decimal.NewFromInt(97), decimal.NewFromInt(115)
+	rest.Volume, rest.QuoteVolume, rest.Trades = decimal.NewFromInt(2), decimal.NewFromInt(240), 2
+	rest.OpenTime, rest.OpenTradeID, rest.CloseTime, rest.CloseTradeID = 5, 0, 30, 3
+	require.NoError(t, repo.CreateCandles(ctx, []entity.Candle{rest}))
+
+	var got entity.Candle
+	require.NoError(t, db.Get(&got, "SELECT * FROM candles"))
+	assert.Equal(t, "98", got.Open.String())
+	assert.Equal(t, "120", got.High.String())
+	assert.Equal(t, "95", got.Low.String())
+	assert.Equal(t, "115", got.Close.String())
+	assert.Equal(t, "110", got.VWAP.String())
+	assert.Equal(t, int64(4), got.Trades)
+	assert.Equal(t, int64(5), got.OpenTime)
+	assert.Equal(t, int64(30), got.CloseTime)
+}
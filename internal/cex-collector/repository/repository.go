@@ -4,8 +4,12 @@ import (
 	"context"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/batch"
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/logger"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository/mysql"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository/postgres"
 )
 
 // Exchange methods implementation
@@ -14,16 +18,48 @@ type Exchange interface {
 	CreateTick(ctx context.Context, message entity.Message) error
 	// CreateOrder write in storage order data
 	CreateOrder(ctx context.Context, message entity.Message) error
+	// CreateBookSnapshot write in storage level2 book snapshot
+	CreateBookSnapshot(ctx context.Context, snapshot entity.BookSnapshot) error
+	// CreateFeedGap write in storage a range of sequences missing from the feed
+	CreateFeedGap(ctx context.Context, gap entity.FeedGap) error
+	// CreateCandles merges in storage candles holding part of the trades of their bar
+	CreateCandles(ctx context.Context, candles []entity.Candle) error
 }
 
 // Repositories of based interface for repository layout
 type Repositories struct {
 	Exchange
+	// Writer flushes the batched ticks and orders of Exchange
+	Writer Writer
 }
 
-// NewRepositories init repository layout
-func NewRepositories(db *sqlx.DB) *Repositories {
+// NewRepositories init repository layout, ticks and orders are written in batches
+// and the batches that can't be written go to deadLetters, every write is metered.
+// Ticks and orders are also copied to sinks
+func NewRepositories(db *sqlx.DB, cfg config.BatchConfig, deadLetters DeadLetters, logger logger.Logger, sinks ...Sink) *Repositories {
+	exchange := NewBatchExchange(NewMeteredExchange(NewExchange(db)), batch.Options{
+		Size:       cfg.Size,
+		Interval:   cfg.Interval,
+		MaxPending: cfg.MaxPending,
+		Retries:    cfg.Retries,
+		RetryDelay: cfg.RetryDelay,
+		Timeout:    cfg.Timeout,
+	}, deadLetters, logger)
+
+	fanOut := NewFanOutExchange(exchange, exchange, logger, sinks...)
+
 	return &Repositories{
-		Exchange: mysql.NewExchangeRepository(db),
+		Exchange: fanOut,
+		Writer:   fanOut,
+	}
+}
+
+// NewExchange creates the exchange repository of the database driver
+func NewExchange(db *sqlx.DB) BulkExchange {
+	switch db.DriverName() {
+	case "pgx", "postgres", "sqlite3":
+		return postgres.NewExchangeRepository(db)
+	default:
+		return mysql.NewExchangeRepository(db)
 	}
 }
@@ -2,13 +2,22 @@ package repository
 
 import (
 	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/logger"
 	"reflect"
 	"testing"
+
+	"github.com/nel349/bz-findata/internal/cex-collector/repository/mysql"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository/postgres"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewRepositories(t *testing.T) {
 	type args struct {
-		db *sqlx.DB
+		db          *sqlx.DB
+		cfg         config.BatchConfig
+		deadLetters DeadLetters
+		logger      logger.Logger
 	}
 	tests := []struct {
 		name string
@@ -19,9 +28,16 @@ func TestNewRepositories(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewRepositories(tt.args.db); !reflect.DeepEqual(got, tt.want) {
+			if got := NewRepositories(tt.args.db, tt.args.cfg, tt.args.deadLetters, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewRepositories() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestNewExchange(t *testing.T) {
+	pgx := sqlx.NewDb(nil, "pgx")
+	assert.IsType(t, postgres.NewExchangeRepository(pgx), NewExchange(pgx))
+	assert.IsType(t, mysql.NewExchangeRepository(nil), NewExchange(sqlx.NewDb(nil, "mysql")))
+	assert.IsType(t, postgres.NewExchangeRepository(pgx), NewExchange(sqlx.NewDb(nil, "sqlite3")))
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"golang.org/x/sync/errgroup"
+)
+
+// Sink gets a copy of every tick and order written, like the Parquet archive
+type Sink interface {
+	Writer
+	// WriteTicks copies ticks to the sink
+	WriteTicks(ticks []entity.Ticker) error
+	// WriteOrders copies orders to the sink
+	WriteOrders(orders []entity.Order) error
+}
+
+// Publisher publishes the parsed messages of the feed, like the message bus
+type Publisher interface {
+	// PublishMessage publishes a ticker, order or heartbeat, other messages are skipped
+	PublishMessage(message entity.Message) error
+}
+
+// fanOutExchange writes the ticks and orders to the storage and copies them to sinks,
+// a failing sink is logged and never fails the storage write
+type fanOutExchange struct {
+	Exchange
+	writer Writer
+	sinks  []Sink
+	logger logger.Logger
+}
+
+// NewFanOutExchange init exchange repository copying to sinks the ticks and orders
+// written to exchange, running it runs writer and the sinks
+func NewFanOutExchange(exchange Exchange, writer Writer, logger logger.Logger, sinks ...Sink) *fanOutExchange {
+	return &fanOutExchange{
+		Exchange: exchange,
+		writer:   writer,
+		sinks:    sinks,
+		logger:   logger,
+	}
+}
+
+func (f *fanOutExchange) CreateTick(ctx context.Context, message entity.Message) error {
+	err := f.Exchange.CreateTick(ctx, message)
+	if message.Ticker != nil {
+		for _, sink := range f.sinks {
+			if sinkErr := sink.WriteTicks([]entity.Ticker{*message.Ticker}); sinkErr != nil {
+				f.failed("ticks", sinkErr)
+			}
+		}
+	}
+
+	return err
+}
+
+func (f *fanOutExchange) CreateOrder(ctx context.Context, message entity.Message) error {
+	err := f.Exchange.CreateOrder(ctx, message)
+	if message.Order != nil {
+		for _, sink := range f.sinks {
+			if sinkErr := sink.WriteOrders([]entity.Order{*message.Order}); sinkErr != nil {
+				f.failed("orders", sinkErr)
+			}
+		}
+	}
+
+	return err
+}
+
+func (f *fanOutExchange) failed(table string, err error) {
+	metrics.SinkFailures.WithLabelValues(table).Inc()
+	f.logger.Error(fmt.Sprintf("failed to copy %s to sink: %v", table, err))
+}
+
+// Run runs the writer and the sinks until ctx is done
+func (f *fanOutExchange) Run(ctx context.Context) error {
+	var g errgroup.Group
+
+	g.Go(func() error { return f.writer.Run(ctx) })
+	for _, sink := range f.sinks {
+		sink := sink
+		g.Go(func() error { return sink.Run(ctx) })
+	}
+
+	return g.Wait()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sinkStore struct {
+	err    error
+	ticks  []entity.Ticker
+	orders []entity.Order
+	ran    bool
+}
+
+func (s *sinkStore) Run(ctx context.Context) error {
+	<-ctx.Done()
+	s.ran = true
+	return nil
+}
+func (s *sinkStore) WriteTicks(ticks []entity.Ticker) error {
+	s.ticks = append(s.ticks, ticks...)
+	return s.err
+}
+func (s *sinkStore) WriteOrders(orders []entity.Order) error {
+	s.orders = append(s.orders, orders...)
+	return s.err
+}
+
+type runWriter struct{ ran bool }
+
+func (w *runWriter) Run(ctx context.Context) error {
+	<-ctx.Done()
+	w.ran = true
+	return nil
+}
+
+func TestFanOutExchange(t *testing.T) {
+	store := &bulkStore{}
+	archive := &sinkStore{}
+	broken := &sinkStore{err: errors.New("disk full")}
+	writer := &runWriter{}
+	f := NewFanOutExchange(store, writer, nopLogger{}, archive, broken)
+
+	ctx := context.Background()
+	require.NoError(t, f.CreateTick(ctx, entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD"}}))
+	// a failing sink never fails the storage write
+	require.NoError(t, f.CreateOrder(ctx, entity.Message{Order: &entity.Order{ProductID: "ETH-USD"}}))
+
+	assert.Equal(t, []entity.Ticker{{Symbol: "BTC-USD"}}, archive.ticks)
+	assert.Equal(t, []entity.Order{{ProductID: "ETH-USD"}}, archive.orders)
+	assert.Len(t, broken.orders, 1)
+
+	runCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	require.NoError(t, f.Run(runCtx))
+	assert.True(t, writer.ran)
+	assert.True(t, archive.ran)
+	assert.True(t, broken.ran)
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/logger"
+)
+
+// Engine holds the active rule set and swaps it on reload
+type Engine struct {
+	path    string
+	set     atomic.Pointer[RuleSet]
+	modTime time.Time
+	logger  logger.Logger
+}
+
+// NewEngine init rule engine from a rules file, an empty path keeps the default rule set
+func NewEngine(path string, logger logger.Logger) (*Engine, error) {
+	e := &Engine{path: path, logger: logger}
+	e.set.Store(DefaultRuleSet())
+
+	if path == "" {
+		return e, nil
+	}
+
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// RuleSet returns the active rule set
+func (e *Engine) RuleSet() *RuleSet {
+	return e.set.Load()
+}
+
+// Store replaces the active rule set
+func (e *Engine) Store(set *RuleSet) error {
+	if err := set.Validate(); err != nil {
+		return err
+	}
+	e.set.Store(set)
+
+	return nil
+}
+
+// Match reports whether the order passes the active rule set
+func (e *Engine) Match(order *entity.Order) bool {
+	return e.set.Load().Match(order)
+}
+
+// Reload reads the rules file again when it was changed since the last load
+func (e *Engine) Reload() (bool, error) {
+	if e.path == "" {
+		return false, nil
+	}
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat rules file: %w", err)
+	}
+	if !info.ModTime().After(e.modTime) {
+		return false, nil
+	}
+
+	set, err := LoadFile(e.path)
+	if err != nil {
+		return false, err
+	}
+
+	e.set.Store(set)
+	e.modTime = info.ModTime()
+
+	return true, nil
+}
+
+// Watch reloads the rules file on every interval tick until ctx is done,
+// a broken file is logged and the previous rule set stays active
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	if e.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := e.Reload()
+			if err != nil {
+				e.logger.Error(fmt.Sprintf("failed to reload order rules: %v", err))
+				continue
+			}
+			if reloaded {
+				e.logger.Info(fmt.Sprintf("reloaded %d order rules from %s", len(e.RuleSet().Rules), e.path))
+			}
+		}
+	}
+}
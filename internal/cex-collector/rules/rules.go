@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nel349/bz-findata/pkg/currency"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+// AnyProduct matches every product id
+const AnyProduct = "*"
+
+// Rule describes which orders of a product should be persisted
+type Rule struct {
+	// Product is the exchange product id (e.g. BTC-USD) or AnyProduct
+	Product string `json:"product"`
+	// Types of order messages accepted (match, open, done, received, change), empty accepts all
+	Types []string `json:"types"`
+	// Side of the order (buy or sell), empty accepts both
+	Side string `json:"side,omitempty"`
+	// MinNotional is the exclusive lower bound of the order USD value
+	MinNotional decimal.Decimal `json:"min_notional"`
+	// MaxNotional is the inclusive upper bound of the order USD value, zero means unbounded
+	MaxNotional decimal.Decimal `json:"max_notional,omitempty"`
+	// QuoteRate converts the notional from the quote currency of the product into
+	// USD (e.g. BTC->USD for ETH-BTC) instead of the rate seen on the feed, zero uses the feed
+	QuoteRate decimal.Decimal `json:"quote_rate,omitempty"`
+}
+
+// RuleSet is a group of rules, an order is accepted when any rule matches
+type RuleSet struct {
+	Rules []Rule `json:"rules"`
+}
+
+// DefaultRuleSet keeps the thresholds the collector shipped with
+func DefaultRuleSet() *RuleSet {
+	return &RuleSet{
+		Rules: []Rule{
+			{Product: "ETH-USD", Types: []string{"match"}, MinNotional: decimal.NewFromInt(20000)}, // 20k
+			{Product: "BTC-USD", Types: []string{"match"}, MinNotional: decimal.NewFromInt(20000)}, // 20k
+		},
+	}
+}
+
+// LoadFile reads and validates a rule set from a JSON file
+func LoadFile(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes and validates a rule set from JSON
+func Parse(data []byte) (*RuleSet, error) {
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// Validate checks that every rule of the set is usable
+func (s *RuleSet) Validate() error {
+	for i, r := range s.Rules {
+		if r.Product == "" {
+			return fmt.Errorf("rule %d: product is required", i)
+		}
+		if r.MinNotional.IsNegative() || r.MaxNotional.IsNegative() || r.QuoteRate.IsNegative() {
+			return fmt.Errorf("rule %d: notional bounds and quote rate must not be negative", i)
+		}
+		if r.MaxNotional.IsPositive() && r.MaxNotional.LessThan(r.MinNotional) {
+			return fmt.Errorf("rule %d: max_notional is lower than min_notional", i)
+		}
+		if side := strings.ToLower(r.Side); side != "" && side != "buy" && side != "sell" {
+			return fmt.Errorf("rule %d: unknown side %q", i, r.Side)
+		}
+	}
+
+	return nil
+}
+
+// Match reports whether the order passes any rule of the set
+func (s *RuleSet) Match(order *entity.Order) bool {
+	for _, r := range s.Rules {
+		if r.Match(order) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match reports whether the order passes the rule
+func (r Rule) Match(order *entity.Order) bool {
+	if r.Product != AnyProduct && r.Product != order.ProductID {
+		return false
+	}
+
+	if len(r.Types) > 0 {
+		typeAllowed := false
+		for _, t := range r.Types {
+			if strings.EqualFold(t, order.Type) {
+				typeAllowed = true
+				break
+			}
+		}
+		if !typeAllowed {
+			return false
+		}
+	}
+
+	if r.Side != "" && !strings.EqualFold(r.Side, order.Side) {
+		return false
+	}
+
+	notional, ok := r.Notional(order)
+	if !ok || notional.LessThanOrEqual(r.MinNotional) {
+		return false
+	}
+
+	return r.MaxNotional.IsZero() || notional.LessThanOrEqual(r.MaxNotional)
+}
+
+// Notional returns the order value in USD, converted with the rule quote rate when set.
+// It is unknown, false, for an order without a USD value whose product is not quoted in USD
+func (r Rule) Notional(order *entity.Order) (decimal.Decimal, bool) {
+	if r.QuoteRate.IsPositive() {
+		return currency.Notional(order).Mul(r.QuoteRate), true
+	}
+	if order.USDValue.Valid {
+		return order.USDValue.Decimal, true
+	}
+	if _, quote, ok := currency.Split(order.ProductID); ok && quote == currency.USD {
+		return currency.Notional(order), true
+	}
+
+	return decimal.Zero, false
+}
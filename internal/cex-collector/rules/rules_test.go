@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
+func TestRuleSet_Match(t *testing.T) {
+	set := &RuleSet{
+		Rules: []Rule{
+			{Product: "BTC-USD", Types: []string{"match"}, MinNotional: dec(20000)},
+			{Product: "ETH-BTC", Types: []string{"match"}, MinNotional: dec(20000), QuoteRate: dec(60000)},
+			{Product: "SOL-USD", Types: []string{"received", "open"}, Side: "buy", MinNotional: dec(1000), MaxNotional: dec(5000)},
+			{Product: AnyProduct, Types: []string{"done"}, MinNotional: dec(1000000)},
+			{Product: "BTC-EUR", Types: []string{"match"}, MinNotional: dec(20000)},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		order entity.Order
+		want  bool
+	}{
+		{name: "match above threshold", order: entity.Order{ProductID: "BTC-USD", Type: "match", Size: dec(1), Price: dec(30000)}, want: true},
+		{name: "type is case insensitive", order: entity.Order{ProductID: "BTC-USD", Type: "MATCH", Size: dec(1), Price: dec(30000)}, want: true},
+		{name: "threshold is exclusive", order: entity.Order{ProductID: "BTC-USD", Type: "match", Size: dec(1), Price: dec(20000)}, want: false},
+		{name: "type not allowed", order: entity.Order{ProductID: "BTC-USD", Type: "open", Size: dec(1), Price: dec(30000)}, want: false},
+		{name: "unknown product", order: entity.Order{ProductID: "DOGE-USD", Type: "match", Size: dec(1000000), Price: dec(1)}, want: false},
+		{name: "quote rate converts notional", order: entity.Order{ProductID: "ETH-BTC", Type: "match", Size: dec(10), Price: dec(0.05)}, want: true},
+		{name: "quote rate below threshold", order: entity.Order{ProductID: "ETH-BTC", Type: "match", Size: dec(1), Price: dec(0.05)}, want: false},
+		{name: "side and max notional", order: entity.Order{ProductID: "SOL-USD", Type: "open", Side: "buy", Size: dec(20), Price: dec(150)}, want: true},
+		{name: "wrong side", order: entity.Order{ProductID: "SOL-USD", Type: "open", Side: "sell", Size: dec(20), Price: dec(150)}, want: false},
+		{name: "above max notional", order: entity.Order{ProductID: "SOL-USD", Type: "open", Side: "buy", Size: dec(100), Price: dec(150)}, want: false},
+		{name: "usd value of the order", order: entity.Order{ProductID: "BTC-EUR", Type: "match", Size: dec(1), Price: dec(19000), USDValue: decimal.NewNullDecimal(dec(20520))}, want: true},
+		{name: "unknown usd value is skipped", order: entity.Order{ProductID: "BTC-EUR", Type: "match", Size: dec(1), Price: dec(30000)}, want: false},
+		{name: "wildcard product", order: entity.Order{ProductID: "DOGE-USD", Type: "done", Size: dec(10000000), Price: dec(1)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, set.Match(&tt.order))
+		})
+	}
+}
+
+func TestRule_Notional(t *testing.T) {
+	rule := Rule{Product: AnyProduct}
+
+	notional, ok := rule.Notional(&entity.Order{ProductID: "BTC-USD", Size: dec(2), Price: dec(100)})
+	assert.True(t, ok)
+	assert.True(t, notional.Equal(dec(200)))
+
+	_, ok = rule.Notional(&entity.Order{ProductID: "BTC-EUR", Size: dec(2), Price: dec(100)})
+	assert.False(t, ok)
+
+	rule.QuoteRate = dec(1.1)
+	notional, ok = rule.Notional(&entity.Order{ProductID: "BTC-EUR", Size: dec(2), Price: dec(100)})
+	assert.True(t, ok)
+	assert.True(t, notional.Equal(dec(220)))
+}
+
+func TestDefaultRuleSet(t *testing.T) {
+	set := DefaultRuleSet()
+
+	assert.NoError(t, set.Validate())
+	assert.True(t, set.Match(&entity.Order{ProductID: "ETH-USD", Type: "match", Size: dec(10), Price: dec(2500)}))
+	assert.False(t, set.Match(&entity.Order{ProductID: "ETH-USD", Type: "open", Size: dec(10), Price: dec(2500)}))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"rules":[{"product":"BTC-USD","types":["match"],"min_notional":100}]}`, wantErr: false},
+		{name: "invalid json", data: `{"rules":`, wantErr: true},
+		{name: "missing product", data: `{"rules":[{"types":["match"]}]}`, wantErr: true},
+		{name: "max lower than min", data: `{"rules":[{"product":"BTC-USD","min_notional":100,"max_notional":10}]}`, wantErr: true},
+		{name: "unknown side", data: `{"rules":[{"product":"BTC-USD","side":"long"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules":[{"product":"BTC-USD","types":["match"],"min_notional":20000}]}`), 0o600))
+
+	engine, err := NewEngine(path, nil)
+	require.NoError(t, err)
+
+	order := &entity.Order{ProductID: "BTC-USD", Type: "match", Size: dec(0.5), Price: dec(30000)}
+	assert.False(t, engine.Match(order))
+
+	// unchanged file is not reloaded
+	reloaded, err := engine.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules":[{"product":"BTC-USD","types":["match"],"min_notional":10000}]}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err = engine.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.True(t, engine.Match(order))
+
+	// a broken file keeps the previous rule set
+	require.NoError(t, os.WriteFile(path, []byte(`{"rules":[{"types":["match"]}]}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+
+	_, err = engine.Reload()
+	assert.Error(t, err)
+	assert.True(t, engine.Match(order))
+}
+
+func TestNewEngine_Default(t *testing.T) {
+	engine, err := NewEngine("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRuleSet(), engine.RuleSet())
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+)
+
+type bookService struct {
+	exchange repository.Exchange
+	logger   logger.Logger
+	books    *orderbook.Books
+	cfg      config.BookConfig
+}
+
+// NewBookService created level2 book usecase
+func NewBookService(
+	exchange repository.Exchange,
+	logger logger.Logger,
+	books *orderbook.Books,
+	cfg config.BookConfig,
+) *bookService {
+	return &bookService{exchange, logger, books, cfg}
+}
+
+func (b *bookService) Books() *orderbook.Books {
+	return b.books
+}
+
+func (b *bookService) RunSnapshots(ctx context.Context) error {
+	if b.cfg.SnapshotInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(b.cfg.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t := <-ticker.C:
+			b.storeSnapshots(ctx, t)
+		}
+	}
+}
+
+func (b *bookService) storeSnapshots(ctx context.Context, at time.Time) {
+	for _, product := range b.books.Products() {
+		snapshot, ok := b.books.Get(product).Snapshot(at, b.cfg.Levels, b.cfg.DepthBps)
+		if !ok {
+			continue
+		}
+
+		if err := b.exchange.CreateBookSnapshot(ctx, snapshot); err != nil {
+			b.logger.Error(fmt.Sprintf("Failed to create book snapshot for %s: %v", product, err))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/database"
+	"github.com/nel349/bz-findata/pkg/logger"
+)
+
+// candleWriteTimeout bounds the write of the candles left on shutdown
+const candleWriteTimeout = 5 * time.Second
+
+type candleService struct {
+	exchange repository.Exchange
+	logger   logger.Logger
+	candles  *candle.Builder
+	cfg      config.CandleConfig
+}
+
+// NewCandleService created candle usecase
+func NewCandleService(
+	exchange repository.Exchange,
+	logger logger.Logger,
+	candles *candle.Builder,
+	cfg config.CandleConfig,
+) *candleService {
+	return &candleService{exchange, logger, candles, cfg}
+}
+
+func (c *candleService) Candles() *candle.Builder {
+	return c.candles
+}
+
+func (c *candleService) RunCandles(ctx context.Context) error {
+	if c.cfg.FlushInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the stream stopped, write what is left
+			writeCtx, cancel := context.WithTimeout(context.Background(), candleWriteTimeout)
+			defer cancel()
+			if err := c.storeCandles(writeCtx); err != nil {
+				return fmt.Errorf("failed to create candles on shutdown: %w", err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.storeCandles(ctx); err != nil {
+				c.logger.Error(fmt.Sprintf("Failed to create candles: %v", err))
+			}
+		}
+	}
+}
+
+// storeCandles writes the candles built since the last flush. The write adds them to the
+// stored bars, so they are only kept for the next flush when the database rejected it,
+// a write that may have been applied is not repeated
+func (c *candleService) storeCandles(ctx context.Context) error {
+	candles := c.candles.Flush()
+	if len(candles) == 0 {
+		return nil
+	}
+
+	if err := c.exchange.CreateCandles(ctx, candles); err != nil {
+		if !database.Rejected(err) {
+			return fmt.Errorf("dropped %d candles the failed write may have applied: %w", len(candles), err)
+		}
+		c.candles.Restore(candles)
+		return fmt.Errorf("kept %d candles for the next flush: %w", len(candles), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// candleStore keeps the candles written, failing with err while fail is set.
+// The database rejects the writes when err is nil
+type candleStore struct {
+	repository.Exchange
+	mu      sync.Mutex
+	fail    bool
+	err     error
+	candles []entity.Candle
+}
+
+func (s *candleStore) CreateCandles(_ context.Context, candles []entity.Candle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		if s.err != nil {
+			return s.err
+		}
+		return fmt.Errorf("db down: %w", driver.ErrBadConn)
+	}
+	s.candles = append(s.candles, candles...)
+	return nil
+}
+
+func (s *candleStore) written() []entity.Candle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]entity.Candle(nil), s.candles...)
+}
+
+func Test_exchangeService_ProcessStream_Candles(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
+	candles := candle.NewBuilder([]time.Duration{time.Minute})
+	e := NewExchangeService(failingExchange{}, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candles, currency.NewConverter(0), &deadLetters{}, nil)
+
+	ch := make(chan entity.Message, 4)
+	// below the default rules, summarized but not stored
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1, TradeID: 1, Size: dec(0.1), Price: dec(50000)}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 2, TradeID: 2, Size: dec(0.3), Price: dec(50100)}}
+	// duplicate delivery
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 2, TradeID: 2, Size: dec(0.3), Price: dec(50100)}}
+	ch <- entity.Message{Order: &entity.Order{Type: "open", ProductID: "BTC-USD", Sequence: 3, Size: dec(1), Price: dec(49000)}}
+	close(ch)
+
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	got := candles.Flush()
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(2), got[0].Trades)
+	assert.Equal(t, "0.4", got[0].Volume.String())
+	assert.Equal(t, "50000", got[0].Open.String())
+	assert.Equal(t, "50100", got[0].Close.String())
+}
+
+func Test_candleService_RunCandles(t *testing.T) {
+	store := &candleStore{fail: true}
+	candles := candle.NewBuilder([]time.Duration{time.Minute})
+	c := NewCandleService(store, nopLogger{}, candles, config.CandleConfig{FlushInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- c.RunCandles(ctx)
+	}()
+
+	candles.Add(&entity.Order{Type: "match", ProductID: "BTC-USD", TradeID: 1, Size: dec(1), Price: dec(100)})
+
+	// kept while the writes fail
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, store.written())
+	assert.Equal(t, 1, candles.Pending())
+
+	store.mu.Lock()
+	store.fail = false
+	store.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return len(store.written()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// written on shutdown
+	candles.Add(&entity.Order{Type: "match", ProductID: "BTC-USD", TradeID: 2, Size: dec(1), Price: dec(101)})
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	written := store.written()
+	require.Len(t, written, 2)
+	assert.Equal(t, int64(1), written[0].Trades)
+	assert.Equal(t, "101", written[1].Close.String())
+}
+
+func Test_candleService_storeCandles_uncertain(t *testing.T) {
+	// the write timed out, the database may have added the candles to its bars
+	store := &candleStore{fail: true, err: context.DeadlineExceeded}
+	candles := candle.NewBuilder([]time.Duration{time.Minute})
+	c := NewCandleService(store, nopLogger{}, candles, config.CandleConfig{})
+
+	candles.Add(&entity.Order{Type: "match", ProductID: "BTC-USD", TradeID: 1, Size: dec(1), Price: dec(100)})
+	assert.ErrorIs(t, c.storeCandles(context.Background()), context.DeadlineExceeded)
+	assert.Zero(t, candles.Pending())
+}
@@ -3,64 +3,94 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
 	"github.com/nel349/bz-findata/pkg/entity"
 	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
 )
 
 type exchangeService struct {
 	exchange repository.Exchange
 	logger   logger.Logger
+	rules    *rules.Engine
+	books    *orderbook.Books
+	l3       *orderbook.L3Engine
+	tracker  *sequence.Tracker
+	candles  *candle.Builder
+	rates    *currency.Converter
+	dead     repository.DeadLetters
+	bus      repository.Publisher
 }
 
 // NewExchangeService created exchange usecase
 func NewExchangeService(
 	exchange repository.Exchange,
 	logger logger.Logger,
+	orderRules *rules.Engine,
+	books *orderbook.Books,
+	l3 *orderbook.L3Engine,
+	tracker *sequence.Tracker,
+	candles *candle.Builder,
+	rates *currency.Converter,
+	deadLetters repository.DeadLetters,
+	publisher repository.Publisher,
 ) *exchangeService {
-	return &exchangeService{exchange, logger}
+	return &exchangeService{exchange, logger, orderRules, books, l3, tracker, candles, rates, deadLetters, publisher}
 }
 
-type ProductThreshold struct {
-	ProductID string
-	Threshold float64
+func (e *exchangeService) shouldProcessOrder(order *entity.Order) bool {
+	return e.rules.Match(order)
 }
 
-var allowedOrderTypes = []string{
-	"match",
-	// "open",
-	// "done",
-	// "received",
+func (e *exchangeService) storeFeedGap(ctx context.Context, gap *entity.FeedGap) {
+	e.logger.Error(fmt.Sprintf(
+		"Sequence gap detected: product_id:%s, missing:%d, from:%d, to:%d, source:%s",
+		gap.ProductID,
+		gap.Missing,
+		gap.FromSequence,
+		gap.ToSequence,
+		gap.Source,
+	))
+	metrics.FeedGaps.WithLabelValues(gap.ProductID).Inc()
+	metrics.FeedGapMissing.WithLabelValues(gap.ProductID).Add(float64(gap.Missing))
+	if err := e.exchange.CreateFeedGap(ctx, *gap); err != nil {
+		e.logger.Error(fmt.Sprintf("Failed to create feed gap: %v", err))
+	}
 }
 
-var thresholds = []ProductThreshold{
-	{ProductID: "ETH-USD", Threshold: 20000}, // 20k
-	{ProductID: "BTC-USD", Threshold: 20000}, // 20k
+func usdValue(order *entity.Order) string {
+	if !order.USDValue.Valid {
+		return "unknown"
+	}
+	return order.USDValue.Decimal.String()
 }
 
-func (e *exchangeService) shouldProcessOrder(order *entity.Order) bool {
-	// Check if order type is allowed
-	orderTypeAllowed := false
-	for _, allowedType := range allowedOrderTypes {
-		if strings.ToLower(order.Type) == allowedType {
-			orderTypeAllowed = true
-			break
-		}
+// deadLetter keeps a message that could not be stored for a later replay,
+// the message is lost without a dead letter queue
+func (e *exchangeService) deadLetter(msg entity.Message, cause error) {
+	if e.dead == nil {
+		return
 	}
-	if !orderTypeAllowed {
-		return false
+	if err := e.dead.Add(msg, cause); err != nil {
+		e.logger.Error(fmt.Sprintf("Lost message, failed to write dead letter: %v", err))
 	}
+}
 
-	orderValue := order.Size * order.Price
-
-	for _, t := range thresholds {
-		if order.ProductID == t.ProductID && orderValue > t.Threshold {
-			return true
-		}
+// publish publishes every parsed message, stored or not, when a publisher is set
+func (e *exchangeService) publish(msg entity.Message) {
+	if e.bus == nil {
+		return
+	}
+	if err := e.bus.PublishMessage(msg); err != nil {
+		metrics.PublishFailures.Inc()
+		e.logger.Error(fmt.Sprintf("Failed to publish message: %v", err))
 	}
-	return false
 }
 
 func (e *exchangeService) ProcessStream(ctx context.Context, ch <-chan entity.Message) error {
@@ -80,14 +110,17 @@ func (e *exchangeService) ProcessStream(ctx context.Context, ch <-chan entity.Me
 
 			switch {
 			case msg.Ticker != nil:
+				e.rates.Observe(msg.Ticker)
+				e.publish(msg)
 				if err := e.exchange.CreateTick(ctx, msg); err != nil {
-					//TODO [critical] block - what's need?
-					return err
+					e.logger.Error(fmt.Sprintf("Failed to create tick: %v", err))
+					e.deadLetter(msg, err)
+					continue
 				}
 
 				e.logger.Info(
 					fmt.Sprintf(
-						"Inserted ticker %s > time:%d, bid:%f, ask:%f",
+						"Inserted ticker %s > time:%d, bid:%s, ask:%s",
 						msg.Ticker.Symbol,
 						msg.Ticker.Timestamp,
 						msg.Ticker.Bid,
@@ -96,10 +129,33 @@ func (e *exchangeService) ProcessStream(ctx context.Context, ch <-chan entity.Me
 				)
 
 			case msg.Order != nil:
+				// only the feeds of Coinbase Exchange have one sequence per product,
+				// the orders of the other venues have none and are not tracked
+				sequenced := msg.Order.Sequence != 0
+				if sequenced {
+					status, gap := e.tracker.Observe(msg.Order.ProductID, int64(msg.Order.Sequence), msg.Order.Timestamp, msg.Order.Type)
+					if gap != nil {
+						e.storeFeedGap(ctx, gap)
+					} else if status == sequence.Duplicate {
+						// already processed
+						continue
+					}
+				}
+				// every match is summarized, not only the stored ones
+				e.candles.Add(msg.Order)
+				e.rates.ObserveOrder(msg.Order)
+				e.rates.Price(msg.Order)
+				e.publish(msg)
+				if e.l3 != nil && sequenced {
+					if err := e.l3.Apply(ctx, msg.Order); err != nil {
+						e.logger.Error(fmt.Sprintf("Failed to apply order to book: %v", err))
+					}
+				}
 				if e.shouldProcessOrder(msg.Order) {
 					e.logger.Info(fmt.Sprintf(
-						"Received order: total_value:%f, type:%s, product_id:%s, size:%f, price:%f",
-						msg.Order.Size*msg.Order.Price,
+						"Received order: notional:%s, usd_value:%s, type:%s, product_id:%s, size:%s, price:%s",
+						currency.Notional(msg.Order),
+						usdValue(msg.Order),
 						msg.Order.Type,
 						msg.Order.ProductID,
 						msg.Order.Size,
@@ -107,10 +163,22 @@ func (e *exchangeService) ProcessStream(ctx context.Context, ch <-chan entity.Me
 					))
 					if err := e.exchange.CreateOrder(ctx, msg); err != nil {
 						e.logger.Error(fmt.Sprintf("Failed to create order: %v", err))
+						e.deadLetter(msg, err)
 						continue
 					}
 				}
+			case msg.Book != nil:
+				e.books.Apply(msg.Book)
+			case msg.Candle != nil:
+				// the stored candles are built from the matches, the bars of the
+				// exchange are only published so no trade is counted twice
+				e.publish(msg)
+				e.logger.Debug(fmt.Sprintf("Received candle: %+v", msg.Candle))
 			case msg.Heartbeat != nil:
+				if gap := e.tracker.Checkpoint(msg.Heartbeat.ProductID, msg.Heartbeat.Sequence, msg.Heartbeat.Time.UnixNano(), msg.Heartbeat.Type); gap != nil {
+					e.storeFeedGap(ctx, gap)
+				}
+				e.publish(msg)
 				e.logger.Info(fmt.Sprintf("Received heartbeat in : %+v", msg.Heartbeat))
 			default:
 				e.logger.Info("Unknown message type")
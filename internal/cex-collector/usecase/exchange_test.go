@@ -2,18 +2,43 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/nel349/bz-findata/internal/cex-collector/metrics"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase/advanced"
 	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func dec(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
 func TestNewExchangeService(t *testing.T) {
 	type args struct {
-		exchange repository.Exchange
-		logger   logger.Logger
+		exchange    repository.Exchange
+		logger      logger.Logger
+		orderRules  *rules.Engine
+		books       *orderbook.Books
+		l3          *orderbook.L3Engine
+		tracker     *sequence.Tracker
+		candles     *candle.Builder
+		rates       *currency.Converter
+		deadLetters repository.DeadLetters
+		publisher   repository.Publisher
 	}
 	tests := []struct {
 		name string
@@ -24,7 +49,7 @@ func TestNewExchangeService(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewExchangeService(tt.args.exchange, tt.args.logger); !reflect.DeepEqual(got, tt.want) {
+			if got := NewExchangeService(tt.args.exchange, tt.args.logger, tt.args.orderRules, tt.args.books, tt.args.l3, tt.args.tracker, tt.args.candles, tt.args.rates, tt.args.deadLetters, tt.args.publisher); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewExchangeService() = %v, want %v", got, tt.want)
 			}
 		})
@@ -60,3 +85,215 @@ func Test_exchangeService_Tick(t *testing.T) {
 		})
 	}
 }
+
+// failingExchange fails every write
+type failingExchange struct {
+	repository.Exchange
+}
+
+func (failingExchange) CreateTick(context.Context, entity.Message) error {
+	return errors.New("db down")
+}
+
+func (failingExchange) CreateOrder(context.Context, entity.Message) error {
+	return errors.New("db down")
+}
+
+type deadLetters struct {
+	messages []entity.Message
+	causes   []error
+}
+
+func (d *deadLetters) Add(message entity.Message, cause error) error {
+	d.messages = append(d.messages, message)
+	d.causes = append(d.causes, cause)
+	return nil
+}
+
+func Test_exchangeService_ProcessStream_DeadLetters(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
+	dead := &deadLetters{}
+	e := NewExchangeService(failingExchange{}, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candle.NewBuilder(nil), currency.NewConverter(0), dead, nil)
+
+	ch := make(chan entity.Message, 3)
+	ch <- entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD"}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1, Size: dec(1), Price: dec(50000)}}
+	// below the default rules, not stored
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 2, Size: dec(0.1), Price: dec(50000)}}
+	close(ch)
+
+	// the stream goes on after failed writes
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	require.Len(t, dead.messages, 2)
+	assert.Equal(t, "BTC-USD", dead.messages[0].Ticker.Symbol)
+	assert.Equal(t, 1, dead.messages[1].Order.Sequence)
+	assert.EqualError(t, dead.causes[1], "db down")
+}
+
+func Test_exchangeService_ProcessStream_NoDeadLetters(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
+	e := NewExchangeService(failingExchange{}, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candle.NewBuilder(nil), currency.NewConverter(0), nil, nil)
+
+	ch := make(chan entity.Message, 2)
+	ch <- entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD"}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1, Size: dec(1), Price: dec(50000)}}
+	close(ch)
+
+	// failed writes are dropped without a dead letter queue
+	assert.NoError(t, e.ProcessStream(context.Background(), ch))
+}
+
+// orderStore keeps the orders and feed gaps written
+type orderStore struct {
+	repository.Exchange
+	orders []entity.Order
+	gaps   []entity.FeedGap
+}
+
+func (s *orderStore) CreateFeedGap(_ context.Context, gap entity.FeedGap) error {
+	s.gaps = append(s.gaps, gap)
+	return nil
+}
+
+func (s *orderStore) CreateTick(context.Context, entity.Message) error {
+	return nil
+}
+
+func (s *orderStore) CreateOrder(_ context.Context, message entity.Message) error {
+	s.orders = append(s.orders, *message.Order)
+	return nil
+}
+
+func Test_exchangeService_ProcessStream_USDValue(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+	require.NoError(t, orderRules.Store(&rules.RuleSet{Rules: []rules.Rule{
+		{Product: rules.AnyProduct, Types: []string{"match", "received"}, MinNotional: dec(20000)},
+	}}))
+
+	store := &orderStore{}
+	e := NewExchangeService(store, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candle.NewBuilder(nil), currency.NewConverter(0), &deadLetters{}, nil)
+
+	ch := make(chan entity.Message, 4)
+	// 10 ETH at 0.05 BTC is 0.5 BTC, no USD rate yet
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "ETH-BTC", Sequence: 1, Size: dec(10), Price: dec(0.05)}}
+	ch <- entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD", Price: decimal.NewNullDecimal(dec(60000))}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "ETH-BTC", Sequence: 2, Size: dec(10), Price: dec(0.05)}}
+	// market order valued by its funds
+	ch <- entity.Message{Order: &entity.Order{Type: "received", ProductID: "BTC-USD", Sequence: 1, OrderType: "market", Funds: dec(25000)}}
+	close(ch)
+
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	require.Len(t, store.orders, 2)
+	assert.Equal(t, 2, store.orders[0].Sequence)
+	assert.Equal(t, "30000", store.orders[0].USDValue.Decimal.String())
+	assert.Equal(t, "25000", store.orders[1].USDValue.Decimal.String())
+}
+
+func Test_exchangeService_ProcessStream_FeedGap(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
+	store := &orderStore{}
+	e := NewExchangeService(store, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candle.NewBuilder(nil), currency.NewConverter(0), &deadLetters{}, nil)
+
+	ch := make(chan entity.Message, 2)
+	ch <- entity.Message{Order: &entity.Order{Type: "open", ProductID: "GAP-USD", Sequence: 1}}
+	ch <- entity.Message{Order: &entity.Order{Type: "open", ProductID: "GAP-USD", Sequence: 5}}
+	close(ch)
+
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	require.Len(t, store.gaps, 1)
+	assert.Equal(t, int64(3), store.gaps[0].Missing)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.FeedGaps.WithLabelValues("GAP-USD")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(metrics.FeedGapMissing.WithLabelValues("GAP-USD")))
+}
+
+func Test_exchangeService_ProcessStream_UnsequencedTrades(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+	require.NoError(t, orderRules.Store(&rules.RuleSet{Rules: []rules.Rule{
+		{Product: rules.AnyProduct, Types: []string{"match"}},
+	}}))
+
+	store := &orderStore{}
+	tracker := sequence.NewTracker()
+	e := NewExchangeService(store, nopLogger{}, orderRules, orderbook.NewBooks(), nil, tracker, candle.NewBuilder(nil), currency.NewConverter(0), &deadLetters{}, nil)
+
+	// an Advanced Trade snapshot has the latest trades first and is sent again on reconnect
+	m, err := advanced.ParseMessage([]byte(`{"channel":"market_trades","timestamp":"2024-01-02T03:04:05Z","sequence_num":3,"events":[{"type":"snapshot","trades":[
+		{"trade_id":"20","product_id":"BTC-USD","price":"42000.10","size":"0.5","side":"BUY","time":"2024-01-02T03:04:05Z"},
+		{"trade_id":"15","product_id":"BTC-USD","price":"42000.00","size":"0.25","side":"SELL","time":"2024-01-02T03:04:04Z"}]}]}`))
+	require.NoError(t, err)
+	messages, err := m.ToMessages(time.Now())
+	require.NoError(t, err)
+
+	ch := make(chan entity.Message, 2*len(messages))
+	for i := 0; i < 2; i++ {
+		for _, message := range messages {
+			ch <- message
+		}
+	}
+	close(ch)
+
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	// no gap, no duplicate dropped
+	assert.Empty(t, store.gaps)
+	assert.Empty(t, tracker.Products())
+	require.Len(t, store.orders, 4)
+	for i, order := range store.orders {
+		assert.Equal(t, messages[i%len(messages)].Order.TradeID, order.TradeID)
+	}
+}
+
+// publisher keeps the messages published
+type publisher struct {
+	messages []entity.Message
+}
+
+func (p *publisher) PublishMessage(message entity.Message) error {
+	p.messages = append(p.messages, message)
+	return nil
+}
+
+func Test_exchangeService_ProcessStream_Publisher(t *testing.T) {
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
+	bus := &publisher{}
+	e := NewExchangeService(&orderStore{}, nopLogger{}, orderRules, orderbook.NewBooks(), nil, sequence.NewTracker(), candle.NewBuilder(nil), currency.NewConverter(0), &deadLetters{}, bus)
+
+	ch := make(chan entity.Message, 6)
+	ch <- entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD", Price: decimal.NewNullDecimal(dec(60000))}}
+	// below the default rules, published but not stored
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1, Size: dec(0.1), Price: dec(60000)}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1, Size: dec(0.1), Price: dec(60000)}}
+	ch <- entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "BTC-USD", Sequence: 1}}
+	ch <- entity.Message{Book: &entity.BookUpdate{ProductID: "BTC-USD"}}
+	ch <- entity.Message{Candle: &entity.Candle{ProductID: "BTC-USD", Granularity: 300}}
+	close(ch)
+
+	require.NoError(t, e.ProcessStream(context.Background(), ch))
+
+	// the duplicate order and the book update are not published
+	require.Len(t, bus.messages, 4)
+	assert.Equal(t, "6000", bus.messages[1].Order.USDValue.Decimal.String())
+	assert.NotNil(t, bus.messages[2].Heartbeat)
+	assert.NotNil(t, bus.messages[3].Candle)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) InitLogger()          {}
+func (nopLogger) Debug(...interface{}) {}
+func (nopLogger) Info(...interface{})  {}
+func (nopLogger) Error(...interface{}) {}
+func (nopLogger) Fatal(...interface{}) {}
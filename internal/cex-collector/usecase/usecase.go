@@ -3,9 +3,15 @@ package usecase
 import (
 	"context"
 
+	"github.com/nel349/bz-findata/config"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
 	"github.com/nel349/bz-findata/pkg/entity"
 	"github.com/nel349/bz-findata/pkg/logger"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
 )
 
 // Exchange usecase
@@ -14,19 +20,84 @@ type Exchange interface {
 	ProcessStream(ctx context.Context, ch <-chan entity.Message) error
 }
 
+// Book usecase
+type Book interface {
+	// Books returns the in-memory level2 books maintained from the stream
+	Books() *orderbook.Books
+	// RunSnapshots persists snapshots of every book on interval
+	RunSnapshots(ctx context.Context) error
+}
+
+// Candle usecase
+type Candle interface {
+	// Candles returns the builder aggregating the matches of the stream
+	Candles() *candle.Builder
+	// RunCandles writes the candles built from the stream on interval,
+	// then what is left once ctx is done
+	RunCandles(ctx context.Context) error
+}
+
 // Services struct of usecase layout
 type Services struct {
 	Exchange
+	Book
+	Candle
 }
 
 // Packages struct of usecase packages
 type Packages struct {
 	Logger logger.Logger
+	// OrderRules selects the orders stored, the default rule set is used when nil
+	OrderRules *rules.Engine
+	Book       config.BookConfig
+	// BookSource enables full channel books resynced from this source when set
+	BookSource orderbook.SnapshotSource
+	// Sequences tracks the feed sequence of every product, a new tracker is used when nil
+	Sequences *sequence.Tracker
+	Candle    config.CandleConfig
+	// Candles aggregates every match, a builder of the default granularities is used when nil
+	Candles *candle.Builder
+	// Rates converts order notionals to USD, a converter keeping rates forever is used when nil
+	Rates *currency.Converter
+	// DeadLetters keeps the ticks and orders that could not be stored, they are lost when nil
+	DeadLetters repository.DeadLetters
+	// Publisher publishes every parsed ticker, order and heartbeat, nothing is published when nil
+	Publisher repository.Publisher
 }
 
 // NewUseCase create usecase layout
 func NewUseCase(repos *repository.Repositories, pkg *Packages) *Services {
+	books := orderbook.NewBooks()
+
+	var l3 *orderbook.L3Engine
+	if pkg.BookSource != nil {
+		l3 = orderbook.NewL3Engine(pkg.BookSource, books)
+	}
+
+	orderRules := pkg.OrderRules
+	if orderRules == nil {
+		// the default rule set has no file to fail on
+		orderRules, _ = rules.NewEngine("", pkg.Logger)
+	}
+
+	tracker := pkg.Sequences
+	if tracker == nil {
+		tracker = sequence.NewTracker()
+	}
+
+	candles := pkg.Candles
+	if candles == nil {
+		candles = candle.NewBuilder(nil)
+	}
+
+	rates := pkg.Rates
+	if rates == nil {
+		rates = currency.NewConverter(0)
+	}
+
 	return &Services{
-		Exchange: NewExchangeService(repos.Exchange, pkg.Logger),
+		Exchange: NewExchangeService(repos.Exchange, pkg.Logger, orderRules, books, l3, tracker, candles, rates, pkg.DeadLetters, pkg.Publisher),
+		Book:     NewBookService(repos.Exchange, pkg.Logger, books, pkg.Book),
+		Candle:   NewCandleService(repos.Exchange, pkg.Logger, candles, pkg.Candle),
 	}
 }
@@ -1,13 +1,34 @@
 package usecase
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/nel349/bz-findata/config"
 	"github.com/nel349/bz-findata/internal/cex-collector/repository"
+	"github.com/nel349/bz-findata/internal/cex-collector/rules"
+	"github.com/nel349/bz-findata/pkg/candle"
+	"github.com/nel349/bz-findata/pkg/currency"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/nel349/bz-findata/pkg/sequence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewUseCase(t *testing.T) {
+	store := failingExchange{}
+	dead := &deadLetters{}
+	tracker := sequence.NewTracker()
+	bookCfg := config.BookConfig{SnapshotInterval: time.Minute, Levels: 50}
+	candleCfg := config.CandleConfig{FlushInterval: time.Second}
+	candles := candle.NewBuilder([]time.Duration{time.Minute})
+	rates := currency.NewConverter(time.Minute)
+	orderRules, err := rules.NewEngine("", nopLogger{})
+	require.NoError(t, err)
+
 	type args struct {
 		repos *repository.Repositories
 		pkg   *Packages
@@ -17,7 +38,18 @@ func TestNewUseCase(t *testing.T) {
 		args args
 		want *Services
 	}{
-		// TODO: Add test cases.
+		{
+			name: "shared tracker, candles and rates",
+			args: args{
+				repos: &repository.Repositories{Exchange: store},
+				pkg:   &Packages{Logger: nopLogger{}, OrderRules: orderRules, Book: bookCfg, Sequences: tracker, Candle: candleCfg, Candles: candles, Rates: rates, DeadLetters: dead},
+			},
+			want: &Services{
+				Exchange: NewExchangeService(store, nopLogger{}, orderRules, orderbook.NewBooks(), nil, tracker, candles, rates, dead, nil),
+				Book:     NewBookService(store, nopLogger{}, orderbook.NewBooks(), bookCfg),
+				Candle:   NewCandleService(store, nopLogger{}, candles, candleCfg),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -27,3 +59,29 @@ func TestNewUseCase(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUseCase_DefaultTracker(t *testing.T) {
+	store := &feedGaps{}
+
+	uc := NewUseCase(&repository.Repositories{Exchange: store}, &Packages{Logger: nopLogger{}})
+
+	ch := make(chan entity.Message, 2)
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 1}}
+	ch <- entity.Message{Order: &entity.Order{Type: "match", ProductID: "BTC-USD", Sequence: 5}}
+	close(ch)
+
+	// sequences are tracked and orders filtered without a tracker or rules in Packages
+	require.NoError(t, uc.Exchange.ProcessStream(context.Background(), ch))
+	assert.Len(t, store.gaps, 1)
+}
+
+// feedGaps keeps the feed gaps written
+type feedGaps struct {
+	repository.Exchange
+	gaps []entity.FeedGap
+}
+
+func (f *feedGaps) CreateFeedGap(_ context.Context, gap entity.FeedGap) error {
+	f.gaps = append(f.gaps, gap)
+	return nil
+}
@@ -10,7 +10,9 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/nel349/bz-findata/internal/dex/eth/moralis"
+	"github.com/nel349/bz-findata/internal/dex/metrics"
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
 )
 
 /**
@@ -33,7 +35,7 @@ import (
 type DefiLlamaResponse struct {
 	Coins map[string]struct {
 		Decimals   uint8   `json:"decimals"`
-		Price      float64 `json:"price"`
+		Price      decimal.Decimal `json:"price"`
 		Symbol     string  `json:"symbol"`
 		Timestamp  int64   `json:"timestamp"`
 		Confidence float64 `json:"confidence"`
@@ -41,6 +43,7 @@ type DefiLlamaResponse struct {
 }
 
 func GetTokenInfo(tokenAddress string) (entity.TokenInfo, error) {
+	defer metrics.ObservePriceAPI("defillama", time.Now())
 
 	url := fmt.Sprintf("https://coins.llama.fi/prices/current/ethereum:%s?searchWidth=4h", tokenAddress)
 
@@ -85,7 +88,8 @@ func GetTokenInfo(tokenAddress string) (entity.TokenInfo, error) {
 func GetTokenMetadataFromDbOrDefiLlama(db *sqlx.DB, tokenAddress string, updateInterval time.Duration) (entity.TokenInfo, error) {
 	// Try to get from database first
 	var tokenInfo entity.TokenInfo
-	err := db.Get(&tokenInfo, "SELECT * FROM token_metadata WHERE BINARY address = ?", strings.ToLower(tokenAddress))
+	selectQuery, upsertQuery := tokenQueries(db)
+	err := db.Get(&tokenInfo, selectQuery, strings.ToLower(tokenAddress))
 	if err == nil {
         timeSinceUpdate := time.Since(tokenInfo.LastUpdated)
         // fmt.Printf("Debug: Token %s last updated: %v\n", tokenAddress, tokenInfo.LastUpdated)
@@ -117,7 +121,20 @@ func GetTokenMetadataFromDbOrDefiLlama(db *sqlx.DB, tokenAddress string, updateI
 	}
 
 	// Store in database
-	_, err = db.Exec(`
+	_, err = db.Exec(upsertQuery,
+		strings.ToLower(tokenInfo.Address), tokenInfo.Decimals, tokenInfo.Symbol, tokenInfo.Price, time.Now().UTC(),
+	)
+	if err != nil {
+		return entity.TokenInfo{}, fmt.Errorf("failed to store token metadata: %w", err)
+	}
+	// fmt.Println("Stored or updated token metadata in database: ", tokenInfo)
+	return tokenInfo, nil
+}
+
+// token_metadata cache queries of mysql and postgres, addresses are stored in lower case
+const (
+	selectTokenMySQL = "SELECT * FROM token_metadata WHERE BINARY address = ?"
+	upsertTokenMySQL = `
     INSERT INTO token_metadata (address, decimals, symbol, price, last_updated) 
     VALUES (?, ?, ?, ?, ?)
 	ON DUPLICATE KEY UPDATE
@@ -126,20 +143,45 @@ func GetTokenMetadataFromDbOrDefiLlama(db *sqlx.DB, tokenAddress string, updateI
 		symbol = VALUES(symbol),
 		price = VALUES(price),
 		last_updated = VALUES(last_updated)
-	`,
-		strings.ToLower(tokenInfo.Address), tokenInfo.Decimals, tokenInfo.Symbol, tokenInfo.Price, time.Now(),
-	)
-	if err != nil {
-		return entity.TokenInfo{}, fmt.Errorf("failed to store token metadata: %w", err)
+	`
+	selectTokenPostgres = "SELECT * FROM token_metadata WHERE address = $1"
+	upsertTokenPostgres = `
+    INSERT INTO token_metadata (address, decimals, symbol, price, last_updated)
+    VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (address) DO UPDATE SET
+		decimals = EXCLUDED.decimals,
+		symbol = EXCLUDED.symbol,
+		price = EXCLUDED.price,
+		last_updated = EXCLUDED.last_updated
+	`
+	selectTokenSQLite = "SELECT * FROM token_metadata WHERE address = ?"
+	upsertTokenSQLite = `
+    INSERT INTO token_metadata (address, decimals, symbol, price, last_updated)
+    VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (address) DO UPDATE SET
+		decimals = EXCLUDED.decimals,
+		symbol = EXCLUDED.symbol,
+		price = EXCLUDED.price,
+		last_updated = EXCLUDED.last_updated
+	`
+)
+
+// tokenQueries returns the select and upsert queries of the token_metadata cache of db
+func tokenQueries(db *sqlx.DB) (string, string) {
+	switch db.DriverName() {
+	case "pgx", "postgres":
+		return selectTokenPostgres, upsertTokenPostgres
+	case "sqlite3":
+		return selectTokenSQLite, upsertTokenSQLite
+	default:
+		return selectTokenMySQL, upsertTokenMySQL
 	}
-	// fmt.Println("Stored or updated token metadata in database: ", tokenInfo)
-	return tokenInfo, nil
 }
 
-func GetWETHPrice(db *sqlx.DB) (float64, error) {
+func GetWETHPrice(db *sqlx.DB) (decimal.Decimal, error) {
 	tokenInfo, err := GetTokenMetadataFromDbOrDefiLlama(db, "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", 15*time.Minute)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get WETH price: %w", err)
+		return decimal.Zero, fmt.Errorf("failed to get WETH price: %w", err)
 	}
 
 	return tokenInfo.Price, nil
@@ -106,7 +106,7 @@ func TestGetTokenInfoFromServerAndStoreInDB(t *testing.T) {
 	}
 
 	// check the token info fields are not empty
-	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price == 0 {
+	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price.IsZero() {
 		t.Errorf("token info fields are empty")
 	}
 
@@ -1,8 +1,18 @@
 package defi_llama
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/migrations"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetTokenInfo(t *testing.T) {
@@ -13,12 +23,45 @@ func TestGetTokenInfo(t *testing.T) {
 	}
 
 	// check the token info fields are not empty
-	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price == 0 {
+	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price.IsZero() {
 		t.Errorf("token info fields are empty")
 	}
 
 	fmt.Println(tokenInfo)
-	fmt.Printf("%.9f\n", tokenInfo.Price)
+	fmt.Println(tokenInfo.Price.StringFixed(9))
 	fmt.Println(tokenInfo.Symbol)
 	fmt.Println(tokenInfo.Decimals)
 }
+
+func TestTokenQueries(t *testing.T) {
+	selectQuery, upsertQuery := tokenQueries(sqlx.NewDb(nil, "pgx"))
+	assert.Equal(t, selectTokenPostgres, selectQuery)
+	assert.Contains(t, upsertQuery, "ON CONFLICT (address) DO UPDATE")
+
+	selectQuery, upsertQuery = tokenQueries(sqlx.NewDb(nil, "mysql"))
+	assert.Equal(t, selectTokenMySQL, selectQuery)
+	assert.Contains(t, upsertQuery, "ON DUPLICATE KEY UPDATE")
+
+	selectQuery, _ = tokenQueries(sqlx.NewDb(nil, "sqlite3"))
+	assert.Equal(t, selectTokenSQLite, selectQuery)
+}
+
+func TestTokenQueries_sqlite(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	defer db.Close()
+	_, err := migrations.Up(context.Background(), db, migrations.SQLite)
+	require.NoError(t, err)
+
+	selectQuery, upsertQuery := tokenQueries(db)
+	updated := time.Now().UTC().Truncate(time.Second)
+	for _, price := range []string{"1.5", "2.25"} {
+		_, err = db.Exec(upsertQuery, "0xabc", 18, "TKN", decimal.RequireFromString(price), updated)
+		require.NoError(t, err)
+	}
+
+	var token entity.TokenInfo
+	require.NoError(t, db.Get(&token, selectQuery, "0xabc"))
+	assert.Equal(t, "2.25", token.Price.String())
+	assert.Equal(t, uint8(18), token.Decimals)
+	assert.True(t, updated.Equal(token.LastUpdated))
+}
@@ -1,14 +1,18 @@
 package moralis
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
+	"time"
 
+	"github.com/nel349/bz-findata/internal/dex/metrics"
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/secrets"
+	"github.com/shopspring/decimal"
 )
 
 /*
@@ -49,17 +53,23 @@ type MoralisResponse struct {
 		Value    string `json:"value"`
 		Decimals uint8  `json:"decimals"`
 	} `json:"nativePrice"`
-	UsdPrice          float64 `json:"usdPrice"`
+	UsdPrice          decimal.Decimal `json:"usdPrice"`
 	UsdPriceFormatted string  `json:"usdPriceFormatted"`
 }
 
 func GetTokenInfoFromMoralis(tokenAddress string) (entity.TokenInfo, error) {
+	defer metrics.ObservePriceAPI("moralis", time.Now())
 	url := fmt.Sprintf("https://deep-index.moralis.io/api/v2.2/erc20/%s/price?chain=eth&include=percent_change", tokenAddress)
 
+	apiKey, err := secrets.Get(context.Background(), "MORALIS_API_KEY")
+	if err != nil {
+		return entity.TokenInfo{}, fmt.Errorf("moralis api key: %w", err)
+	}
+
 	req, _ := http.NewRequest("GET", url, nil)
 
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("X-API-Key", os.Getenv("MORALIS_API_KEY"))
+	req.Header.Add("X-API-Key", apiKey)
 
 	res, _ := http.DefaultClient.Do(req)
 
@@ -72,7 +82,7 @@ func GetTokenInfoFromMoralis(tokenAddress string) (entity.TokenInfo, error) {
 	body, _ := io.ReadAll(res.Body)
 
 	var response MoralisResponse
-	err := json.Unmarshal(body, &response)
+	err = json.Unmarshal(body, &response)
 	if err != nil {
 		return entity.TokenInfo{}, fmt.Errorf("failed to parse JSON: %w", err)
 	}
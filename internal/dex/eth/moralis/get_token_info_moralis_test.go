@@ -21,7 +21,7 @@ func TestGetTokenInfo(t *testing.T) {
 	}
 
 	// check the token info fields are not empty
-	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price == 0 {
+	if tokenInfo.Address == "" || tokenInfo.Symbol == "" || tokenInfo.Decimals == 0 || tokenInfo.Price.IsZero() {
 		t.Errorf("token info fields are empty")
 	}
 
@@ -41,7 +41,7 @@ func TestGetTokenInfo(t *testing.T) {
 	}
 
 	fmt.Println(tokenInfo)
-	fmt.Printf("%.9f\n", tokenInfo.Price)
+	fmt.Println(tokenInfo.Price.StringFixed(9))
 	fmt.Println(tokenInfo.Symbol)
 	fmt.Println(tokenInfo.Decimals)
 }
@@ -3,6 +3,8 @@ package decoder
 import (
 	"math"
 	"math/big"
+
+	"github.com/shopspring/decimal"
 )
 
 func ConvertToFloat64(str string) float64 {
@@ -27,31 +29,13 @@ func GetEthValue(value *big.Int) float64 {
 	return result
 }
 
-func GetUsdValueFromEth(value *big.Int, ethPrice float64) float64 {
-
-	tokenValue := new(big.Float).SetInt(value)
-	scale := big.NewFloat(math.Pow(10, 18)) // 18 decimals for ETH
-	tokenValue = tokenValue.Quo(tokenValue, scale)
-
-	result, _ := new(big.Float).Mul(
-		tokenValue,
-		big.NewFloat(ethPrice),
-	).Float64()
-
-	return result
+func GetUsdValueFromEth(value *big.Int, ethPrice decimal.Decimal) decimal.Decimal {
+	return GetUsdValueFromToken(value, ethPrice, 18) // 18 decimals for ETH
 }
 
-func GetUsdValueFromToken(value *big.Int, tokenPrice float64, decimals int) float64 {
-	tokenValue := new(big.Float).SetInt(value)
-	scale := big.NewFloat(math.Pow(10, float64(decimals)))
-	tokenValue = tokenValue.Quo(tokenValue, scale)
-
-	result, _ := new(big.Float).Mul(
-        tokenValue,
-        big.NewFloat(tokenPrice),
-    ).Float64()
-
-    return result
+// GetUsdValueFromToken scales a raw token amount by its decimals and prices it, exactly
+func GetUsdValueFromToken(value *big.Int, tokenPrice decimal.Decimal, decimals int) decimal.Decimal {
+	return decimal.NewFromBigInt(value, -int32(decimals)).Mul(tokenPrice)
 }
 
 // GetTokenAmountFromRaw converts a raw token amount to a string with the token amount in the correct format
@@ -62,9 +46,6 @@ func GetTokenAmountFromRawToBigFloat(value string, decimals int) *big.Float {
 	return tokenValue
 }
 
-func GetTokenFromRawToUsd(value string, tokenPrice float64, decimals int) float64 {
-	amountInFloat := GetTokenAmountFromRawToBigFloat(value, decimals)
-	tokenPriceFloat := new(big.Float).SetFloat64(tokenPrice)
-	result, _ := amountInFloat.Mul(amountInFloat, tokenPriceFloat).Float64()
-	return result
+func GetTokenFromRawToUsd(value string, tokenPrice decimal.Decimal, decimals int) decimal.Decimal {
+	return GetUsdValueFromToken(ConvertToBigInt(value), tokenPrice, decimals)
 }
\ No newline at end of file
@@ -1,9 +1,10 @@
 package decoder
 
 import (
-	"math"
 	"math/big"
 	"testing"
+
+	"github.com/shopspring/decimal"
 )
 
 func TestGetUsdValueFromToken(t *testing.T) {
@@ -12,15 +13,14 @@ func TestGetUsdValueFromToken(t *testing.T) {
 		t.Fatal("failed to parse big.Int")
 	}
 
-	tokenPrice := 0.026534231
+	tokenPrice := decimal.RequireFromString("0.026534231")
 	decimals := 9
 
 	result := GetUsdValueFromToken(amountIn, tokenPrice, decimals)
 
-	expected := 2434.40644997 // 91745.882892710 * 0.026534231
+	expected := decimal.RequireFromString("2434.40644997411535601") // 91745.882892710 * 0.026534231, no rounding
 
-	// lets do some rounding
-	if math.Abs(result-expected) > 0.00001 {
+	if !result.Equal(expected) {
 		t.Errorf("Result does not match expected value %v, got %v", expected, result)
 	}
 }
@@ -31,13 +31,13 @@ func TestGetUsdValueFromEth(t *testing.T) {
 		t.Fatal("failed to parse big.Int")
 	}
 
-	ethPrice := 1949.99
+	ethPrice := decimal.RequireFromString("1949.99")
 
 	result := GetUsdValueFromEth(amountIn, ethPrice)
 
-	expected := 1949.99
+	expected := decimal.RequireFromString("1949.99")
 
-	if result != expected {
+	if !result.Equal(expected) {
 		t.Errorf("Result does not match expected value %v, got %v", expected, result)
 	}
 }
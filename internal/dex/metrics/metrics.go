@@ -0,0 +1,62 @@
+// Package metrics holds the Prometheus metrics of the dex monitor
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "dex"
+
+var (
+	// BlocksProcessed counts the blocks scanned for swaps
+	BlocksProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_processed_total",
+		Help:      "Blocks scanned for swaps.",
+	})
+
+	// SwapsDecoded counts the swaps decoded per router method
+	SwapsDecoded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "swaps_decoded_total",
+		Help:      "Swaps decoded by router method.",
+	}, []string{"method"})
+
+	// DecodeFailures counts the router transactions that could not be decoded
+	DecodeFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "decode_failures_total",
+		Help:      "Router transactions that could not be decoded.",
+	})
+
+	// PublishFailures counts the swap events dropped or rejected by the message bus
+	PublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bus_publish_failures_total",
+		Help:      "Swap events dropped by the publisher or rejected by the message bus.",
+	})
+
+	// SinkFailures counts the failed copies of swaps to the sinks, like the Parquet archive
+	SinkFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sink_failures_total",
+		Help:      "Failed copies of swaps to the sinks.",
+	})
+
+	// PriceAPIDuration observes the calls to the price APIs per source
+	PriceAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "price_api_duration_seconds",
+		Help:      "Duration of the calls to the token price APIs by source.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+)
+
+// ObservePriceAPI records a call to the price API source started at start,
+// meant to be deferred
+func ObservePriceAPI(source string, start time.Time) {
+	PriceAPIDuration.WithLabelValues(source).Observe(time.Since(start).Seconds())
+}
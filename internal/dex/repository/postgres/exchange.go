@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/internal/dex/repository/swaps"
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// insertSwapQuery stores a swap row, a swap already stored is kept
+const insertSwapQuery = `
+	INSERT INTO swap_transactions (
+		value,
+		tx_hash,
+		version,
+		exchange,
+		amount_in,
+		to_address,
+		token_path_from,
+		token_path_to,
+		amount_token_desired,
+		amount_token_min,
+		amount_eth_min,
+		amount_out_min,
+		method_id,
+		method_name,
+		liquidity,
+		token_a,
+		token_b,
+		amount_a_desired,
+		amount_b_desired,
+		amount_a_min,
+		amount_b_min
+	) VALUES (
+		:value,
+		:tx_hash,
+		:version,
+		:exchange,
+		:amount_in,
+		:to_address,
+		:token_path_from,
+		:token_path_to,
+		:amount_token_desired,
+		:amount_token_min,
+		:amount_eth_min,
+		:amount_out_min,
+		:method_id,
+		:method_name,
+		:liquidity,
+		:token_a,
+		:token_b,
+		:amount_a_desired,
+		:amount_b_desired,
+		:amount_a_min,
+		:amount_b_min
+	) ON CONFLICT DO NOTHING`
+
+type dexExchangeRepo struct {
+	db *sqlx.DB
+}
+
+// NewDexExchangeRepository created exchange repository
+func NewDexExchangeRepository(db *sqlx.DB) *dexExchangeRepo {
+	return &dexExchangeRepo{db}
+}
+
+func (e *dexExchangeRepo) SaveSwap(ctx context.Context, tx *types.Transaction, version string) error {
+	rows, err := swaps.Decode(e.db, tx, version)
+	if err != nil {
+		return err
+	}
+
+	return e.SaveSwaps(ctx, rows)
+}
+
+// SaveSwaps stores swaps already decoded and valued
+func (e *dexExchangeRepo) SaveSwaps(ctx context.Context, rows []entity.SwapTransaction) error {
+	ctxReq, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	var errs []error
+	for _, row := range rows {
+		if _, err := e.db.NamedExecContext(ctxReq, insertSwapQuery, row); err != nil {
+			errs = append(errs, fmt.Errorf("failed to insert swap %s: %w", row.TxHash, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
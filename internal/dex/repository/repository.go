@@ -2,24 +2,89 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/internal/dex/metrics"
 	"github.com/nel349/bz-findata/internal/dex/repository/mysql"
+	"github.com/nel349/bz-findata/internal/dex/repository/postgres"
+	"github.com/nel349/bz-findata/internal/dex/repository/sqlite"
+	"github.com/nel349/bz-findata/internal/dex/repository/swaps"
+	"github.com/nel349/bz-findata/pkg/entity"
 )
 
+// ErrSink is wrapped by the failed copies to the sinks, the swaps may still be stored
+var ErrSink = errors.New("failed to copy swaps to sink")
+
 // Exchange method implementations
 type DexExchange interface {
 	SaveSwap(ctx context.Context, tx *types.Transaction, version string) error
 }
 
+// SwapStore stores swaps already decoded and valued
+type SwapStore interface {
+	SaveSwaps(ctx context.Context, rows []entity.SwapTransaction) error
+}
+
+// SwapSink gets a copy of every swap stored, like the Parquet archive
+type SwapSink interface {
+	WriteSwaps(rows []entity.SwapTransaction) error
+}
+
 // This could contain multiple exchange repositories
 type DexRepositories struct {
 	DexExchange
 }
 
-func NewDexRepositories(db *sqlx.DB) *DexRepositories {
+// NewDexRepositories creates the repositories of the database driver,
+// the swaps stored are also copied to sinks
+func NewDexRepositories(db *sqlx.DB, sinks ...SwapSink) *DexRepositories {
 	return &DexRepositories{
-		DexExchange: mysql.NewDexExchangeRepository(db),
+		DexExchange: &fanOutDexExchange{db: db, store: NewSwapStore(db), sinks: sinks},
+	}
+}
+
+// NewSwapStore creates the swap storage of the database driver
+func NewSwapStore(db *sqlx.DB) SwapStore {
+	switch db.DriverName() {
+	case "pgx", "postgres":
+		return postgres.NewDexExchangeRepository(db)
+	case "sqlite3":
+		return sqlite.NewDexExchangeRepository(db)
+	default:
+		return mysql.NewDexExchangeRepository(db)
+	}
+}
+
+// fanOutDexExchange decodes the swaps of a transaction once, stores them and copies
+// them to sinks, a failing sink never prevents the storage write
+type fanOutDexExchange struct {
+	db    *sqlx.DB
+	store SwapStore
+	sinks []SwapSink
+}
+
+// SaveSwap stores the swaps of tx and copies them to the sinks, the failed copies are
+// returned wrapping ErrSink
+func (f *fanOutDexExchange) SaveSwap(ctx context.Context, tx *types.Transaction, version string) error {
+	rows, err := swaps.Decode(f.db, tx, version)
+	if err != nil {
+		return err
 	}
+
+	return f.saveSwaps(ctx, tx.Hash().Hex(), rows)
+}
+
+func (f *fanOutDexExchange) saveSwaps(ctx context.Context, txHash string, rows []entity.SwapTransaction) error {
+	errs := []error{f.store.SaveSwaps(ctx, rows)}
+	for _, sink := range f.sinks {
+		if err := sink.WriteSwaps(rows); err != nil {
+			metrics.SinkFailures.Inc()
+			errs = append(errs, fmt.Errorf("%w: tx %s: %w", ErrSink, txHash, err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/internal/dex/repository/mysql"
+	"github.com/nel349/bz-findata/internal/dex/repository/postgres"
+	"github.com/nel349/bz-findata/internal/dex/repository/sqlite"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSwapStore(t *testing.T) {
+	assert.IsType(t, postgres.NewDexExchangeRepository(nil), NewSwapStore(sqlx.NewDb(nil, "pgx")))
+	assert.IsType(t, mysql.NewDexExchangeRepository(nil), NewSwapStore(sqlx.NewDb(nil, "mysql")))
+	assert.IsType(t, sqlite.NewDexExchangeRepository(nil), NewSwapStore(sqlx.NewDb(nil, "sqlite3")))
+}
+
+type swapStore struct {
+	rows []entity.SwapTransaction
+}
+
+func (s *swapStore) SaveSwaps(_ context.Context, rows []entity.SwapTransaction) error {
+	s.rows = append(s.rows, rows...)
+	return nil
+}
+
+type brokenSink struct{}
+
+func (brokenSink) WriteSwaps([]entity.SwapTransaction) error {
+	return errors.New("disk full")
+}
+
+func TestFanOutDexExchange_saveSwaps(t *testing.T) {
+	store := &swapStore{}
+	f := &fanOutDexExchange{store: store, sinks: []SwapSink{brokenSink{}}}
+
+	err := f.saveSwaps(context.Background(), "0xabc", []entity.SwapTransaction{{TxHash: "0xabc"}})
+
+	// the swap is stored, the failed copy is returned
+	assert.Len(t, store.rows, 1)
+	assert.ErrorIs(t, err, ErrSink)
+	assert.EqualError(t, err, "failed to copy swaps to sink: tx 0xabc: disk full")
+}
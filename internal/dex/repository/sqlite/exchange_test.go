@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/nel349/bz-findata/pkg/migrations"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDexExchangeRepo_SaveSwaps(t *testing.T) {
+	ctx := context.Background()
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	// every connection of an in memory database is a new database
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	_, err := migrations.Up(ctx, db, migrations.SQLite)
+	require.NoError(t, err)
+
+	swap := entity.SwapTransaction{
+		TxHash: "0xabc", Version: "V2", Exchange: "uniswap", AmountIn: "1000", ToAddress: "0x1",
+		TokenPathFrom: "0x2", TokenPathTo: "0x3", Value: decimal.RequireFromString("125000.5"), MethodName: "swapExactETHForTokens",
+	}
+	repo := NewDexExchangeRepository(db)
+	require.NoError(t, repo.SaveSwaps(ctx, []entity.SwapTransaction{swap, swap}))
+
+	// read back like the analysis does, no column is NULL
+	var got []entity.SwapTransaction
+	require.NoError(t, db.Select(&got, "SELECT * FROM swap_transactions WHERE last_updated > datetime(?, 'unixepoch') ORDER BY value DESC", 0))
+	require.Len(t, got, 1)
+	assert.Equal(t, "125000.5", got[0].Value.String())
+	assert.NotEmpty(t, got[0].LastUpdated)
+}
@@ -0,0 +1,263 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Tables of the archive
+const (
+	Ticks  = "ticks"
+	Orders = "orders"
+	Swaps  = "swaps"
+)
+
+// ErrClosed is returned by the writes to a closed archive
+var ErrClosed = errors.New("archive closed")
+
+// DefaultLayout of the partition directories, {table}, {product}, {date} and {hour}
+// are replaced by the table, the product or dex, the UTC day and the UTC hour of the rows
+const DefaultLayout = "{table}/{product}/{date}/{hour}"
+
+const (
+	// rowGroupSize bounds the bytes an open file buffers in memory
+	rowGroupSize = 16 * 1024 * 1024
+	// closeInterval is how often the files of past hours are closed
+	closeInterval = time.Minute
+	// pending is the suffix of a file still written, readers only see closed files
+	pending = ".tmp"
+)
+
+// Archive writes ticks, orders and swaps to hourly partitioned Parquet files,
+// a partition file is closed closeDelay after its hour so late rows still make it
+type Archive struct {
+	dir        string
+	layout     string
+	closeDelay time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	files  map[fileKey]*file
+	closed bool
+}
+
+// fileKey identifies an open file, tables and hours sharing a partition directory of a
+// layout without {table} or {hour} still get their own files
+type fileKey struct {
+	table string
+	dir   string
+	hour  time.Time
+}
+
+// file is an open Parquet file of a partition
+type file struct {
+	hour   time.Time
+	path   string
+	local  source.ParquetFile
+	writer *writer.ParquetWriter
+}
+
+// NewArchive creates an archive in dir, an empty layout is DefaultLayout
+func NewArchive(dir, layout string, closeDelay time.Duration) (*Archive, error) {
+	if layout == "" {
+		layout = DefaultLayout
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir: %w", err)
+	}
+
+	return &Archive{
+		dir:        dir,
+		layout:     layout,
+		closeDelay: closeDelay,
+		now:        time.Now,
+		files:      make(map[fileKey]*file),
+	}, nil
+}
+
+// WriteTicks archives ticks in the hour of their exchange time
+func (a *Archive) WriteTicks(ticks []entity.Ticker) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, t := range ticks {
+		if err := a.write(Ticks, t.Symbol, time.Unix(0, t.Timestamp), new(tickRow), newTickRow(t)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteOrders archives orders in the hour of their exchange time
+func (a *Archive) WriteOrders(orders []entity.Order) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, o := range orders {
+		if err := a.write(Orders, o.ProductID, time.Unix(0, o.Timestamp), new(orderRow), newOrderRow(o)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteSwaps archives swaps in the hour they are written, partitioned by dex
+func (a *Archive) WriteSwaps(swaps []entity.SwapTransaction) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for _, s := range swaps {
+		if err := a.write(Swaps, s.Exchange, now, new(swapRow), newSwapRow(s, now.UnixNano())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run closes the files of past hours until ctx is done, then closes every file.
+// A file failing to close doesn't stop the others, the first failure is returned
+func (a *Archive) Run(ctx context.Context) error {
+	ticker := time.NewTicker(closeInterval)
+	defer ticker.Stop()
+
+	var failed error
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Join(failed, a.Close())
+		case <-ticker.C:
+			if err := a.closeExpired(a.now()); err != nil && failed == nil {
+				failed = err
+			}
+		}
+	}
+}
+
+// Close closes every open file, nothing is archived afterwards
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.closed = true
+	var errs []error
+	for key, f := range a.files {
+		if err := f.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(a.files, key)
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeExpired closes the files whose hour ended closeDelay before now
+func (a *Archive) closeExpired(now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	for key, f := range a.files {
+		if now.Before(f.hour.Add(time.Hour + a.closeDelay)) {
+			continue
+		}
+		if err := f.close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(a.files, key)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *Archive) write(table, product string, at time.Time, schema, row interface{}) error {
+	if a.closed {
+		return ErrClosed
+	}
+
+	hour := at.UTC().Truncate(time.Hour)
+	key := fileKey{table, filepath.Join(a.dir, partition(a.layout, table, product, hour)), hour}
+
+	f, ok := a.files[key]
+	if !ok {
+		var err error
+		if f, err = openFile(key, a.now(), schema); err != nil {
+			return err
+		}
+		a.files[key] = f
+	}
+
+	if err := f.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to archive %s row: %w", table, err)
+	}
+
+	return nil
+}
+
+// partition is the directory of the rows of table and product in hour
+func partition(layout, table, product string, hour time.Time) string {
+	if product == "" {
+		product = "unknown"
+	}
+	// a product never adds directories
+	product = strings.NewReplacer("/", "_", `\`, "_", "..", "_").Replace(product)
+
+	return strings.NewReplacer(
+		"{table}", table,
+		"{product}", product,
+		"{date}", hour.Format("2006-01-02"),
+		"{hour}", hour.Format("15"),
+	).Replace(layout)
+}
+
+// openFile opens a new file of key named after its table, its hour and the time it was
+// opened, a partition reopened after its file was closed gets another file
+func openFile(key fileKey, now time.Time, schema interface{}) (*file, error) {
+	if err := os.MkdirAll(key.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create partition dir: %w", err)
+	}
+
+	name := key.table + "-" + key.hour.Format("2006010215") + "-" + strconv.FormatInt(now.UnixNano(), 10) + ".parquet"
+	path := filepath.Join(key.dir, name)
+	lf, err := local.NewLocalFileWriter(path + pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	pw, err := writer.NewParquetWriter(lf, schema, 1)
+	if err != nil {
+		_ = lf.Close()
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	pw.RowGroupSize = rowGroupSize
+
+	return &file{hour: key.hour, path: path, local: lf, writer: pw}, nil
+}
+
+// close writes the footer and publishes the file under its final name
+func (f *file) close() error {
+	if err := f.writer.WriteStop(); err != nil {
+		_ = f.local.Close()
+		return fmt.Errorf("failed to finish archive file %s: %w", f.path, err)
+	}
+	if err := f.local.Close(); err != nil {
+		return fmt.Errorf("failed to close archive file %s: %w", f.path, err)
+	}
+
+	return os.Rename(f.path+pending, f.path)
+}
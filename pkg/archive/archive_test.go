@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"database/sql"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var hour = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func readRows[T any](t *testing.T, pattern string) []T {
+	t.Helper()
+
+	paths, err := filepath.Glob(pattern)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+
+	lf, err := local.NewLocalFileReader(paths[0])
+	require.NoError(t, err)
+	defer lf.Close()
+	pr, err := reader.NewParquetReader(lf, new(T), 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+
+	rows := make([]T, pr.GetNumRows())
+	require.NoError(t, pr.Read(&rows))
+
+	return rows
+}
+
+// decimalString decodes the two's complement bytes of a DECIMAL(38,18)
+func decimalString(b string) string {
+	v := new(big.Int).SetBytes([]byte(b))
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+
+	return decimal.NewFromBigInt(v, -decimalScale).StringFixed(decimalScale)
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, "", 5*time.Minute)
+	require.NoError(t, err)
+	a.now = func() time.Time { return hour.Add(30 * time.Minute) }
+
+	require.NoError(t, a.WriteTicks([]entity.Ticker{
+		{Timestamp: hour.Add(time.Minute).UnixNano(), Symbol: "BTC-USD", Price: decimal.NewNullDecimal(decimal.RequireFromString("64000.123456789")), Side: sql.NullString{String: "buy", Valid: true}},
+		{Timestamp: hour.Add(time.Hour).UnixNano(), Symbol: "BTC-USD", Price: decimal.NewNullDecimal(decimal.RequireFromString("64100"))},
+	}))
+	require.NoError(t, a.WriteOrders([]entity.Order{
+		{Timestamp: hour.UnixNano(), ProductID: "ETH-USD", Type: "match", Size: decimal.RequireFromString("-1.5")},
+		{Timestamp: hour.UnixNano(), ProductID: "ETH-USD", Type: "match", USDValue: decimal.NewNullDecimal(decimal.RequireFromString("4500.25"))},
+	}))
+	require.NoError(t, a.WriteSwaps([]entity.SwapTransaction{{TxHash: "0xabc", Exchange: "uniswap", Value: decimal.RequireFromString("1200.5")}}))
+
+	// files are only published once closed
+	published, err := filepath.Glob(filepath.Join(dir, "*", "*", "*", "*", "*.parquet"))
+	require.NoError(t, err)
+	assert.Empty(t, published)
+
+	require.NoError(t, a.Close())
+	assert.ErrorIs(t, a.WriteTicks([]entity.Ticker{{Timestamp: hour.UnixNano(), Symbol: "BTC-USD"}}), ErrClosed)
+
+	ticks := readRows[tickRow](t, filepath.Join(dir, "ticks", "BTC-USD", "2024-05-01", "10", "*.parquet"))
+	require.Len(t, ticks, 1)
+	assert.Equal(t, "64000.123456789000000000", decimalString(*ticks[0].Price))
+	assert.Nil(t, ticks[0].LastSize)
+	assert.Equal(t, "buy", *ticks[0].Side)
+	assert.Nil(t, ticks[0].TradeID)
+	assert.Len(t, readRows[tickRow](t, filepath.Join(dir, "ticks", "BTC-USD", "2024-05-01", "11", "*.parquet")), 1)
+
+	orders := readRows[orderRow](t, filepath.Join(dir, "orders", "ETH-USD", "2024-05-01", "10", "*.parquet"))
+	require.Len(t, orders, 2)
+	assert.Equal(t, "-1.500000000000000000", decimalString(orders[0].Size))
+	assert.Nil(t, orders[0].USDValue)
+	require.NotNil(t, orders[1].USDValue)
+	assert.Equal(t, "4500.250000000000000000", decimalString(*orders[1].USDValue))
+
+	swaps := readRows[swapRow](t, filepath.Join(dir, "swaps", "uniswap", "2024-05-01", "10", "*.parquet"))
+	require.Len(t, swaps, 1)
+	assert.Equal(t, "0xabc", swaps[0].TxHash)
+	assert.Equal(t, hour.Add(30*time.Minute).UnixNano(), swaps[0].Timestamp)
+}
+
+func TestArchive_closeExpired(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, "{table}/product={product}/date={date}/hour={hour}", 5*time.Minute)
+	require.NoError(t, err)
+	a.now = func() time.Time { return hour }
+
+	require.NoError(t, a.WriteTicks([]entity.Ticker{{Timestamp: hour.UnixNano(), Symbol: "BTC-USD"}}))
+	partition := filepath.Join(dir, "ticks", "product=BTC-USD", "date=2024-05-01", "hour=10")
+
+	// still open within the close delay
+	require.NoError(t, a.closeExpired(hour.Add(time.Hour+4*time.Minute)))
+	assert.Len(t, a.files, 1)
+
+	require.NoError(t, a.closeExpired(hour.Add(time.Hour+5*time.Minute)))
+	assert.Empty(t, a.files)
+	assert.Len(t, readRows[tickRow](t, filepath.Join(partition, "*.parquet")), 1)
+
+	// a late row opens another file of its partition
+	a.now = func() time.Time { return hour.Add(2 * time.Hour) }
+	require.NoError(t, a.WriteTicks([]entity.Ticker{{Timestamp: hour.UnixNano(), Symbol: "BTC-USD"}}))
+	require.NoError(t, a.Close())
+
+	entries, err := os.ReadDir(partition)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestArchive_sharedPartition(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir, "{date}", time.Minute)
+	require.NoError(t, err)
+	a.now = func() time.Time { return hour }
+
+	require.NoError(t, a.WriteTicks([]entity.Ticker{
+		{Timestamp: hour.UnixNano(), Symbol: "BTC-USD"},
+		{Timestamp: hour.Add(time.Hour).UnixNano(), Symbol: "BTC-USD"},
+	}))
+	require.NoError(t, a.WriteOrders([]entity.Order{{Timestamp: hour.UnixNano(), ProductID: "BTC-USD"}}))
+	assert.Len(t, a.files, 3)
+	require.NoError(t, a.Close())
+
+	partition := filepath.Join(dir, "2024-05-01")
+	assert.Len(t, readRows[tickRow](t, filepath.Join(partition, "ticks-2024050110-*.parquet")), 1)
+	assert.Len(t, readRows[tickRow](t, filepath.Join(partition, "ticks-2024050111-*.parquet")), 1)
+	assert.Len(t, readRows[orderRow](t, filepath.Join(partition, "orders-*.parquet")), 1)
+}
+
+func Test_partition(t *testing.T) {
+	assert.Equal(t, "swaps/unknown/2024-05-01/10", partition(DefaultLayout, Swaps, "", hour))
+	assert.Equal(t, "ticks/BTC_USD/2024-05-01/10", partition(DefaultLayout, Ticks, "BTC/USD", hour))
+}
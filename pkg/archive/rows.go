@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"database/sql"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// decimalScale of the DECIMAL(38,18) columns, the scale of the database columns
+const decimalScale = 18
+
+// tickRow is the parquet row of a ticker, times are unix nanoseconds
+type tickRow struct {
+	Timestamp  int64   `parquet:"name=timestamp, type=INT64"`
+	ReceivedAt int64   `parquet:"name=received_at, type=INT64"`
+	Symbol     string  `parquet:"name=symbol, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Sequence   int64   `parquet:"name=sequence, type=INT64"`
+	Price      *string `parquet:"name=price, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	LastSize   *string `parquet:"name=last_size, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	Side       *string `parquet:"name=side, type=UTF8, encoding=PLAIN_DICTIONARY, repetitiontype=OPTIONAL"`
+	TradeID    *int64  `parquet:"name=trade_id, type=INT64, repetitiontype=OPTIONAL"`
+	Bid        string  `parquet:"name=bid, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	BidSize    *string `parquet:"name=bid_size, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	Ask        string  `parquet:"name=ask, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	AskSize    *string `parquet:"name=ask_size, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	Open24h    *string `parquet:"name=open_24h, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	High24h    *string `parquet:"name=high_24h, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	Low24h     *string `parquet:"name=low_24h, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+	Volume24h  *string `parquet:"name=volume_24h, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"`
+}
+
+func newTickRow(t entity.Ticker) tickRow {
+	return tickRow{
+		Timestamp:  t.Timestamp,
+		ReceivedAt: t.ReceivedAt,
+		Symbol:     t.Symbol,
+		Sequence:   t.Sequence,
+		Price:      nullDecimalBytes(t.Price),
+		LastSize:   nullDecimalBytes(t.LastSize),
+		Side:       nullString(t.Side),
+		TradeID:    nullInt64(t.TradeID),
+		Bid:        decimalBytes(t.Bid),
+		BidSize:    nullDecimalBytes(t.BidSize),
+		Ask:        decimalBytes(t.Ask),
+		AskSize:    nullDecimalBytes(t.AskSize),
+		Open24h:    nullDecimalBytes(t.Open24h),
+		High24h:    nullDecimalBytes(t.High24h),
+		Low24h:     nullDecimalBytes(t.Low24h),
+		Volume24h:  nullDecimalBytes(t.Volume24h),
+	}
+}
+
+// orderRow is the parquet row of an order, times are unix nanoseconds
+type orderRow struct {
+	Timestamp     int64   `parquet:"name=timestamp, type=INT64"`
+	ProductID     string  `parquet:"name=product_id, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Type          string  `parquet:"name=type, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	OrderID       string  `parquet:"name=order_id, type=UTF8"`
+	Funds         string  `parquet:"name=funds, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	Side          string  `parquet:"name=side, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Size          string  `parquet:"name=size, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	Price         string  `parquet:"name=price, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	OrderType     string  `parquet:"name=order_type, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClientOID     string  `parquet:"name=client_oid, type=UTF8"`
+	Sequence      int64   `parquet:"name=sequence, type=INT64"`
+	RemainingSize string  `parquet:"name=remaining_size, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"`
+	Reason        string  `parquet:"name=reason, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	TradeID       int64   `parquet:"name=trade_id, type=INT64"`
+	MakerOrderID  string  `parquet:"name=maker_order_id, type=UTF8"`
+	TakerOrderID  string  `parquet:"name=taker_order_id, type=UTF8"`
+	USDValue      *string `parquet:"name=usd_value, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16, repetitiontype=OPTIONAL"` // null without a rate
+}
+
+func newOrderRow(o entity.Order) orderRow {
+	return orderRow{
+		Timestamp:     o.Timestamp,
+		ProductID:     o.ProductID,
+		Type:          o.Type,
+		OrderID:       o.OrderID,
+		Funds:         decimalBytes(o.Funds),
+		Side:          o.Side,
+		Size:          decimalBytes(o.Size),
+		Price:         decimalBytes(o.Price),
+		OrderType:     o.OrderType,
+		ClientOID:     o.ClientOID,
+		Sequence:      int64(o.Sequence),
+		RemainingSize: decimalBytes(o.RemainingSize),
+		Reason:        o.Reason,
+		TradeID:       o.TradeID,
+		MakerOrderID:  o.MakerOrderID,
+		TakerOrderID:  o.TakerOrderID,
+		USDValue:      nullDecimalBytes(o.USDValue),
+	}
+}
+
+// swapRow is the parquet row of a swap, amounts are the raw token amounts of the transaction
+type swapRow struct {
+	Timestamp          int64  `parquet:"name=timestamp, type=INT64"` // time the swap was archived, unix nano
+	TxHash             string `parquet:"name=tx_hash, type=UTF8"`
+	Version            string `parquet:"name=version, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Exchange           string `parquet:"name=exchange, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value              string `parquet:"name=value, type=DECIMAL, scale=18, precision=38, basetype=FIXED_LEN_BYTE_ARRAY, length=16"` // USD
+	MethodID           string `parquet:"name=method_id, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	MethodName         string `parquet:"name=method_name, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress          string `parquet:"name=to_address, type=UTF8"`
+	TokenPathFrom      string `parquet:"name=token_path_from, type=UTF8"`
+	TokenPathTo        string `parquet:"name=token_path_to, type=UTF8"`
+	AmountIn           string `parquet:"name=amount_in, type=UTF8"`
+	AmountOutMin       string `parquet:"name=amount_out_min, type=UTF8"`
+	AmountTokenDesired string `parquet:"name=amount_token_desired, type=UTF8"`
+	AmountTokenMin     string `parquet:"name=amount_token_min, type=UTF8"`
+	AmountETHMin       string `parquet:"name=amount_eth_min, type=UTF8"`
+	TokenA             string `parquet:"name=token_a, type=UTF8"`
+	TokenB             string `parquet:"name=token_b, type=UTF8"`
+	AmountADesired     string `parquet:"name=amount_a_desired, type=UTF8"`
+	AmountBDesired     string `parquet:"name=amount_b_desired, type=UTF8"`
+	AmountAMin         string `parquet:"name=amount_a_min, type=UTF8"`
+	AmountBMin         string `parquet:"name=amount_b_min, type=UTF8"`
+	Liquidity          string `parquet:"name=liquidity, type=UTF8"`
+}
+
+func newSwapRow(s entity.SwapTransaction, archivedAt int64) swapRow {
+	return swapRow{
+		Timestamp:          archivedAt,
+		TxHash:             s.TxHash,
+		Version:            s.Version,
+		Exchange:           s.Exchange,
+		Value:              decimalBytes(s.Value),
+		MethodID:           s.MethodID,
+		MethodName:         s.MethodName,
+		ToAddress:          s.ToAddress,
+		TokenPathFrom:      s.TokenPathFrom,
+		TokenPathTo:        s.TokenPathTo,
+		AmountIn:           s.AmountIn,
+		AmountOutMin:       s.AmountOutMin,
+		AmountTokenDesired: s.AmountTokenDesired,
+		AmountTokenMin:     s.AmountTokenMin,
+		AmountETHMin:       s.AmountETHMin,
+		TokenA:             s.TokenA,
+		TokenB:             s.TokenB,
+		AmountADesired:     s.AmountADesired,
+		AmountBDesired:     s.AmountBDesired,
+		AmountAMin:         s.AmountAMin,
+		AmountBMin:         s.AmountBMin,
+		Liquidity:          s.Liquidity,
+	}
+}
+
+// decimalBytes encodes d as the 16 bytes big endian unscaled value of a DECIMAL(38,18),
+// digits past the scale are truncated like the database columns round them
+func decimalBytes(d decimal.Decimal) string {
+	return types.StrIntToBinary(d.Shift(decimalScale).Truncate(0).String(), "BigEndian", 16, true)
+}
+
+// nullDecimalBytes encodes d like decimalBytes, nil when it is null
+func nullDecimalBytes(d decimal.NullDecimal) *string {
+	if !d.Valid {
+		return nil
+	}
+	b := decimalBytes(d.Decimal)
+
+	return &b
+}
+
+// nullString is the string of s, nil when it is null
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+
+	return &s.String
+}
+
+// nullInt64 is the integer of i, nil when it is null
+func nullInt64(i sql.NullInt64) *int64 {
+	if !i.Valid {
+		return nil
+	}
+
+	return &i.Int64
+}
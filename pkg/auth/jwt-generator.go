@@ -1,44 +1,35 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"math"
 	"math/big"
-	"os"
 	"time"
+	"github.com/nel349/bz-findata/pkg/secrets"
 	"gopkg.in/go-jose/go-jose.v2"
 	"gopkg.in/go-jose/go-jose.v2/jwt"
 	// log "github.com/sirupsen/logrus"
 )
 
-var (
-	keyName   string
-	keySecret string
-)
-
-func init() {
-	keyName = os.Getenv("COINBASE_TRADING_KEY_NAME")
-	keySecret = os.Getenv("COINBASE_TRADING_PRIVATE_KEY")
-
-	if keyName == "" {
-		fmt.Println("Warning: COINBASE_TRADING_KEY_NAME is not set")
-	}
-	if keySecret == "" {
-		fmt.Println("Warning: COINBASE_TRADING_PRIVATE_KEY is not set")
-	}
-}
-
 type APIKeyClaims struct {
 	*jwt.Claims
-	URI string `json:"uri"`
+	URI string `json:"uri,omitempty"` // unset for websocket subscriptions
 }
 
-func BuildJWT(uri string) (string, error) {
-	// fmt.Println("keyName:", keyName)
-	// fmt.Println("keySecret:", keySecret)
+// BuildJWT signs a JWT of the REST request uri ("GET api.coinbase.com/api/v3/brokerage/accounts"),
+// or of a websocket subscription without uri, with the trading key of the default secret provider,
+// COINBASE_TRADING_KEY_NAME and COINBASE_TRADING_PRIVATE_KEY
+func BuildJWT(ctx context.Context, uri string) (string, error) {
+	keys, err := secrets.GetAll(ctx, "COINBASE_TRADING_KEY_NAME", "COINBASE_TRADING_PRIVATE_KEY")
+	if err != nil {
+		return "", fmt.Errorf("jwt: %w", err)
+	}
+	keyName, keySecret := keys[0], keys[1]
+
 	block, _ := pem.Decode([]byte(keySecret))
 	if block == nil {
 		return "", fmt.Errorf("jwt: Could not decode private key")
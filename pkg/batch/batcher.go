@@ -0,0 +1,194 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Add once the batcher stopped
+var ErrClosed = errors.New("batcher closed")
+
+// Flush writes a batch of items, it must be safe to retry a failed batch
+type Flush[T any] func(ctx context.Context, items []T) error
+
+// Failed receives a batch that could not be written after every retry
+type Failed[T any] func(items []T, err error)
+
+// Options of a batcher
+type Options struct {
+	// Size flushes once that many items are buffered
+	Size int
+	// Interval flushes buffered items at least that often
+	Interval time.Duration
+	// MaxPending bounds the items queued while a batch is written, Add blocks above it
+	MaxPending int
+	// Retries of a failed batch, RetryDelay doubles between them
+	Retries    int
+	RetryDelay time.Duration
+	// Timeout of a single flush
+	Timeout time.Duration
+}
+
+// DefaultOptions flush 500 items or every second
+func DefaultOptions() Options {
+	return Options{
+		Size:       500,
+		Interval:   time.Second,
+		MaxPending: 50000,
+		Retries:    3,
+		RetryDelay: 500 * time.Millisecond,
+		Timeout:    5 * time.Second,
+	}
+}
+
+// Batcher buffers items and writes them in batches from Run
+type Batcher[T any] struct {
+	opts   Options
+	flush  Flush[T]
+	failed Failed[T]
+	queue  chan T
+	closed chan struct{}
+
+	// mu is held by Add while it queues, Run takes it to stop the queueing before draining
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewBatcher init batcher writing with flush, failed may be nil
+func NewBatcher[T any](opts Options, flush Flush[T], failed Failed[T]) *Batcher[T] {
+	defaults := DefaultOptions()
+	if opts.Size <= 0 {
+		opts.Size = defaults.Size
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaults.Interval
+	}
+	if opts.MaxPending < opts.Size {
+		opts.MaxPending = opts.Size
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if failed == nil {
+		failed = func([]T, error) {}
+	}
+
+	return &Batcher[T]{
+		opts:   opts,
+		flush:  flush,
+		failed: failed,
+		queue:  make(chan T, opts.MaxPending),
+		closed: make(chan struct{}),
+	}
+}
+
+// Add queues item, blocking while MaxPending items wait to be written
+func (b *Batcher[T]) Add(ctx context.Context, item T) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.stopped {
+		return ErrClosed
+	}
+
+	select {
+	case b.queue <- item:
+		return nil
+	case <-b.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of queued items
+func (b *Batcher[T]) Pending() int {
+	return len(b.queue)
+}
+
+// Run writes batches until ctx is done, then flushes what is left
+func (b *Batcher[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+
+	items := make([]T, 0, b.opts.Size)
+
+	for {
+		select {
+		case item := <-b.queue:
+			items = append(items, item)
+			if len(items) >= b.opts.Size {
+				b.write(items)
+				items = items[:0]
+			}
+		case <-ticker.C:
+			if len(items) > 0 {
+				b.write(items)
+				items = items[:0]
+			}
+		case <-ctx.Done():
+			// wake the blocked Adds, then wait for those queueing so drain sees every item
+			close(b.closed)
+			b.mu.Lock()
+			b.stopped = true
+			b.mu.Unlock()
+			return b.drain(items)
+		}
+	}
+}
+
+// drain flushes the buffered and queued items on shutdown
+func (b *Batcher[T]) drain(items []T) error {
+	var failed int
+
+	for len(items) > 0 || len(b.queue) > 0 {
+		for len(items) < b.opts.Size && len(b.queue) > 0 {
+			items = append(items, <-b.queue)
+		}
+
+		if err := b.write(items); err != nil {
+			failed += len(items)
+		}
+		items = items[:0]
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to flush %d items on shutdown", failed)
+	}
+
+	return nil
+}
+
+// write flushes a batch with retries, the batch is handed to failed if every attempt fails
+func (b *Batcher[T]) write(items []T) error {
+	// items is reused by the caller
+	batch := make([]T, len(items))
+	copy(batch, items)
+
+	delay := b.opts.RetryDelay
+
+	var err error
+	for attempt := 0; attempt <= b.opts.Retries; attempt++ {
+		if attempt > 0 && delay > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.opts.Timeout)
+		err = b.flush(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+
+	b.failed(batch, err)
+
+	return err
+}
@@ -0,0 +1,179 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder keeps the flushed batches, failing the first fails flushes
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	fails   int
+	calls   int
+}
+
+func (r *recorder) flush(_ context.Context, items []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+	if r.fails > 0 {
+		r.fails--
+		return errors.New("unavailable")
+	}
+	r.batches = append(r.batches, append([]int(nil), items...))
+	return nil
+}
+
+func (r *recorder) flushed() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.batches
+}
+
+func run(ctx context.Context, b *Batcher[int]) chan error {
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+	return done
+}
+
+func TestBatcher_FlushOnSize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &recorder{}
+	b := NewBatcher(Options{Size: 3, Interval: time.Hour}, r.flush, nil)
+	done := run(ctx, b)
+
+	for i := 1; i <= 7; i++ {
+		require.NoError(t, b.Add(ctx, i))
+	}
+
+	assert.Eventually(t, func() bool { return len(r.flushed()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}}, r.flushed())
+
+	// the rest is flushed on shutdown
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, r.flushed())
+
+	assert.ErrorIs(t, b.Add(context.Background(), 8), ErrClosed)
+}
+
+func TestBatcher_FlushOnInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &recorder{}
+	b := NewBatcher(Options{Size: 100, Interval: 10 * time.Millisecond}, r.flush, nil)
+	done := run(ctx, b)
+
+	require.NoError(t, b.Add(ctx, 1))
+	require.NoError(t, b.Add(ctx, 2))
+
+	assert.Eventually(t, func() bool { return len(r.flushed()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]int{{1, 2}}, r.flushed())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestBatcher_Retry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &recorder{fails: 2}
+	b := NewBatcher(Options{Size: 2, Interval: time.Hour, Retries: 2, RetryDelay: time.Millisecond}, r.flush, nil)
+	done := run(ctx, b)
+
+	require.NoError(t, b.Add(ctx, 1))
+	require.NoError(t, b.Add(ctx, 2))
+
+	assert.Eventually(t, func() bool { return len(r.flushed()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, [][]int{{1, 2}}, r.flushed())
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, 3, r.calls)
+}
+
+func TestBatcher_Failed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var failed []int
+	r := &recorder{fails: 10}
+	b := NewBatcher(Options{Size: 10, Interval: time.Hour, Retries: 1}, r.flush, func(items []int, err error) {
+		failed = append(failed, items...)
+	})
+	done := run(ctx, b)
+
+	require.NoError(t, b.Add(ctx, 1))
+	require.NoError(t, b.Add(ctx, 2))
+
+	cancel()
+	assert.Error(t, <-done)
+	assert.Equal(t, []int{1, 2}, failed)
+	assert.Equal(t, 2, r.calls)
+	assert.Empty(t, r.flushed())
+}
+
+func TestBatcher_Bounded(t *testing.T) {
+	// not running, nothing is written
+	b := NewBatcher(Options{Size: 2, MaxPending: 2}, (&recorder{}).flush, nil)
+
+	require.NoError(t, b.Add(context.Background(), 1))
+	require.NoError(t, b.Add(context.Background(), 2))
+	assert.Equal(t, 2, b.Pending())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Add(ctx, 3), context.DeadlineExceeded)
+}
+
+func TestBatcher_AddWhileClosing(t *testing.T) {
+	r := &recorder{}
+	b := NewBatcher(Options{Size: 10, MaxPending: 10, Interval: time.Hour}, r.flush, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				if err := b.Add(context.Background(), j); err != nil {
+					assert.ErrorIs(t, err, ErrClosed)
+					return
+				}
+				mu.Lock()
+				added++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	wg.Wait()
+
+	// every accepted item is written
+	flushed := 0
+	for _, batch := range r.flushed() {
+		flushed += len(batch)
+	}
+	assert.Equal(t, added, flushed)
+	assert.ErrorIs(t, b.Add(context.Background(), 1), ErrClosed)
+}
@@ -0,0 +1,54 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Protocols of the brokers
+const (
+	NATS      = "nats"
+	Kafka     = "kafka"      // Kafka protocol to the brokers of the cluster
+	KafkaREST = "kafka-rest" // through a Confluent REST Proxy
+	Embedded  = "embedded"   // in-process, for tests and local runs
+)
+
+// Message is an encoded event on its topic, Key orders the messages of a Kafka partition
+type Message struct {
+	Topic       string
+	Key         string
+	ContentType string
+	Payload     []byte
+}
+
+// Broker delivers messages to a message bus
+type Broker interface {
+	// Publish sends msg to its topic
+	Publish(ctx context.Context, msg Message) error
+	// Close flushes what is pending and disconnects
+	Close() error
+}
+
+// BatchBroker is a broker sending many messages of a topic at once
+type BatchBroker interface {
+	Broker
+	// PublishBatch sends msgs, all of one topic, in order
+	PublishBatch(ctx context.Context, msgs []Message) error
+}
+
+// NewBroker connects to the broker of protocol at url
+func NewBroker(protocol, url string, timeout time.Duration) (Broker, error) {
+	switch protocol {
+	case NATS:
+		return NewNATSBroker(url, timeout)
+	case Kafka:
+		return NewKafkaBroker(url, timeout)
+	case KafkaREST:
+		return NewKafkaRESTBroker(url, timeout)
+	case Embedded:
+		return NewEmbeddedBroker(), nil
+	default:
+		return nil, fmt.Errorf("unknown bus protocol %q, want nats, kafka, kafka-rest or embedded", protocol)
+	}
+}
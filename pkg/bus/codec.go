@@ -0,0 +1,268 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nel349/bz-findata/pkg/bus/buspb"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/nel349/bz-findata/pkg/bus event.proto
+
+// Format of the published events
+type Format string
+
+// Formats of the events
+const (
+	JSON     Format = "json"
+	Protobuf Format = "protobuf" // the BusEvent message of event.proto
+)
+
+// ParseFormat parses json or protobuf, empty is json
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", JSON:
+		return JSON, nil
+	case Protobuf:
+		return Protobuf, nil
+	default:
+		return "", fmt.Errorf("unknown event format %q, want json or protobuf", s)
+	}
+}
+
+// ContentType of the events of the format
+func (f Format) ContentType() string {
+	if f == Protobuf {
+		return "application/x-protobuf"
+	}
+	return "application/json"
+}
+
+// Encode encodes event in format
+func Encode(format Format, event Event) ([]byte, error) {
+	if format == Protobuf {
+		return proto.Marshal(toProto(event))
+	}
+	return json.Marshal(event)
+}
+
+// Decode decodes an event encoded in format, for consumers and tests
+func Decode(format Format, b []byte) (Event, error) {
+	if format == Protobuf {
+		var event buspb.BusEvent
+		if err := proto.Unmarshal(b, &event); err != nil {
+			return Event{}, err
+		}
+		return fromProto(&event), nil
+	}
+
+	var event Event
+	return event, json.Unmarshal(b, &event)
+}
+
+// toProto is the BusEvent message of event
+func toProto(e Event) *buspb.BusEvent {
+	event := &buspb.BusEvent{Version: int32(e.Version), Type: e.Type, Source: e.Source, Time: e.Time}
+
+	switch {
+	case e.Ticker != nil:
+		t := e.Ticker
+		event.Payload = &buspb.BusEvent_Ticker{Ticker: &buspb.Ticker{
+			Time:       t.Time,
+			ReceivedAt: t.ReceivedAt,
+			Symbol:     t.Symbol,
+			Sequence:   t.Sequence,
+			Price:      t.Price,
+			LastSize:   t.LastSize,
+			Side:       t.Side,
+			TradeId:    t.TradeID,
+			Bid:        t.Bid,
+			BidSize:    t.BidSize,
+			Ask:        t.Ask,
+			AskSize:    t.AskSize,
+			Open_24H:   t.Open24h,
+			High_24H:   t.High24h,
+			Low_24H:    t.Low24h,
+			Volume_24H: t.Volume24h,
+		}}
+	case e.Order != nil:
+		o := e.Order
+		event.Payload = &buspb.BusEvent_Order{Order: &buspb.Order{
+			Type:          o.Type,
+			Time:          o.Time,
+			ProductId:     o.ProductID,
+			OrderId:       o.OrderID,
+			Funds:         o.Funds,
+			Side:          o.Side,
+			Size:          o.Size,
+			Price:         o.Price,
+			OrderType:     o.OrderType,
+			ClientOid:     o.ClientOID,
+			Sequence:      o.Sequence,
+			RemainingSize: o.RemainingSize,
+			Reason:        o.Reason,
+			TradeId:       o.TradeID,
+			MakerOrderId:  o.MakerOrderID,
+			TakerOrderId:  o.TakerOrderID,
+			NewSize:       o.NewSize,
+			OldSize:       o.OldSize,
+			UsdValue:      o.USDValue,
+		}}
+	case e.Heartbeat != nil:
+		h := e.Heartbeat
+		event.Payload = &buspb.BusEvent_Heartbeat{Heartbeat: &buspb.Heartbeat{
+			Sequence:    h.Sequence,
+			LastTradeId: h.LastTradeID,
+			ProductId:   h.ProductID,
+			Time:        h.Time,
+		}}
+	case e.Swap != nil:
+		s := e.Swap
+		event.Payload = &buspb.BusEvent_Swap{Swap: &buspb.Swap{
+			Value:              s.Value,
+			TxHash:             s.TxHash,
+			Version:            s.Version,
+			Exchange:           s.Exchange,
+			AmountIn:           s.AmountIn,
+			AmountOutMin:       s.AmountOutMin,
+			ToAddress:          s.ToAddress,
+			TokenPathFrom:      s.TokenPathFrom,
+			TokenPathTo:        s.TokenPathTo,
+			MethodId:           s.MethodID,
+			MethodName:         s.MethodName,
+			AmountTokenDesired: s.AmountTokenDesired,
+			AmountTokenMin:     s.AmountTokenMin,
+			AmountEthMin:       s.AmountETHMin,
+			TokenA:             s.TokenA,
+			TokenB:             s.TokenB,
+			AmountADesired:     s.AmountADesired,
+			AmountBDesired:     s.AmountBDesired,
+			Liquidity:          s.Liquidity,
+			AmountAMin:         s.AmountAMin,
+			AmountBMin:         s.AmountBMin,
+			AmountOut:          s.AmountOut,
+			AmountInMax:        s.AmountInMax,
+			Fee:                s.Fee,
+			NumberOfCalls:      int64(s.NumberOfCalls),
+			CallsData:          s.CallsData,
+		}}
+	case e.Candle != nil:
+		c := e.Candle
+		event.Payload = &buspb.BusEvent_Candle{Candle: &buspb.Candle{
+			ProductId:   c.ProductID,
+			Granularity: c.Granularity,
+			Time:        c.Time,
+			Open:        c.Open,
+			High:        c.High,
+			Low:         c.Low,
+			Close:       c.Close,
+			Volume:      c.Volume,
+		}}
+	}
+
+	return event
+}
+
+// fromProto is the event of a BusEvent message, payloads unknown to this version are left out
+func fromProto(e *buspb.BusEvent) Event {
+	event := Event{Version: int(e.Version), Type: e.Type, Source: e.Source, Time: e.Time}
+
+	switch p := e.Payload.(type) {
+	case *buspb.BusEvent_Ticker:
+		t := p.Ticker
+		event.Ticker = &Ticker{
+			Time:       t.Time,
+			ReceivedAt: t.ReceivedAt,
+			Symbol:     t.Symbol,
+			Sequence:   t.Sequence,
+			Price:      t.Price,
+			LastSize:   t.LastSize,
+			Side:       t.Side,
+			TradeID:    t.TradeId,
+			Bid:        t.Bid,
+			BidSize:    t.BidSize,
+			Ask:        t.Ask,
+			AskSize:    t.AskSize,
+			Open24h:    t.Open_24H,
+			High24h:    t.High_24H,
+			Low24h:     t.Low_24H,
+			Volume24h:  t.Volume_24H,
+		}
+	case *buspb.BusEvent_Order:
+		o := p.Order
+		event.Order = &Order{
+			Type:          o.Type,
+			Time:          o.Time,
+			ProductID:     o.ProductId,
+			OrderID:       o.OrderId,
+			Funds:         o.Funds,
+			Side:          o.Side,
+			Size:          o.Size,
+			Price:         o.Price,
+			OrderType:     o.OrderType,
+			ClientOID:     o.ClientOid,
+			Sequence:      o.Sequence,
+			RemainingSize: o.RemainingSize,
+			Reason:        o.Reason,
+			TradeID:       o.TradeId,
+			MakerOrderID:  o.MakerOrderId,
+			TakerOrderID:  o.TakerOrderId,
+			NewSize:       o.NewSize,
+			OldSize:       o.OldSize,
+			USDValue:      o.UsdValue,
+		}
+	case *buspb.BusEvent_Heartbeat:
+		h := p.Heartbeat
+		event.Heartbeat = &Heartbeat{
+			Sequence:    h.Sequence,
+			LastTradeID: h.LastTradeId,
+			ProductID:   h.ProductId,
+			Time:        h.Time,
+		}
+	case *buspb.BusEvent_Swap:
+		s := p.Swap
+		event.Swap = &Swap{
+			Value:              s.Value,
+			TxHash:             s.TxHash,
+			Version:            s.Version,
+			Exchange:           s.Exchange,
+			AmountIn:           s.AmountIn,
+			AmountOutMin:       s.AmountOutMin,
+			ToAddress:          s.ToAddress,
+			TokenPathFrom:      s.TokenPathFrom,
+			TokenPathTo:        s.TokenPathTo,
+			MethodID:           s.MethodId,
+			MethodName:         s.MethodName,
+			AmountTokenDesired: s.AmountTokenDesired,
+			AmountTokenMin:     s.AmountTokenMin,
+			AmountETHMin:       s.AmountEthMin,
+			TokenA:             s.TokenA,
+			TokenB:             s.TokenB,
+			AmountADesired:     s.AmountADesired,
+			AmountBDesired:     s.AmountBDesired,
+			Liquidity:          s.Liquidity,
+			AmountAMin:         s.AmountAMin,
+			AmountBMin:         s.AmountBMin,
+			AmountOut:          s.AmountOut,
+			AmountInMax:        s.AmountInMax,
+			Fee:                s.Fee,
+			NumberOfCalls:      int(s.NumberOfCalls),
+			CallsData:          s.CallsData,
+		}
+	case *buspb.BusEvent_Candle:
+		c := p.Candle
+		event.Candle = &Candle{
+			ProductID:   c.ProductId,
+			Granularity: c.Granularity,
+			Time:        c.Time,
+			Open:        c.Open,
+			High:        c.High,
+			Low:         c.Low,
+			Close:       c.Close,
+			Volume:      c.Volume,
+		}
+	}
+
+	return event
+}
@@ -0,0 +1,91 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestEncode(t *testing.T) {
+	order, ok := NewMessageEvent("coinbase", entity.Message{Order: &entity.Order{
+		Type:      "match",
+		Timestamp: 1714557600000000000,
+		ProductID: "BTC-USD",
+		Size:      decimal.RequireFromString("-1.5"),
+		Price:     decimal.RequireFromString("64000.123456789"),
+		Sequence:  42,
+		USDValue:  decimal.NewNullDecimal(decimal.RequireFromString("96000.18")),
+	}})
+	require.True(t, ok)
+	heartbeat, ok := NewMessageEvent("coinbase", entity.Message{Heartbeat: &entity.Heartbeat{
+		Sequence: 7, ProductID: "ETH-USD", Time: time.Unix(0, 1714557600000000000),
+	}})
+	require.True(t, ok)
+	candle, ok := NewMessageEvent("coinbase", entity.Message{Candle: &entity.Candle{
+		ProductID: "BTC-USD", Granularity: 300, Timestamp: 1714557600000000000, Close: decimal.RequireFromString("64000.5"),
+	}})
+	require.True(t, ok)
+	// a ticker before any trade has no price
+	ticker, ok := NewMessageEvent("coinbase", entity.Message{Ticker: &entity.Ticker{
+		Symbol: "BTC-USD", Bid: decimal.RequireFromString("64000"), BidSize: decimal.NewNullDecimal(decimal.RequireFromString("0.5")),
+	}})
+	require.True(t, ok)
+	swap := NewSwapEvent("ethereum", entity.SwapTransaction{
+		TxHash: "0xabc", Exchange: "uniswap", Value: decimal.RequireFromString("1200.5"), CallsData: []string{"0x01", "0x02"},
+	}, 1714557600000000000)
+
+	for _, format := range []Format{JSON, Protobuf} {
+		for _, event := range []Event{ticker, order, heartbeat, candle, swap} {
+			b, err := Encode(format, event)
+			require.NoError(t, err)
+
+			decoded, err := Decode(format, b)
+			require.NoError(t, err)
+			assert.Equal(t, event, decoded, "%s %s", format, event.Type)
+		}
+	}
+
+	assert.Equal(t, "-1.5", order.Order.Size)
+	assert.Equal(t, "96000.18", *order.Order.USDValue)
+	assert.Nil(t, ticker.Ticker.Price)
+	assert.Nil(t, ticker.Ticker.Side)
+	assert.Nil(t, ticker.Ticker.TradeID)
+	assert.Equal(t, "BTC-USD", candle.Key())
+	_, ok = NewMessageEvent("coinbase", entity.Message{Book: &entity.BookUpdate{}})
+	assert.False(t, ok)
+}
+
+func TestEncode_protobufWire(t *testing.T) {
+	b, err := Encode(Protobuf, Event{Version: Version, Type: TickerEvent, Ticker: &Ticker{Symbol: "BTC-USD"}})
+	require.NoError(t, err)
+
+	// version = 1, type = 2, ticker = 10 { symbol = 3 }
+	want := protowire.AppendTag(nil, 1, protowire.VarintType)
+	want = protowire.AppendVarint(want, Version)
+	want = protowire.AppendTag(want, 2, protowire.BytesType)
+	want = protowire.AppendString(want, TickerEvent)
+	ticker := protowire.AppendTag(nil, 3, protowire.BytesType)
+	ticker = protowire.AppendString(ticker, "BTC-USD")
+	want = protowire.AppendTag(want, 10, protowire.BytesType)
+	want = protowire.AppendBytes(want, ticker)
+
+	assert.Equal(t, want, b)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, JSON, f)
+
+	f, err = ParseFormat("protobuf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-protobuf", f.ContentType())
+
+	_, err = ParseFormat("avro")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package bus
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// EmbeddedBroker is an in-process broker, subscribers get the messages of the topics
+// matching their pattern
+type EmbeddedBroker struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+}
+
+type subscription struct {
+	pattern string
+	ch      chan Message
+	// done is closed by unsubscribe to release the publishers blocked on ch, mu keeps
+	// ch open until they return
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewEmbeddedBroker creates an in-process broker
+func NewEmbeddedBroker() *EmbeddedBroker {
+	return &EmbeddedBroker{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe gets the messages of the topics matching pattern, NATS wildcards included:
+// * matches a token and > the remaining ones. A full buffer blocks the publisher,
+// unsubscribe closes the channel
+func (b *EmbeddedBroker) Subscribe(pattern string, buffer int) (<-chan Message, func()) {
+	sub := &subscription{pattern: pattern, ch: make(chan Message, buffer), done: make(chan struct{})}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+
+			close(sub.done)
+			sub.mu.Lock()
+			sub.closed = true
+			close(sub.ch)
+			sub.mu.Unlock()
+		})
+	}
+}
+
+// Publish sends msg to the matching subscriptions, the broker is not locked while it waits
+// on a full buffer so a subscription can end meanwhile
+func (b *EmbeddedBroker) Publish(ctx context.Context, msg Message) error {
+	var subs []*subscription
+	b.mu.RLock()
+	for sub := range b.subs {
+		if match(sub.pattern, msg.Topic) {
+			subs = append(subs, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if err := sub.send(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send waits until msg is buffered, the subscription ends or ctx is done
+func (s *subscription) send(ctx context.Context, msg Message) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.ch <- msg:
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+// Close keeps the subscriptions, they end with unsubscribe
+func (b *EmbeddedBroker) Close() error {
+	return nil
+}
+
+// match reports whether the dot separated topic matches pattern
+func match(pattern, topic string) bool {
+	p, t := strings.Split(pattern, "."), strings.Split(topic, ".")
+	for i, token := range p {
+		if token == ">" {
+			return len(t) > i
+		}
+		if i >= len(t) || (token != "*" && token != t[i]) {
+			return false
+		}
+	}
+
+	return len(p) == len(t)
+}
@@ -0,0 +1,278 @@
+package bus
+
+import (
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Version of the event schema, bumped on breaking changes only
+const Version = 1
+
+// Types of the events
+const (
+	TickerEvent    = "ticker"
+	OrderEvent     = "order"
+	HeartbeatEvent = "heartbeat"
+	SwapEvent      = "swap"
+	CandleEvent    = "candle"
+)
+
+// Event is the envelope of every event published, exactly one payload is set.
+// Decimals are strings so no precision is lost, times are unix nanos.
+// It is encoded as the BusEvent message of event.proto in protobuf
+type Event struct {
+	Version   int        `json:"version"`
+	Type      string     `json:"type"`
+	Source    string     `json:"source"` // exchange or chain
+	Time      int64      `json:"time"`   // time of the event, the archive time for swaps
+	Ticker    *Ticker    `json:"ticker,omitempty"`
+	Order     *Order     `json:"order,omitempty"`
+	Heartbeat *Heartbeat `json:"heartbeat,omitempty"`
+	Swap      *Swap      `json:"swap,omitempty"`
+	Candle    *Candle    `json:"candle,omitempty"`
+}
+
+// Ticker payload, the optional fields are nil when the exchange doesn't send them
+type Ticker struct {
+	Time       int64   `json:"time"`
+	ReceivedAt int64   `json:"received_at"`
+	Symbol     string  `json:"symbol"`
+	Sequence   int64   `json:"sequence"`
+	Price      *string `json:"price"`
+	LastSize   *string `json:"last_size"`
+	Side       *string `json:"side"`
+	TradeID    *int64  `json:"trade_id"`
+	Bid        string  `json:"bid"`
+	BidSize    *string `json:"bid_size"`
+	Ask        string  `json:"ask"`
+	AskSize    *string `json:"ask_size"`
+	Open24h    *string `json:"open_24h"`
+	High24h    *string `json:"high_24h"`
+	Low24h     *string `json:"low_24h"`
+	Volume24h  *string `json:"volume_24h"`
+}
+
+// Order payload, USDValue is nil without a USD rate
+type Order struct {
+	Type          string  `json:"type"`
+	Time          int64   `json:"time"`
+	ProductID     string  `json:"product_id"`
+	OrderID       string  `json:"order_id,omitempty"`
+	Funds         string  `json:"funds"`
+	Side          string  `json:"side"`
+	Size          string  `json:"size"`
+	Price         string  `json:"price"`
+	OrderType     string  `json:"order_type,omitempty"`
+	ClientOID     string  `json:"client_oid,omitempty"`
+	Sequence      int64   `json:"sequence"`
+	RemainingSize string  `json:"remaining_size"`
+	Reason        string  `json:"reason,omitempty"`
+	TradeID       int64   `json:"trade_id,omitempty"`
+	MakerOrderID  string  `json:"maker_order_id,omitempty"`
+	TakerOrderID  string  `json:"taker_order_id,omitempty"`
+	NewSize       string  `json:"new_size"`
+	OldSize       string  `json:"old_size"`
+	USDValue      *string `json:"usd_value"`
+}
+
+// Heartbeat payload
+type Heartbeat struct {
+	Sequence    int64  `json:"sequence"`
+	LastTradeID int64  `json:"last_trade_id"`
+	ProductID   string `json:"product_id"`
+	Time        int64  `json:"time"`
+}
+
+// Candle payload of a bar computed by the exchange
+type Candle struct {
+	ProductID   string `json:"product_id"`
+	Granularity int64  `json:"granularity"` // seconds
+	Time        int64  `json:"time"`        // bar start
+	Open        string `json:"open"`
+	High        string `json:"high"`
+	Low         string `json:"low"`
+	Close       string `json:"close"`
+	Volume      string `json:"volume"`
+}
+
+// Swap payload
+type Swap struct {
+	Value              string   `json:"value"` // USD
+	TxHash             string   `json:"tx_hash"`
+	Version            string   `json:"version"`
+	Exchange           string   `json:"exchange"`
+	AmountIn           string   `json:"amount_in,omitempty"`
+	AmountOutMin       string   `json:"amount_out_min,omitempty"`
+	ToAddress          string   `json:"to_address,omitempty"`
+	TokenPathFrom      string   `json:"token_path_from,omitempty"`
+	TokenPathTo        string   `json:"token_path_to,omitempty"`
+	MethodID           string   `json:"method_id"`
+	MethodName         string   `json:"method_name"`
+	AmountTokenDesired string   `json:"amount_token_desired,omitempty"`
+	AmountTokenMin     string   `json:"amount_token_min,omitempty"`
+	AmountETHMin       string   `json:"amount_eth_min,omitempty"`
+	TokenA             string   `json:"token_a,omitempty"`
+	TokenB             string   `json:"token_b,omitempty"`
+	AmountADesired     string   `json:"amount_a_desired,omitempty"`
+	AmountBDesired     string   `json:"amount_b_desired,omitempty"`
+	Liquidity          string   `json:"liquidity,omitempty"`
+	AmountAMin         string   `json:"amount_a_min,omitempty"`
+	AmountBMin         string   `json:"amount_b_min,omitempty"`
+	AmountOut          string   `json:"amount_out,omitempty"`
+	AmountInMax        string   `json:"amount_in_max,omitempty"`
+	Fee                string   `json:"fee,omitempty"`
+	NumberOfCalls      int      `json:"number_of_calls,omitempty"`
+	CallsData          []string `json:"calls_data,omitempty"`
+}
+
+// NewMessageEvent is the event of a ticker, order, heartbeat or candle message of source,
+// false for other messages
+func NewMessageEvent(source string, message entity.Message) (Event, bool) {
+	event := Event{Version: Version, Source: source}
+
+	switch {
+	case message.Ticker != nil:
+		t := message.Ticker
+		event.Type, event.Time = TickerEvent, t.Timestamp
+		event.Ticker = &Ticker{
+			Time:       t.Timestamp,
+			ReceivedAt: t.ReceivedAt,
+			Symbol:     t.Symbol,
+			Sequence:   t.Sequence,
+			Price:      nullString(t.Price),
+			LastSize:   nullString(t.LastSize),
+			Side:       nullValue(t.Side.String, t.Side.Valid),
+			TradeID:    nullValue(t.TradeID.Int64, t.TradeID.Valid),
+			Bid:        t.Bid.String(),
+			BidSize:    nullString(t.BidSize),
+			Ask:        t.Ask.String(),
+			AskSize:    nullString(t.AskSize),
+			Open24h:    nullString(t.Open24h),
+			High24h:    nullString(t.High24h),
+			Low24h:     nullString(t.Low24h),
+			Volume24h:  nullString(t.Volume24h),
+		}
+	case message.Order != nil:
+		o := message.Order
+		event.Type, event.Time = OrderEvent, o.Timestamp
+		event.Order = &Order{
+			Type:          o.Type,
+			Time:          o.Timestamp,
+			ProductID:     o.ProductID,
+			OrderID:       o.OrderID,
+			Funds:         o.Funds.String(),
+			Side:          o.Side,
+			Size:          o.Size.String(),
+			Price:         o.Price.String(),
+			OrderType:     o.OrderType,
+			ClientOID:     o.ClientOID,
+			Sequence:      int64(o.Sequence),
+			RemainingSize: o.RemainingSize.String(),
+			Reason:        o.Reason,
+			TradeID:       o.TradeID,
+			MakerOrderID:  o.MakerOrderID,
+			TakerOrderID:  o.TakerOrderID,
+			NewSize:       o.NewSize.String(),
+			OldSize:       o.OldSize.String(),
+			USDValue:      nullString(o.USDValue),
+		}
+	case message.Heartbeat != nil:
+		h := message.Heartbeat
+		event.Type, event.Time = HeartbeatEvent, h.Time.UnixNano()
+		event.Heartbeat = &Heartbeat{
+			Sequence:    h.Sequence,
+			LastTradeID: h.LastTradeID,
+			ProductID:   h.ProductID,
+			Time:        h.Time.UnixNano(),
+		}
+	case message.Candle != nil:
+		c := message.Candle
+		event.Type, event.Time = CandleEvent, c.Timestamp
+		event.Candle = &Candle{
+			ProductID:   c.ProductID,
+			Granularity: c.Granularity,
+			Time:        c.Timestamp,
+			Open:        c.Open.String(),
+			High:        c.High.String(),
+			Low:         c.Low.String(),
+			Close:       c.Close.String(),
+			Volume:      c.Volume.String(),
+		}
+	default:
+		return Event{}, false
+	}
+
+	return event, true
+}
+
+// NewSwapEvent is the event of a swap decoded on chain at time
+func NewSwapEvent(chain string, s entity.SwapTransaction, time int64) Event {
+	return Event{
+		Version: Version,
+		Type:    SwapEvent,
+		Source:  chain,
+		Time:    time,
+		Swap: &Swap{
+			Value:              s.Value.String(),
+			TxHash:             s.TxHash,
+			Version:            s.Version,
+			Exchange:           s.Exchange,
+			AmountIn:           s.AmountIn,
+			AmountOutMin:       s.AmountOutMin,
+			ToAddress:          s.ToAddress,
+			TokenPathFrom:      s.TokenPathFrom,
+			TokenPathTo:        s.TokenPathTo,
+			MethodID:           s.MethodID,
+			MethodName:         s.MethodName,
+			AmountTokenDesired: s.AmountTokenDesired,
+			AmountTokenMin:     s.AmountTokenMin,
+			AmountETHMin:       s.AmountETHMin,
+			TokenA:             s.TokenA,
+			TokenB:             s.TokenB,
+			AmountADesired:     s.AmountADesired,
+			AmountBDesired:     s.AmountBDesired,
+			Liquidity:          s.Liquidity,
+			AmountAMin:         s.AmountAMin,
+			AmountBMin:         s.AmountBMin,
+			AmountOut:          s.AmountOut,
+			AmountInMax:        s.AmountInMax,
+			Fee:                s.Fee,
+			NumberOfCalls:      s.NumberOfCalls,
+			CallsData:          s.CallsData,
+		},
+	}
+}
+
+// Key is the product or chain of the event, its topic
+func (e Event) Key() string {
+	switch {
+	case e.Ticker != nil:
+		return e.Ticker.Symbol
+	case e.Order != nil:
+		return e.Order.ProductID
+	case e.Heartbeat != nil:
+		return e.Heartbeat.ProductID
+	case e.Candle != nil:
+		return e.Candle.ProductID
+	default:
+		return e.Source
+	}
+}
+
+func nullString(d decimal.NullDecimal) *string {
+	if !d.Valid {
+		return nil
+	}
+	s := d.Decimal.String()
+
+	return &s
+}
+
+// nullValue points to v, nil when it is not valid
+func nullValue[T any](v T, valid bool) *T {
+	if !valid {
+		return nil
+	}
+
+	return &v
+}
@@ -0,0 +1,83 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type kafkaBroker struct {
+	client  *kgo.Client
+	timeout time.Duration
+}
+
+// NewKafkaBroker produces to the Kafka cluster of the comma separated seed brokers, like
+// localhost:9092. Records are keyed so the events of a key stay ordered in their partition
+// and carry the content type in a header, the records of a topic queued together are
+// produced in one batch
+func NewKafkaBroker(brokers string, timeout time.Duration) (*kafkaBroker, error) {
+	var seeds []string
+	for _, broker := range strings.Split(brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			seeds = append(seeds, broker)
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, errors.New("no kafka broker")
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(seeds...),
+		kgo.ClientID("bz-findata"),
+		kgo.DialTimeout(timeout),
+		kgo.ProduceRequestTimeout(timeout),
+		kgo.RecordDeliveryTimeout(timeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+
+	return &kafkaBroker{client: client, timeout: timeout}, nil
+}
+
+func (k *kafkaBroker) Publish(ctx context.Context, msg Message) error {
+	return k.PublishBatch(ctx, []Message{msg})
+}
+
+// PublishBatch produces the records of msgs and waits until every one is acknowledged
+func (k *kafkaBroker) PublishBatch(ctx context.Context, msgs []Message) error {
+	records := make([]*kgo.Record, len(msgs))
+	for i, msg := range msgs {
+		records[i] = &kgo.Record{
+			Topic:   msg.Topic,
+			Key:     []byte(msg.Key),
+			Value:   msg.Payload,
+			Headers: []kgo.RecordHeader{{Key: "content-type", Value: []byte(msg.ContentType)}},
+		}
+	}
+
+	var errs []error
+	for _, result := range k.client.ProduceSync(ctx, records...) {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to produce to kafka topic %s: %w", result.Record.Topic, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (k *kafkaBroker) Close() error {
+	defer k.client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+	if err := k.client.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush kafka: %w", err)
+	}
+
+	return nil
+}
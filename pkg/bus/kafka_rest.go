@@ -0,0 +1,112 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaRESTContentType is the binary embedded format of the REST Proxy v2 API
+const kafkaRESTContentType = "application/vnd.kafka.binary.v2+json"
+
+type kafkaRESTBroker struct {
+	url    string
+	client *http.Client
+}
+
+type kafkaRESTRecords struct {
+	Records []kafkaRESTRecord `json:"records"`
+}
+
+// kafkaRESTRecord key and value are base64 encoded as []byte
+type kafkaRESTRecord struct {
+	Key   []byte `json:"key,omitempty"`
+	Value []byte `json:"value"`
+}
+
+type kafkaRESTOffsets struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaRESTBroker produces to Kafka through the Confluent REST Proxy at url, for clusters
+// only reachable over HTTP. Records are keyed so the events of a key stay ordered in their
+// partition, the records of a topic queued together are produced in one request
+func NewKafkaRESTBroker(rawURL string, timeout time.Duration) (*kafkaRESTBroker, error) {
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid kafka rest proxy url: %w", err)
+	}
+
+	return &kafkaRESTBroker{
+		url:    strings.TrimSuffix(rawURL, "/"),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (k *kafkaRESTBroker) Publish(ctx context.Context, msg Message) error {
+	return k.PublishBatch(ctx, []Message{msg})
+}
+
+// PublishBatch produces the records of msgs in one request to the topic of the first one
+func (k *kafkaRESTBroker) PublishBatch(ctx context.Context, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	topic := msgs[0].Topic
+
+	records := make([]kafkaRESTRecord, len(msgs))
+	for i, msg := range msgs {
+		records[i] = kafkaRESTRecord{Key: []byte(msg.Key), Value: msg.Payload}
+	}
+	body, err := json.Marshal(kafkaRESTRecords{Records: records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.url+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to kafka: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to produce to kafka topic %s: %s: %s", topic, resp.Status, bytes.TrimSpace(b))
+	}
+
+	var offsets kafkaRESTOffsets
+	if err := json.NewDecoder(resp.Body).Decode(&offsets); err != nil {
+		return fmt.Errorf("failed to decode kafka offsets: %w", err)
+	}
+	// the offsets are those of the records, in order
+	var errs []error
+	for _, o := range offsets.Offsets {
+		if o.ErrorCode != nil {
+			errs = append(errs, fmt.Errorf("failed to produce to kafka topic %s: %s (code %d)", topic, o.Error, *o.ErrorCode))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (k *kafkaRESTBroker) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsBroker struct {
+	conn    *nats.Conn
+	timeout time.Duration
+}
+
+// NewNATSBroker connects to the NATS server at url, messages are published on the
+// subject of their topic and buffered by the client while it reconnects
+func NewNATSBroker(url string, timeout time.Duration) (*natsBroker, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("bz-findata"),
+		nats.Timeout(timeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return &natsBroker{conn: conn, timeout: timeout}, nil
+}
+
+func (n *natsBroker) Publish(_ context.Context, msg Message) error {
+	m := nats.NewMsg(msg.Topic)
+	m.Header.Set("Content-Type", msg.ContentType)
+	m.Data = msg.Payload
+
+	if err := n.conn.PublishMsg(m); err != nil {
+		return fmt.Errorf("failed to publish to nats: %w", err)
+	}
+
+	return nil
+}
+
+func (n *natsBroker) Close() error {
+	defer n.conn.Close()
+
+	if err := n.conn.FlushTimeout(n.timeout); err != nil {
+		return fmt.Errorf("failed to flush nats: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// maxBatch is the most events sent to a batch broker at once
+const maxBatch = 500
+
+// ErrFull is returned when more events are pending than the publisher keeps,
+// the event is dropped so the feed is never slowed by the bus
+var ErrFull = errors.New("bus publisher queue full")
+
+// Publisher publishes the events of a source to a topic per product or chain,
+// events are queued and sent by Run, batched per topic when the broker batches
+type Publisher struct {
+	broker Broker
+	format Format
+	prefix string
+	source string
+	queue  chan Message
+	failed func(error)
+	now    func() time.Time
+}
+
+// NewPublisher creates a publisher of the events of source, topics are prefix and the
+// product or chain of the event. At most maxPending events wait for the broker,
+// failed is called for every event the broker could not take
+func NewPublisher(broker Broker, format Format, prefix, source string, maxPending int, failed func(error)) *Publisher {
+	return &Publisher{
+		broker: broker,
+		format: format,
+		prefix: prefix,
+		source: source,
+		queue:  make(chan Message, maxPending),
+		failed: failed,
+		now:    time.Now,
+	}
+}
+
+// PublishMessage queues the event of a ticker, order, heartbeat or candle, other messages are skipped.
+// The event is of the source of the message, if it has one, instead of the source of the publisher
+func (p *Publisher) PublishMessage(message entity.Message) error {
+	source := p.source
+	if message.Source != "" {
+		source = message.Source
+	}
+
+	event, ok := NewMessageEvent(source, message)
+	if !ok {
+		return nil
+	}
+
+	return p.enqueue(event)
+}
+
+// WriteSwaps queues the events of swaps, published on the topic of the chain
+func (p *Publisher) WriteSwaps(swaps []entity.SwapTransaction) error {
+	now := p.now().UnixNano()
+	for _, s := range swaps {
+		if err := p.enqueue(NewSwapEvent(p.source, s, now)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Publisher) enqueue(event Event) error {
+	payload, err := Encode(p.format, event)
+	if err != nil {
+		return err
+	}
+
+	msg := Message{
+		Topic:       Topic(p.prefix, event.Key()),
+		Key:         event.Key(),
+		ContentType: p.format.ContentType(),
+		Payload:     payload,
+	}
+	if event.Swap != nil {
+		// spreads the swaps of a chain over the partitions
+		msg.Key = event.Swap.TxHash
+	}
+
+	select {
+	case p.queue <- msg:
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+// Run publishes the queued events until ctx is done, then what is still queued,
+// and closes the broker
+func (p *Publisher) Run(ctx context.Context) error {
+	for {
+		select {
+		case msg := <-p.queue:
+			p.publish(ctx, p.batch(msg))
+		case <-ctx.Done():
+			for {
+				select {
+				case msg := <-p.queue:
+					p.publish(context.Background(), p.batch(msg))
+				default:
+					return p.broker.Close()
+				}
+			}
+		}
+	}
+}
+
+// batch is msg and the messages queued behind it, up to maxBatch
+func (p *Publisher) batch(msg Message) []Message {
+	msgs := []Message{msg}
+	for len(msgs) < maxBatch {
+		select {
+		case msg := <-p.queue:
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+
+	return msgs
+}
+
+// publish sends msgs one by one, or one batch per topic when the broker batches
+func (p *Publisher) publish(ctx context.Context, msgs []Message) {
+	batcher, ok := p.broker.(BatchBroker)
+	if !ok {
+		for _, msg := range msgs {
+			p.fail(p.broker.Publish(ctx, msg), 1)
+		}
+		return
+	}
+
+	var topics []string
+	byTopic := make(map[string][]Message)
+	for _, msg := range msgs {
+		if _, ok := byTopic[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+		byTopic[msg.Topic] = append(byTopic[msg.Topic], msg)
+	}
+	for _, topic := range topics {
+		p.fail(batcher.PublishBatch(ctx, byTopic[topic]), len(byTopic[topic]))
+	}
+}
+
+// fail reports err for each event of a failed send, a partly rejected batch is failed
+func (p *Publisher) fail(err error, events int) {
+	if err == nil || p.failed == nil {
+		return
+	}
+	for i := 0; i < events; i++ {
+		p.failed(err)
+	}
+}
+
+// Topic is the topic of the events of key, a product or chain, under prefix.
+// The key is one token, characters other than letters, digits, - and _ become _
+func Topic(prefix, key string) string {
+	if key == "" {
+		key = "unknown"
+	}
+	key = strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, key)
+
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
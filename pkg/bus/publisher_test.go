@@ -0,0 +1,244 @@
+package bus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestPublisher(t *testing.T) {
+	broker := NewEmbeddedBroker()
+	btc, unsubscribe := broker.Subscribe("market.BTC-USD", 10)
+	defer unsubscribe()
+	all, unsubscribeAll := broker.Subscribe("market.>", 10)
+	defer unsubscribeAll()
+
+	p := NewPublisher(broker, JSON, "market", "coinbase", 10, nil)
+	require.NoError(t, p.PublishMessage(entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD", Price: decimal.NewNullDecimal(decimal.NewFromInt(64000))}}))
+	require.NoError(t, p.PublishMessage(entity.Message{Order: &entity.Order{ProductID: "ETH-USD", Type: "match"}}))
+	require.NoError(t, p.PublishMessage(entity.Message{Order: &entity.Order{ProductID: "ETH-USDT", Type: "match"}, Source: "binance"}))
+	require.NoError(t, p.PublishMessage(entity.Message{Book: &entity.BookUpdate{}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	msg := <-btc
+	assert.Equal(t, "market.BTC-USD", msg.Topic)
+	assert.Equal(t, "application/json", msg.ContentType)
+	event, err := Decode(JSON, msg.Payload)
+	require.NoError(t, err)
+	assert.Equal(t, TickerEvent, event.Type)
+	assert.Equal(t, "coinbase", event.Source)
+	assert.Equal(t, "64000", *event.Ticker.Price)
+	assert.Nil(t, event.Ticker.LastSize)
+
+	assert.Equal(t, "market.BTC-USD", (<-all).Topic)
+	assert.Equal(t, "market.ETH-USD", (<-all).Topic)
+
+	// the source of the message wins
+	msg = <-all
+	assert.Equal(t, "market.ETH-USDT", msg.Topic)
+	event, err = Decode(JSON, msg.Payload)
+	require.NoError(t, err)
+	assert.Equal(t, "binance", event.Source)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestPublisher_full(t *testing.T) {
+	p := NewPublisher(NewEmbeddedBroker(), JSON, "dex", "ethereum", 1, nil)
+
+	require.NoError(t, p.WriteSwaps([]entity.SwapTransaction{{TxHash: "0x1"}}))
+	assert.ErrorIs(t, p.WriteSwaps([]entity.SwapTransaction{{TxHash: "0x2"}}), ErrFull)
+
+	msg := <-p.queue
+	assert.Equal(t, "dex.ethereum", msg.Topic)
+	assert.Equal(t, "0x1", msg.Key)
+}
+
+type failingBroker struct{ closed bool }
+
+func (f *failingBroker) Publish(context.Context, Message) error { return errors.New("down") }
+func (f *failingBroker) Close() error                           { f.closed = true; return nil }
+
+// batchBroker keeps the batches published
+type batchBroker struct {
+	failingBroker
+	batches [][]Message
+}
+
+func (b *batchBroker) PublishBatch(_ context.Context, msgs []Message) error {
+	b.batches = append(b.batches, msgs)
+	return nil
+}
+
+func TestPublisher_batch(t *testing.T) {
+	broker := &batchBroker{}
+	p := NewPublisher(broker, JSON, "market", "coinbase", 10, func(error) { t.Fail() })
+	require.NoError(t, p.PublishMessage(entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "BTC-USD", Sequence: 1}}))
+	require.NoError(t, p.PublishMessage(entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "ETH-USD", Sequence: 1}}))
+	require.NoError(t, p.PublishMessage(entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "BTC-USD", Sequence: 2}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, p.Run(ctx))
+
+	// one batch per topic, in the order queued
+	require.Len(t, broker.batches, 2)
+	require.Len(t, broker.batches[0], 2)
+	assert.Equal(t, "market.BTC-USD", broker.batches[0][1].Topic)
+	event, err := Decode(JSON, broker.batches[0][1].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), event.Heartbeat.Sequence)
+	assert.Equal(t, "market.ETH-USD", broker.batches[1][0].Topic)
+}
+
+func TestPublisher_Run(t *testing.T) {
+	broker := &failingBroker{}
+	var failures int
+	p := NewPublisher(broker, Protobuf, "market", "coinbase", 10, func(error) { failures++ })
+	require.NoError(t, p.PublishMessage(entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "BTC-USD"}}))
+	require.NoError(t, p.PublishMessage(entity.Message{Heartbeat: &entity.Heartbeat{ProductID: "BTC-USD"}}))
+
+	// what is queued is still published once ctx is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, p.Run(ctx))
+
+	assert.Equal(t, 2, failures)
+	assert.True(t, broker.closed)
+}
+
+func TestKafkaBroker(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "market.BTC-USD"))
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	_, err = NewKafkaBroker(" , ", time.Second)
+	assert.EqualError(t, err, "no kafka broker")
+
+	k, err := NewKafkaBroker(strings.Join(cluster.ListenAddrs(), ","), 5*time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, k.Publish(context.Background(), Message{Topic: "market.BTC-USD", Key: "BTC-USD", ContentType: "application/json", Payload: []byte(`{}`)}))
+	require.NoError(t, k.PublishBatch(context.Background(), []Message{
+		{Topic: "market.BTC-USD", Key: "BTC-USD", Payload: []byte("1")},
+		{Topic: "market.BTC-USD", Key: "BTC-USD", Payload: []byte("2")},
+	}))
+	require.NoError(t, k.Close())
+
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics("market.BTC-USD"),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < 3 && ctx.Err() == nil {
+		fetches := consumer.PollFetches(ctx)
+		records = append(records, fetches.Records()...)
+	}
+
+	// the records of a key are in order in their partition
+	require.Len(t, records, 3)
+	assert.Equal(t, "BTC-USD", string(records[0].Key))
+	assert.Equal(t, `{}`, string(records[0].Value))
+	assert.Equal(t, []kgo.RecordHeader{{Key: "content-type", Value: []byte("application/json")}}, records[0].Headers)
+	assert.Equal(t, "2", string(records[2].Value))
+}
+
+func TestKafkaRESTBroker(t *testing.T) {
+	var got kafkaRESTRecords
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/market.BTC-USD", r.URL.Path)
+		assert.Equal(t, kafkaRESTContentType, r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		if string(got.Records[0].Value) == "fail" {
+			w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"not leader"}]}`))
+			return
+		}
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":12,"error_code":null,"error":null}]}`))
+	}))
+	defer server.Close()
+
+	k, err := NewKafkaRESTBroker(server.URL+"/", time.Second)
+	require.NoError(t, err)
+	defer k.Close()
+
+	require.NoError(t, k.Publish(context.Background(), Message{Topic: "market.BTC-USD", Key: "BTC-USD", Payload: []byte(`{}`)}))
+	assert.Equal(t, "BTC-USD", string(got.Records[0].Key))
+	assert.Equal(t, `{}`, string(got.Records[0].Value))
+
+	err = k.Publish(context.Background(), Message{Topic: "market.BTC-USD", Payload: []byte("fail")})
+	assert.ErrorContains(t, err, "not leader")
+
+	// the records of a batch are produced in one request
+	require.NoError(t, k.PublishBatch(context.Background(), []Message{
+		{Topic: "market.BTC-USD", Key: "BTC-USD", Payload: []byte("1")},
+		{Topic: "market.BTC-USD", Key: "BTC-USD", Payload: []byte("2")},
+	}))
+	require.Len(t, got.Records, 2)
+	assert.Equal(t, "2", string(got.Records[1].Value))
+
+	// the REST Proxy embeds binary records as base64
+	raw, _ := json.Marshal(kafkaRESTRecord{Value: []byte("hi")})
+	assert.JSONEq(t, `{"value":"`+base64.StdEncoding.EncodeToString([]byte("hi"))+`"}`, string(raw))
+}
+
+func TestEmbeddedBroker_unsubscribeBlocked(t *testing.T) {
+	broker := NewEmbeddedBroker()
+	_, unsubscribe := broker.Subscribe("market.>", 1)
+	require.NoError(t, broker.Publish(context.Background(), Message{Topic: "market.BTC-USD"}))
+
+	// the second publish waits on the full buffer until the subscription ends
+	published := make(chan error)
+	go func() { published <- broker.Publish(context.Background(), Message{Topic: "market.BTC-USD"}) }()
+	time.Sleep(10 * time.Millisecond)
+
+	unsubscribed := make(chan struct{})
+	go func() {
+		unsubscribe()
+		close(unsubscribed)
+	}()
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe blocked by the publisher")
+	}
+	assert.NoError(t, <-published)
+	assert.NoError(t, broker.Publish(context.Background(), Message{Topic: "market.BTC-USD"}))
+}
+
+func TestTopic(t *testing.T) {
+	assert.Equal(t, "market.BTC-USD", Topic("market", "BTC-USD"))
+	assert.Equal(t, "market.BTC_USD", Topic("market", "BTC/USD"))
+	assert.Equal(t, "unknown", Topic("", ""))
+}
+
+func Test_match(t *testing.T) {
+	assert.True(t, match("market.*", "market.BTC-USD"))
+	assert.True(t, match("market.>", "market.BTC-USD"))
+	assert.False(t, match("market.>", "market"))
+	assert.False(t, match("market.*", "dex.ethereum"))
+	assert.False(t, match("market", "market.BTC-USD"))
+}
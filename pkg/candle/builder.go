@@ -0,0 +1,215 @@
+package candle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// DefaultGranularities of the candles built when none are configured
+var DefaultGranularities = []time.Duration{time.Minute, 5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// ParseGranularity parses a bar length such as 1m, 5m, 1h or 1d,
+// bars are aligned on unix time so a day starts at midnight UTC
+func ParseGranularity(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid granularity %q: %w", s, err)
+	}
+
+	if d < time.Second || d%time.Second != 0 {
+		return 0, fmt.Errorf("invalid granularity %q: must be whole seconds", s)
+	}
+
+	return d, nil
+}
+
+// ParseGranularities parses every bar length of list
+func ParseGranularities(list []string) ([]time.Duration, error) {
+	granularities := make([]time.Duration, 0, len(list))
+	for _, s := range list {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+
+		d, err := ParseGranularity(s)
+		if err != nil {
+			return nil, err
+		}
+		granularities = append(granularities, d)
+	}
+
+	return granularities, nil
+}
+
+type key struct {
+	productID   string
+	granularity int64
+	start       int64
+}
+
+// Builder aggregates matches into candles of every granularity. It only keeps
+// the trades added since the last Flush, so a late trade becomes a partial candle
+// of its past bar which the storage merges with what it already has.
+type Builder struct {
+	mu            sync.Mutex
+	granularities []time.Duration
+	pending       map[key]*entity.Candle
+}
+
+// NewBuilder init candle builder, DefaultGranularities are used when granularities is empty
+func NewBuilder(granularities []time.Duration) *Builder {
+	if len(granularities) == 0 {
+		granularities = DefaultGranularities
+	}
+
+	return &Builder{
+		granularities: granularities,
+		pending:       make(map[key]*entity.Candle),
+	}
+}
+
+// Granularities returns the bar lengths built
+func (b *Builder) Granularities() []time.Duration {
+	return b.granularities
+}
+
+// Add counts a match in the bar of every granularity holding its time,
+// other orders are ignored and false is returned
+func (b *Builder) Add(order *entity.Order) bool {
+	if order.Type != "match" || !order.Size.IsPositive() {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, granularity := range b.granularities {
+		b.merge(entity.Candle{
+			ProductID:    order.ProductID,
+			Granularity:  int64(granularity / time.Second),
+			Timestamp:    order.Timestamp - order.Timestamp%int64(granularity),
+			Open:         order.Price,
+			High:         order.Price,
+			Low:          order.Price,
+			Close:        order.Price,
+			Volume:       order.Size,
+			QuoteVolume:  order.Size.Mul(order.Price),
+			VWAP:         order.Price,
+			Trades:       1,
+			OpenTime:     order.Timestamp,
+			OpenTradeID:  order.TradeID,
+			CloseTime:    order.Timestamp,
+			CloseTradeID: order.TradeID,
+		})
+	}
+
+	return true
+}
+
+// Restore merges candles back, e.g. after they failed to be written
+func (b *Builder) Restore(candles []entity.Candle) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range candles {
+		b.merge(c)
+	}
+}
+
+// Pending returns the number of candles waiting for Flush
+func (b *Builder) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// Flush returns the candles built since the last flush ordered by product,
+// granularity and time, and starts over
+func (b *Builder) Flush() []entity.Candle {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[key]*entity.Candle)
+	b.mu.Unlock()
+
+	candles := make([]entity.Candle, 0, len(pending))
+	for _, c := range pending {
+		candles = append(candles, *c)
+	}
+	sort.Slice(candles, func(i, j int) bool {
+		a, b := candles[i], candles[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		if a.Granularity != b.Granularity {
+			return a.Granularity < b.Granularity
+		}
+		return a.Timestamp < b.Timestamp
+	})
+
+	return candles
+}
+
+func (b *Builder) merge(c entity.Candle) {
+	k := key{c.ProductID, c.Granularity, c.Timestamp}
+
+	current, ok := b.pending[k]
+	if !ok {
+		b.pending[k] = &c
+		return
+	}
+
+	*current = Merge(*current, c)
+}
+
+// Merge combines two candles of the same bar, the result does not depend on the
+// order the trades were delivered in
+func Merge(a, b entity.Candle) entity.Candle {
+	m := a
+
+	if before(b.OpenTime, b.OpenTradeID, a.OpenTime, a.OpenTradeID) {
+		m.Open, m.OpenTime, m.OpenTradeID = b.Open, b.OpenTime, b.OpenTradeID
+	}
+	if !before(b.CloseTime, b.CloseTradeID, a.CloseTime, a.CloseTradeID) {
+		m.Close, m.CloseTime, m.CloseTradeID = b.Close, b.CloseTime, b.CloseTradeID
+	}
+	if b.High.GreaterThan(m.High) {
+		m.High = b.High
+	}
+	if b.Low.LessThan(m.Low) {
+		m.Low = b.Low
+	}
+
+	m.Volume = m.Volume.Add(b.Volume)
+	m.QuoteVolume = m.QuoteVolume.Add(b.QuoteVolume)
+	m.Trades += b.Trades
+	if m.Volume.IsPositive() {
+		m.VWAP = m.QuoteVolume.Div(m.Volume)
+	}
+
+	return m
+}
+
+// before orders trades by time, then by trade id
+func before(t, tradeID, otherT, otherTradeID int64) bool {
+	if t != otherT {
+		return t < otherT
+	}
+	return tradeID < otherTradeID
+}
@@ -0,0 +1,135 @@
+package candle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func match(tradeID int64, at time.Duration, price, size float64) *entity.Order {
+	return &entity.Order{
+		Type:      "match",
+		ProductID: "BTC-USD",
+		Timestamp: base.Add(at).UnixNano(),
+		TradeID:   tradeID,
+		Price:     decimal.NewFromFloat(price),
+		Size:      decimal.NewFromFloat(size),
+	}
+}
+
+func TestBuilder_Add(t *testing.T) {
+	b := NewBuilder([]time.Duration{time.Minute, time.Hour})
+
+	assert.True(t, b.Add(match(1, 10*time.Second, 100, 1)))
+	assert.True(t, b.Add(match(3, 50*time.Second, 90, 1)))
+	// late trade of the same bar
+	assert.True(t, b.Add(match(2, 30*time.Second, 120, 2)))
+	assert.True(t, b.Add(match(4, 70*time.Second, 110, 1)))
+	assert.False(t, b.Add(&entity.Order{Type: "open", ProductID: "BTC-USD", Size: decimal.NewFromInt(1)}))
+	assert.False(t, b.Add(&entity.Order{Type: "match", ProductID: "BTC-USD"}))
+
+	candles := b.Flush()
+	require.Len(t, candles, 3)
+	assert.Zero(t, b.Pending())
+
+	first := candles[0]
+	assert.Equal(t, int64(60), first.Granularity)
+	assert.Equal(t, base.UnixNano(), first.Timestamp)
+	assert.Equal(t, "100", first.Open.String())
+	assert.Equal(t, "120", first.High.String())
+	assert.Equal(t, "90", first.Low.String())
+	assert.Equal(t, "90", first.Close.String())
+	assert.Equal(t, "4", first.Volume.String())
+	assert.Equal(t, "430", first.QuoteVolume.String())
+	assert.Equal(t, "107.5", first.VWAP.String())
+	assert.Equal(t, int64(3), first.Trades)
+
+	assert.Equal(t, base.Add(time.Minute).UnixNano(), candles[1].Timestamp)
+	assert.Equal(t, int64(1), candles[1].Trades)
+
+	hour := candles[2]
+	assert.Equal(t, int64(3600), hour.Granularity)
+	assert.Equal(t, "100", hour.Open.String())
+	assert.Equal(t, "110", hour.Close.String())
+	assert.Equal(t, int64(4), hour.Trades)
+}
+
+func TestBuilder_ExactSums(t *testing.T) {
+	b := NewBuilder([]time.Duration{time.Minute})
+	b.Add(match(1, 0, 0.1, 0.1))
+	b.Add(match(2, time.Second, 0.2, 0.2))
+
+	candles := b.Flush()
+	require.Len(t, candles, 1)
+	// 0.1 + 0.2 is 0.30000000000000004 in float64
+	assert.Equal(t, "0.3", candles[0].Volume.String())
+	assert.Equal(t, "0.05", candles[0].QuoteVolume.String())
+}
+
+func TestBuilder_LateTradeAfterFlush(t *testing.T) {
+	trades := []*entity.Order{
+		match(1, 10*time.Second, 100, 1),
+		match(2, 20*time.Second, 105, 1),
+		match(4, 40*time.Second, 95, 3),
+		match(3, 30*time.Second, 130, 1), // delivered late
+	}
+
+	all := NewBuilder([]time.Duration{time.Minute})
+	for _, trade := range trades {
+		all.Add(trade)
+	}
+	want := all.Flush()
+
+	// flushed between trades, as the storage sees it
+	b := NewBuilder([]time.Duration{time.Minute})
+	var stored *entity.Candle
+	for _, trade := range trades {
+		b.Add(trade)
+		for _, c := range b.Flush() {
+			if stored == nil {
+				stored = &c
+				continue
+			}
+			merged := Merge(*stored, c)
+			stored = &merged
+		}
+	}
+
+	require.Len(t, want, 1)
+	assert.Equal(t, want[0], *stored)
+	assert.Equal(t, "100", stored.Open.String())
+	assert.Equal(t, "95", stored.Close.String())
+	assert.Equal(t, "130", stored.High.String())
+}
+
+func TestBuilder_Restore(t *testing.T) {
+	b := NewBuilder([]time.Duration{time.Minute})
+	b.Add(match(1, 0, 100, 1))
+
+	failed := b.Flush()
+	b.Add(match(2, time.Second, 101, 1))
+	b.Restore(failed)
+
+	candles := b.Flush()
+	require.Len(t, candles, 1)
+	assert.Equal(t, int64(2), candles[0].Trades)
+	assert.Equal(t, "100", candles[0].Open.String())
+	assert.Equal(t, "101", candles[0].Close.String())
+}
+
+func TestParseGranularities(t *testing.T) {
+	got, err := ParseGranularities([]string{"1m", " 5m", "1h", "1d", ""})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultGranularities, got)
+
+	for _, s := range []string{"1x", "500ms", "0s", "d"} {
+		_, err := ParseGranularity(s)
+		assert.Error(t, err, s)
+	}
+}
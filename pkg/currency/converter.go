@@ -0,0 +1,159 @@
+package currency
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+// USD is the currency every notional is converted to
+const USD = "USD"
+
+type rate struct {
+	price decimal.Decimal
+	time  int64 // unix nano
+}
+
+// Converter turns the notional of any product into USD from the latest
+// prices seen on the feed, e.g. BTC-USD converts the notional of ETH-BTC
+type Converter struct {
+	maxAge int64
+	mu     sync.RWMutex
+	prices map[string]rate // last price by product id
+}
+
+// NewConverter init converter, prices older than maxAge at the time of the
+// converted order are not used, a zero maxAge keeps them forever
+func NewConverter(maxAge time.Duration) *Converter {
+	return &Converter{
+		maxAge: int64(maxAge),
+		prices: make(map[string]rate),
+	}
+}
+
+// Split returns the base and quote currencies of a product id such as ETH-BTC
+func Split(productID string) (base, quote string, ok bool) {
+	base, quote, ok = strings.Cut(strings.ToUpper(productID), "-")
+	return base, quote, ok && base != "" && quote != ""
+}
+
+// Notional returns the order value in the quote currency of its product,
+// market orders without a price are valued by their funds
+func Notional(order *entity.Order) decimal.Decimal {
+	if order.Price.IsZero() && order.Funds.IsPositive() {
+		return order.Funds
+	}
+
+	return order.Size.Mul(order.Price)
+}
+
+// Observe keeps the last trade price of a ticker, or its mid when it has no trade
+func (c *Converter) Observe(ticker *entity.Ticker) {
+	price := ticker.Price.Decimal
+	if !price.IsPositive() && ticker.Bid.IsPositive() && ticker.Ask.IsPositive() {
+		price = ticker.Bid.Add(ticker.Ask).Div(decimal.NewFromInt(2))
+	}
+	c.Set(ticker.Symbol, price, ticker.Timestamp)
+}
+
+// ObserveOrder keeps the price of a match
+func (c *Converter) ObserveOrder(order *entity.Order) {
+	if order.Type == "match" {
+		c.Set(order.ProductID, order.Price, order.Timestamp)
+	}
+}
+
+// Set stores the price of a product at t, older prices than the stored one are ignored
+func (c *Converter) Set(productID string, price decimal.Decimal, t int64) {
+	if !price.IsPositive() {
+		return
+	}
+	productID = strings.ToUpper(productID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.prices[productID]; ok && last.time > t {
+		return
+	}
+	c.prices[productID] = rate{price, t}
+}
+
+// Rate returns the USD value of one unit of currency at time t, directly from
+// <currency>-USD or USD-<currency>, or through a currency with a USD price
+func (c *Converter) Rate(currency string, t int64) (decimal.Decimal, bool) {
+	currency = strings.ToUpper(currency)
+	if currency == USD {
+		return decimal.NewFromInt(1), true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if r, ok := c.direct(currency, t); ok {
+		return r, true
+	}
+
+	// one hop, e.g. ETH through ETH-BTC and BTC-USD
+	for productID, p := range c.prices {
+		if !c.fresh(p, t) {
+			continue
+		}
+		base, quote, ok := Split(productID)
+		if !ok {
+			continue
+		}
+		switch currency {
+		case base:
+			if r, ok := c.direct(quote, t); ok {
+				return p.price.Mul(r), true
+			}
+		case quote:
+			if r, ok := c.direct(base, t); ok {
+				return r.Div(p.price), true
+			}
+		}
+	}
+
+	return decimal.Zero, false
+}
+
+// ToUSD converts an amount in the quote currency of productID
+func (c *Converter) ToUSD(productID string, amount decimal.Decimal, t int64) (decimal.Decimal, bool) {
+	_, quote, ok := Split(productID)
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	r, ok := c.Rate(quote, t)
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	return amount.Mul(r), true
+}
+
+// Price sets the USD value of the order, it stays null when no rate is known
+func (c *Converter) Price(order *entity.Order) {
+	value, ok := c.ToUSD(order.ProductID, Notional(order), order.Timestamp)
+	order.USDValue = decimal.NullDecimal{Decimal: value, Valid: ok}
+}
+
+func (c *Converter) direct(currency string, t int64) (decimal.Decimal, bool) {
+	if p, ok := c.prices[currency+"-"+USD]; ok && c.fresh(p, t) {
+		return p.price, true
+	}
+	if p, ok := c.prices[USD+"-"+currency]; ok && c.fresh(p, t) {
+		return decimal.NewFromInt(1).Div(p.price), true
+	}
+
+	return decimal.Zero, false
+}
+
+// fresh reports whether the rate is recent enough to convert at t
+func (c *Converter) fresh(r rate, t int64) bool {
+	return c.maxAge <= 0 || t-r.time <= c.maxAge
+}
@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestNotional(t *testing.T) {
+	assert.Equal(t, "15000", Notional(&entity.Order{Size: dec("0.5"), Price: dec("30000")}).String())
+	// market order with funds only
+	assert.Equal(t, "2500.5", Notional(&entity.Order{Funds: dec("2500.50")}).String())
+	assert.True(t, Notional(&entity.Order{Type: "done"}).IsZero())
+}
+
+func TestConverter_Rate(t *testing.T) {
+	c := NewConverter(time.Minute)
+	c.Observe(&entity.Ticker{Symbol: "BTC-USD", Price: decimal.NewNullDecimal(dec("60000")), Timestamp: 0})
+	// no trade yet, the mid is used
+	c.Observe(&entity.Ticker{Symbol: "USD-JPY", Bid: dec("149"), Ask: dec("151"), Timestamp: 0})
+	c.ObserveOrder(&entity.Order{Type: "match", ProductID: "ETH-BTC", Price: dec("0.05"), Timestamp: 0})
+	c.ObserveOrder(&entity.Order{Type: "open", ProductID: "SOL-USD", Price: dec("150"), Timestamp: 0})
+
+	tests := []struct {
+		currency string
+		want     string
+		ok       bool
+	}{
+		{currency: "USD", want: "1", ok: true},
+		{currency: "btc", want: "60000", ok: true},
+		{currency: "JPY", want: "0.0066666666666667", ok: true},
+		{currency: "ETH", want: "3000", ok: true},
+		{currency: "SOL", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			got, ok := c.Rate(tt.currency, int64(time.Second))
+			require.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+
+	// too old at the time of the order
+	_, ok := c.Rate("BTC", int64(2*time.Minute))
+	assert.False(t, ok)
+}
+
+func TestConverter_Price(t *testing.T) {
+	c := NewConverter(0)
+	c.Set("BTC-USD", dec("60000"), 2)
+	// an older price does not replace the last one
+	c.Set("BTC-USD", dec("10"), 1)
+
+	order := &entity.Order{Type: "match", ProductID: "ETH-BTC", Size: dec("10"), Price: dec("0.05")}
+	c.Price(order)
+	require.True(t, order.USDValue.Valid)
+	assert.Equal(t, "30000", order.USDValue.Decimal.String())
+
+	order = &entity.Order{Type: "received", ProductID: "BTC-EUR", Funds: dec("1000")}
+	c.Price(order)
+	assert.False(t, order.USDValue.Valid)
+
+	c.Set("EUR-USD", dec("1.08"), 3)
+	c.Price(order)
+	require.True(t, order.USDValue.Valid)
+	assert.Equal(t, "1080", order.USDValue.Decimal.String())
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"github.com/nel349/bz-findata/pkg/database/mysql"
+	"github.com/nel349/bz-findata/pkg/database/postgres"
+	"github.com/nel349/bz-findata/pkg/database/sqlite"
+)
+
+// Drivers of the supported databases, named like the dialects of pkg/migrations
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite" // local development, base is the database file
+)
+
+// Connect opens the database of driver, host may hold the port.
+// A sqlite database needs neither host nor user
+func Connect(driver, host, user, password, base string) (*sqlx.DB, error) {
+	if driver != SQLite && (host == "" || user == "") {
+		return nil, fmt.Errorf("database host and user are required by driver %q", driver)
+	}
+
+	switch driver {
+	case MySQL, "":
+		client, err := mysql.NewMysqlClient(host, user, password, base)
+		if err != nil {
+			return nil, err
+		}
+		return client.DB, nil
+	case Postgres:
+		client, err := postgres.NewPostgresClient(PostgresDSN(host, user, password, base))
+		if err != nil {
+			return nil, err
+		}
+		return client.DB, nil
+	case SQLite:
+		if base == "" {
+			return nil, fmt.Errorf("database file is required by driver %q", driver)
+		}
+		client, err := sqlite.NewSqliteClient(base)
+		if err != nil {
+			return nil, err
+		}
+		return client.DB, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// PostgresDSN builds the connection string of a postgres database
+func PostgresDSN(host, user, password, base string) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(user, password),
+		Host:   host,
+		Path:   "/" + base,
+	}
+
+	return dsn.String()
+}
+
+// Rejected reports whether err was returned by the database for a statement it did not
+// apply, or by a connection the statement was never sent on. A statement outside of a
+// transaction that was rejected can be run again, after other errors like a timeout it
+// may have been applied
+func Rejected(err error) bool {
+	var mysqlErr *gomysql.MySQLError
+	var pgErr *pgconn.PgError
+	var sqliteErr sqlite3.Error
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.As(err, &mysqlErr) ||
+		errors.As(err, &pgErr) ||
+		errors.As(err, &sqliteErr)
+}
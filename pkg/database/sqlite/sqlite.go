@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type client struct {
+	*sqlx.DB
+}
+
+// NewSqliteClient init client for the sqlite database file at path, created when missing.
+// The file is shared by the services in WAL mode, a write waits for another one
+// instead of failing with database is locked
+func NewSqliteClient(path string) (*client, error) {
+	params := url.Values{}
+	params.Set("_busy_timeout", "5000")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_foreign_keys", "on")
+
+	db, err := sqlx.Connect("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{db}, nil
+}
+
+func (db *client) PingCheck() error {
+	return db.Ping()
+}
+
+func (db *client) CloseConnect() error {
+	return db.Close()
+}
@@ -0,0 +1,171 @@
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// maxEntrySize is the longest line read back from the queue
+const maxEntrySize = 4 << 20
+
+// Entry is a message that could not be stored
+type Entry struct {
+	FailedAt int64          `json:"failed_at"` // unix nano
+	Error    string         `json:"error"`
+	Attempts int            `json:"attempts"` // failed replays
+	Message  entity.Message `json:"message"`
+}
+
+// Stats of a replay
+type Stats struct {
+	Replayed int
+	Failed   int // queued again
+	Invalid  int // unreadable lines, dropped
+}
+
+// Queue is an append-only JSONL file of failed messages
+type Queue struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// NewQueue init dead-letter queue stored in the file at path
+func NewQueue(path string) (*Queue, error) {
+	if path == "" {
+		return nil, errors.New("dead-letter queue path is required")
+	}
+
+	return &Queue{path: path, now: time.Now}, nil
+}
+
+// Path of the queue file
+func (q *Queue) Path() string {
+	return q.path
+}
+
+// Add appends message with the error that failed it
+func (q *Queue) Add(message entity.Message, cause error) error {
+	return q.append([]Entry{{
+		FailedAt: q.now().UnixNano(),
+		Error:    errorString(cause),
+		Message:  message,
+	}})
+}
+
+// append writes entries, the file is reopened on every write so a replay can move it away
+func (q *Queue) append(entries []Entry) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	file, err := os.OpenFile(q.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter queue: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write dead letter: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	return file.Close()
+}
+
+// Replay hands every queued entry to store, entries it fails on are queued again.
+// The queue is moved aside first so messages failing meanwhile are kept for the next replay.
+func (q *Queue) Replay(ctx context.Context, store func(ctx context.Context, entry Entry) error) (Stats, error) {
+	var stats Stats
+
+	replaying := fmt.Sprintf("%s.replay-%d", q.path, q.now().UnixNano())
+
+	q.mu.Lock()
+	err := os.Rename(q.path, replaying)
+	q.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to move dead-letter queue: %w", err)
+	}
+
+	file, err := os.Open(replaying)
+	if err != nil {
+		return stats, fmt.Errorf("failed to open dead-letter queue: %w", err)
+	}
+	defer file.Close()
+
+	var failed []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// e.g. a line cut by a crash
+			stats.Invalid++
+			continue
+		}
+
+		// stop storing on cancel, the rest is queued again
+		if ctx.Err() != nil {
+			failed = append(failed, entry)
+			continue
+		}
+
+		if err := store(ctx, entry); err != nil {
+			entry.Attempts++
+			entry.Error = errorString(err)
+			failed = append(failed, entry)
+			stats.Failed++
+			continue
+		}
+		stats.Replayed++
+	}
+	if err := scanner.Err(); err != nil {
+		return stats, q.restore(replaying, failed, fmt.Errorf("failed to read dead-letter queue: %w", err))
+	}
+
+	if err := q.append(failed); err != nil {
+		return stats, fmt.Errorf("failed to requeue dead letters, kept in %s: %w", replaying, err)
+	}
+	if err := os.Remove(replaying); err != nil {
+		return stats, fmt.Errorf("failed to remove %s: %w", replaying, err)
+	}
+
+	return stats, ctx.Err()
+}
+
+// restore requeues the failed entries when the moved queue can't be read to the end,
+// the moved queue is kept since storing its entries again is harmless
+func (q *Queue) restore(replaying string, failed []Entry, cause error) error {
+	if len(failed) > 0 {
+		if err := q.append(failed); err != nil {
+			return fmt.Errorf("%w, failed to requeue dead letters: %v", cause, err)
+		}
+	}
+
+	return fmt.Errorf("%w, remaining dead letters kept in %s", cause, replaying)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
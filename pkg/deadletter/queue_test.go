@@ -0,0 +1,97 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	q, err := NewQueue(path)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Add(entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD", Bid: decimal.NewFromInt(1), Ask: decimal.NewFromInt(2)}}, errors.New("timeout")))
+	require.NoError(t, q.Add(entity.Message{Order: &entity.Order{ProductID: "BTC-USD", Sequence: 7}}, errors.New("timeout")))
+	require.NoError(t, q.Add(entity.Message{Order: &entity.Order{ProductID: "ETH-USD", Sequence: 8}}, errors.New("timeout")))
+
+	// a line cut by a crash
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"failed_at":1,"mess` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	var stored []entity.Message
+	stats, err := q.Replay(context.Background(), func(_ context.Context, entry Entry) error {
+		if entry.Message.Order != nil && entry.Message.Order.ProductID == "ETH-USD" {
+			return errors.New("still down")
+		}
+		assert.Equal(t, "timeout", entry.Error)
+		stored = append(stored, entry.Message)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Replayed: 2, Failed: 1, Invalid: 1}, stats)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "BTC-USD", stored[0].Ticker.Symbol)
+	assert.Equal(t, 7, stored[1].Order.Sequence)
+
+	// the failed entry is queued again with its new error
+	stats, err = q.Replay(context.Background(), func(_ context.Context, entry Entry) error {
+		assert.Equal(t, "still down", entry.Error)
+		assert.Equal(t, 1, entry.Attempts)
+		assert.Equal(t, "ETH-USD", entry.Message.Order.ProductID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Replayed: 1}, stats)
+
+	matches, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, matches)
+}
+
+func TestQueue_ReplayEmpty(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "dead-letters.jsonl"))
+	require.NoError(t, err)
+
+	stats, err := q.Replay(context.Background(), func(context.Context, Entry) error {
+		t.Fatal("nothing to replay")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, stats)
+}
+
+func TestQueue_ReplayCancelled(t *testing.T) {
+	q, err := NewQueue(filepath.Join(t.TempDir(), "dead-letters.jsonl"))
+	require.NoError(t, err)
+	require.NoError(t, q.Add(entity.Message{Ticker: &entity.Ticker{Symbol: "BTC-USD"}}, errors.New("timeout")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = q.Replay(ctx, func(context.Context, Entry) error {
+		t.Fatal("replay is cancelled")
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// kept for the next replay
+	stats, err := q.Replay(context.Background(), func(context.Context, Entry) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Replayed)
+}
+
+func TestNewQueue(t *testing.T) {
+	_, err := NewQueue("")
+	assert.Error(t, err)
+}
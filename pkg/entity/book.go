@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BookChange model of a single price level change of the book
+type BookChange struct {
+	Side  string
+	Price decimal.Decimal
+	Size  decimal.Decimal
+}
+
+// BookUpdate model data of level2 book message from exchange,
+// a snapshot replaces the whole book and an update changes single levels
+type BookUpdate struct {
+	ProductID string
+	Snapshot  bool
+	Time      time.Time
+	Changes   []BookChange
+}
+
+// BookSnapshot model data of a persisted state of the book
+type BookSnapshot struct {
+	Timestamp int64           `db:"timestamp"`
+	ProductID string          `db:"product_id"`
+	BestBid   decimal.Decimal `db:"best_bid"`
+	BestAsk   decimal.Decimal `db:"best_ask"`
+	Mid       decimal.Decimal `db:"mid"`
+	Spread    decimal.Decimal `db:"spread"`
+	DepthBps  float64         `db:"depth_bps"`
+	BidDepth  decimal.Decimal `db:"bid_depth"`
+	AskDepth  decimal.Decimal `db:"ask_depth"`
+	Bids      string          `db:"bids"` // JSON encoded [[price, size], ...] best first
+	Asks      string          `db:"asks"` // JSON encoded [[price, size], ...] best first
+}
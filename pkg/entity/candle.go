@@ -0,0 +1,23 @@
+package entity
+
+import "github.com/shopspring/decimal"
+
+// Candle model data of an OHLCV bar of a product. A candle may only hold part of
+// the trades of its bar, candles of the same bar are merged by the storage.
+type Candle struct {
+	ProductID    string          `db:"product_id"`
+	Granularity  int64           `db:"granularity"` // bar length in seconds, 60 for 1m bars
+	Timestamp    int64           `db:"timestamp"`   // bar start, unix nano
+	Open         decimal.Decimal `db:"open"`
+	High         decimal.Decimal `db:"high"`
+	Low          decimal.Decimal `db:"low"`
+	Close        decimal.Decimal `db:"close"`
+	Volume       decimal.Decimal `db:"volume"`       // base size traded
+	QuoteVolume  decimal.Decimal `db:"quote_volume"` // sum of price * size
+	VWAP         decimal.Decimal `db:"vwap"`         // QuoteVolume / Volume
+	Trades       int64           `db:"trades"`
+	OpenTime     int64           `db:"open_time"` // time and trade id of the first trade
+	OpenTradeID  int64           `db:"open_trade_id"`
+	CloseTime    int64           `db:"close_time"` // time and trade id of the last trade
+	CloseTradeID int64           `db:"close_trade_id"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+// FeedGap model data of a range of sequences missing from the exchange feed,
+// orders of the product between StartTime and EndTime are incomplete
+type FeedGap struct {
+	ProductID    string `db:"product_id"`
+	FromSequence int64  `db:"from_sequence"` // first missing sequence
+	ToSequence   int64  `db:"to_sequence"`   // last missing sequence
+	Missing      int64  `db:"missing"`
+	StartTime    int64  `db:"start_time"` // time of the last message before the gap
+	EndTime      int64  `db:"end_time"`   // time of the first message after the gap
+	Source       string `db:"source"`     // message type that revealed the gap
+	DetectedAt   int64  `db:"detected_at"`
+}
@@ -4,4 +4,7 @@ type Message struct {
 	Ticker *Ticker
 	Order  *Order
 	Heartbeat *Heartbeat
+	Book      *BookUpdate
+	Candle    *Candle // a bar computed by the exchange
+	Source    string  // exchange of the message when it is not the default one of the feed reader
 }
\ No newline at end of file
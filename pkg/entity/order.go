@@ -1,21 +1,26 @@
 package entity
 
+import "github.com/shopspring/decimal"
+
 // Order model data of response from exchange
 type Order struct {
 	Type      string  `db:"type"`
 	Timestamp int64   `db:"timestamp"`
 	ProductID string  `db:"product_id"`
 	OrderID   string  `db:"order_id"`
-	Funds     float64 `db:"funds"`
+	Funds     decimal.Decimal `db:"funds"`
 	Side      string  `db:"side"`
-	Size      float64 `db:"size"`
-	Price     float64 `db:"price"`
+	Size      decimal.Decimal `db:"size"`
+	Price     decimal.Decimal `db:"price"`
 	OrderType string  `db:"order_type"`
 	ClientOID   string  `db:"client_oid"`
 	Sequence    int     `db:"sequence"`
-	RemainingSize float64 `db:"remaining_size"`
+	RemainingSize decimal.Decimal `db:"remaining_size"`
 	Reason        string  `db:"reason"`
 	TradeID       int64   `db:"trade_id"`
 	MakerOrderID  string  `db:"maker_order_id"`
 	TakerOrderID  string  `db:"taker_order_id"`
+	NewSize       decimal.Decimal `db:"new_size"` // Only for change
+	OldSize       decimal.Decimal `db:"old_size"` // Only for change
+	USDValue      decimal.NullDecimal `db:"usd_value"` // notional in USD, null without a rate
 }	
@@ -1,12 +1,14 @@
 package entity
 
+import "github.com/shopspring/decimal"
+
 type SwapTransaction struct {
-	Value        float64 `json:"value" db:"value"`
-	TxHash       string  `json:"tx_hash" db:"tx_hash"`
-	Version      string  `json:"version" db:"version"`
-	Exchange     string  `json:"exchange" db:"exchange"`
-	AmountIn     string  `json:"amount_in" db:"amount_in"`
-	AmountOutMin string  `json:"amount_out_min" db:"amount_out_min"`
+	Value        decimal.Decimal `json:"value" db:"value"` // USD
+	TxHash       string          `json:"tx_hash" db:"tx_hash"`
+	Version      string          `json:"version" db:"version"`
+	Exchange     string          `json:"exchange" db:"exchange"`
+	AmountIn     string          `json:"amount_in" db:"amount_in"`
+	AmountOutMin string          `json:"amount_out_min" db:"amount_out_min"`
 	// Deadline      string `json:"deadline" db:"deadline"`
 	ToAddress     string `json:"to_address" db:"to_address"`
 	TokenPathFrom string `json:"token_path_from" db:"token_path_from"`
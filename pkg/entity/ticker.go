@@ -1,9 +1,28 @@
 package entity
 
-// Ticker model data of response from exchange
+import (
+	"database/sql"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ticker model data of response from exchange, the optional fields are null when
+// the exchange doesn't send them
 type Ticker struct {
-	Timestamp int64
-	Bid       float64
-	Ask       float64
-	Symbol    string
+	Timestamp  int64               `db:"timestamp"`   // exchange time, unix nano
+	ReceivedAt int64               `db:"received_at"` // time the frame was received, unix nano
+	Symbol     string              `db:"symbol"`
+	Sequence   int64               `db:"sequence"`
+	Price      decimal.NullDecimal `db:"price"`     // price of the last trade
+	LastSize   decimal.NullDecimal `db:"last_size"` // size of the last trade
+	Side       sql.NullString      `db:"side"`      // taker side of the last trade
+	TradeID    sql.NullInt64       `db:"trade_id"`
+	Bid        decimal.Decimal     `db:"bid"`
+	BidSize    decimal.NullDecimal `db:"bid_size"`
+	Ask        decimal.Decimal     `db:"ask"`
+	AskSize    decimal.NullDecimal `db:"ask_size"`
+	Open24h    decimal.NullDecimal `db:"open_24h"`
+	High24h    decimal.NullDecimal `db:"high_24h"`
+	Low24h     decimal.NullDecimal `db:"low_24h"`
+	Volume24h  decimal.NullDecimal `db:"volume_24h"`
 }
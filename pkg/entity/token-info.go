@@ -1,11 +1,15 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type TokenInfo struct {
 	Address  string  `db:"address"`
 	Decimals   uint8   `db:"decimals"`
 	Symbol     string  `db:"symbol"`
-	Price      float64 `db:"price"`
+	Price      decimal.Decimal `db:"price"` // USD
 	LastUpdated time.Time `db:"last_updated"`
 }
\ No newline at end of file
@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff is a jittered exponential backoff
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	// Jitter is the fraction of the delay that is randomized, from 0 to 1
+	Jitter float64
+}
+
+// NewBackoff init backoff doubling from initial up to max with half of the delay jittered
+func NewBackoff(initial, max time.Duration) Backoff {
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if max < initial {
+		max = initial
+	}
+
+	return Backoff{Initial: initial, Max: max, Jitter: 0.5}
+}
+
+// Delay returns the delay before attempt, counting from 1
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+
+	if b.Jitter > 0 && delay > 0 {
+		jitter := time.Duration(b.Jitter * float64(delay))
+		delay -= time.Duration(rand.Int64N(int64(jitter) + 1))
+	}
+
+	return delay
+}
@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.Delay(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	b := NewBackoff(time.Second, time.Minute)
+
+	for i := 0; i < 100; i++ {
+		delay := b.Delay(3)
+		assert.GreaterOrEqual(t, delay, 2*time.Second)
+		assert.LessOrEqual(t, delay, 4*time.Second)
+	}
+}
@@ -0,0 +1,167 @@
+package binance
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"golang.org/x/net/websocket"
+)
+
+// ErrRequireConfigParameters is returned without the url or the symbols of the feed
+var ErrRequireConfigParameters = errors.New("not correct input parameters")
+
+// Subscribe request of the combined stream, Params are streams such as btcusdt@trade
+type Subscribe struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+	ID     int64    `json:"id"`
+}
+
+// NewSubscribe builds the request id subscribing to every stream of the products
+func NewSubscribe(id int64, products, streams []string) ([]byte, error) {
+	params := make([]string, 0, len(products)*len(streams))
+	for _, productID := range products {
+		symbol, ok := Symbol(productID)
+		if !ok {
+			return nil, fmt.Errorf("invalid product id %q", productID)
+		}
+		for _, stream := range streams {
+			params = append(params, symbol+"@"+stream)
+		}
+	}
+
+	return json.Marshal(Subscribe{Method: "SUBSCRIBE", Params: params, ID: id})
+}
+
+// client is the reconnecting connection to the combined stream, kept alive with pings
+type client struct {
+	*exchange.Conn
+	cfg config.BinanceConfig
+}
+
+// NewBinanceClient init client for the Binance spot combined stream
+func NewBinanceClient(cfg config.BinanceConfig) (*client, error) {
+	if cfg.Url == "" || len(cfg.Symbols) == 0 {
+		return nil, ErrRequireConfigParameters
+	}
+
+	c := &client{cfg: cfg}
+	c.Conn = exchange.NewConn(c.dial, exchange.NewBackoff(cfg.ReconnectDelay, cfg.ReconnectMaxDelay), cfg.ReconnectAttempts)
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// dial opens the websocket connection, every read from it counts as activity so the
+// pongs the connection swallows keep it alive
+func (c *client) dial() (*websocket.Conn, error) {
+	location, err := url.Parse(c.cfg.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	origin := *location
+	origin.Scheme, origin.Path, origin.RawQuery = "https", "", ""
+	if location.Scheme == "ws" {
+		origin.Scheme = "http"
+	}
+
+	wsCfg, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+
+	rwc, err := dialTransport(location)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := websocket.NewClient(wsCfg, activityConn{Conn: rwc, touch: c.UpdateHeartbeat})
+	if err != nil {
+		_ = rwc.Close()
+		return nil, err
+	}
+	conn.MaxPayloadBytes = c.cfg.MaxFrameSize
+	if conn.MaxPayloadBytes <= 0 {
+		conn.MaxPayloadBytes = exchange.DefaultMaxFrameSize
+	}
+
+	return conn, nil
+}
+
+func dialTransport(location *url.URL) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	switch location.Scheme {
+	case "ws":
+		port := location.Port()
+		if port == "" {
+			port = "80"
+		}
+		return dialer.Dial("tcp", net.JoinHostPort(location.Hostname(), port))
+	case "wss":
+		port := location.Port()
+		if port == "" {
+			port = "443"
+		}
+		return tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(location.Hostname(), port), &tls.Config{ServerName: location.Hostname()})
+	}
+
+	return nil, fmt.Errorf("unsupported scheme %q", location.Scheme)
+}
+
+// activityConn calls touch on every read
+type activityConn struct {
+	net.Conn
+	touch func()
+}
+
+func (a activityConn) Read(b []byte) (int, error) {
+	n, err := a.Conn.Read(b)
+	if n > 0 {
+		a.touch()
+	}
+	return n, err
+}
+
+// SubscribeToHeartbeats does nothing, the feed has no heartbeat stream and
+// MonitorHeartbeat keeps the connection alive with pings instead
+func (c *client) SubscribeToHeartbeats(context.Context) error { return nil }
+
+// MonitorHeartbeat pings the server every PingInterval and drops a connection
+// nothing was read from for timeout, the reader then reconnects it.
+// The pings of the server are answered by the connection
+func (c *client) MonitorHeartbeat(ctx context.Context, timeout time.Duration) {
+	interval := c.cfg.PingInterval
+	if interval <= 0 {
+		interval = timeout / 2
+	}
+	pings := time.NewTicker(interval)
+	defer pings.Stop()
+	checks := time.NewTicker(min(time.Second, timeout))
+	defer checks.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.Closed():
+			return
+		case <-pings.C:
+			if !c.Reconnecting() {
+				// a failed ping is a lost connection, the reader notices it
+				_ = c.Ping()
+			}
+		case <-checks.C:
+			c.DropStale(timeout, "no pong")
+		}
+	}
+}
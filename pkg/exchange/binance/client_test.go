@@ -0,0 +1,112 @@
+package binance
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestNewSubscribe(t *testing.T) {
+	message, err := NewSubscribe(1, []string{"BTC-USDT", "eth-btc"}, []string{Trade, BookTicker})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"method":"SUBSCRIBE","params":["btcusdt@trade","btcusdt@bookTicker","ethbtc@trade","ethbtc@bookTicker"],"id":1}`, string(message))
+
+	_, err = NewSubscribe(1, []string{"BTCUSDT"}, []string{Trade})
+	assert.Error(t, err)
+}
+
+func TestNewBinanceClient(t *testing.T) {
+	_, err := NewBinanceClient(config.BinanceConfig{Url: "ws://localhost:1"})
+	assert.ErrorIs(t, err, ErrRequireConfigParameters)
+	_, err = NewBinanceClient(config.BinanceConfig{Url: "http://localhost:1", Symbols: []string{"BTC-USDT"}})
+	assert.Error(t, err)
+}
+
+func TestClient_Keepalive(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	connections := 0
+
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		mu.Lock()
+		connections++
+		n := connections
+		mu.Unlock()
+
+		// the first connection never reads, so never answers a ping
+		if n == 1 {
+			<-ws.Request().Context().Done()
+			return
+		}
+
+		var message string
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			return
+		}
+		mu.Lock()
+		received = append(received, message)
+		mu.Unlock()
+		_ = websocket.Message.Send(ws, `{"result":null,"id":1}`)
+
+		// reading answers the pings
+		for websocket.Message.Receive(ws, &message) == nil {
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewBinanceClient(config.BinanceConfig{
+		Url:               "ws" + strings.TrimPrefix(server.URL, "http"),
+		Symbols:           []string{"BTC-USDT"},
+		PingInterval:      20 * time.Millisecond,
+		ReconnectDelay:    time.Millisecond,
+		ReconnectMaxDelay: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer c.CloseConnection()
+
+	var eventsMu sync.Mutex
+	var events []exchange.Event
+	c.OnEvent(func(e exchange.Event) {
+		eventsMu.Lock()
+		events = append(events, e)
+		eventsMu.Unlock()
+	})
+
+	require.NoError(t, c.Subscribe(func() ([]byte, error) {
+		return NewSubscribe(1, []string{"BTC-USDT"}, []string{Trade})
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.MonitorHeartbeat(ctx, 200*time.Millisecond)
+
+	// the silent connection is dropped and the subscription replayed on the next one
+	message, err := c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, `{"result":null,"id":1}`, string(message))
+
+	// the pongs keep the new connection open
+	time.Sleep(600 * time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, 2, connections)
+	assert.Equal(t, []string{`{"method":"SUBSCRIBE","params":["btcusdt@trade"],"id":1}`}, received)
+	mu.Unlock()
+
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	require.Len(t, events, 4)
+	assert.Equal(t, exchange.Connected, events[0].State)
+	assert.Equal(t, exchange.Disconnected, events[1].State)
+	assert.ErrorContains(t, events[1].Err, "no pong")
+	assert.Equal(t, exchange.Reconnected, events[3].State)
+}
@@ -0,0 +1,257 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Source names the messages of the feed
+const Source = "binance"
+
+// Streams of a symbol
+const (
+	Trade      = "trade"
+	BookTicker = "bookTicker"
+	// Depth followed by 5, 10 or 20 is the partial book of as many levels, every second or
+	// with @100ms every 100ms. The diff depth stream is not supported, its updates only apply
+	// to a book loaded from the REST API
+	Depth = "depth"
+)
+
+// Supported reports whether stream, such as trade or depth20@100ms, is converted by ToMessage
+func Supported(stream string) bool {
+	return stream == Trade || stream == BookTicker || partialDepth(stream)
+}
+
+// partialDepth reports whether stream is a partial book stream
+func partialDepth(stream string) bool {
+	levels, speed, _ := strings.Cut(stream, "@")
+	switch levels {
+	case Depth + "5", Depth + "10", Depth + "20":
+		return speed == "" || speed == "100ms"
+	}
+	return false
+}
+
+// Message is a frame of the combined stream: the data of a stream or the response to a request
+type Message struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *APIError       `json:"error"`
+}
+
+// APIError of a rejected request
+type APIError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// TradeEvent of the trade stream, times are unix milliseconds
+type TradeEvent struct {
+	EventType    string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	BuyerIsMaker bool   `json:"m"`
+}
+
+// BookTickerEvent of the bookTicker stream, it has no time
+type BookTickerEvent struct {
+	UpdateID int64  `json:"u"`
+	Symbol   string `json:"s"`
+	BidPrice string `json:"b"`
+	BidQty   string `json:"B"`
+	AskPrice string `json:"a"`
+	AskQty   string `json:"A"`
+}
+
+// DepthEvent of the partial book streams, levels are [price, quantity] best first.
+// It has no time
+type DepthEvent struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// ParseMessage parses a frame of the feed
+func ParseMessage(message []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(message, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if m.Stream == "" && m.ID == nil && m.Error == nil {
+		return nil, errors.New("message without stream")
+	}
+
+	return &m, nil
+}
+
+// IsResponse reports whether the message answers a request such as a subscription
+func (m *Message) IsResponse() bool {
+	return m.Stream == ""
+}
+
+// Err is the error of a rejected request
+func (m *Message) Err() error {
+	if m.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("API error %d: %s", m.Error.Code, m.Error.Msg)
+}
+
+// ToMessage converts the data of a stream received at receivedAt to an entity message
+// of the product of the stream, false for responses
+func (m *Message) ToMessage(receivedAt time.Time) (entity.Message, bool, error) {
+	if m.IsResponse() {
+		return entity.Message{}, false, nil
+	}
+
+	symbol, stream, ok := strings.Cut(m.Stream, "@")
+	if !ok {
+		return entity.Message{}, false, fmt.Errorf("invalid stream %q", m.Stream)
+	}
+	productID, ok := ProductID(symbol)
+	if !ok {
+		return entity.Message{}, false, fmt.Errorf("unknown quote asset of %s", symbol)
+	}
+
+	msg := entity.Message{Source: Source}
+	switch {
+	case stream == Trade:
+		var event TradeEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			return entity.Message{}, false, fmt.Errorf("invalid %s event: %w", m.Stream, err)
+		}
+		order, err := event.ToOrder(productID)
+		if err != nil {
+			return entity.Message{}, false, err
+		}
+		msg.Order = order
+
+	case stream == BookTicker:
+		var event BookTickerEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			return entity.Message{}, false, fmt.Errorf("invalid %s event: %w", m.Stream, err)
+		}
+		ticker, err := event.ToTicker(productID, receivedAt)
+		if err != nil {
+			return entity.Message{}, false, err
+		}
+		msg.Ticker = ticker
+
+	case partialDepth(stream):
+		var event DepthEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			return entity.Message{}, false, fmt.Errorf("invalid %s event: %w", m.Stream, err)
+		}
+		book, err := event.ToBookUpdate(productID, receivedAt)
+		if err != nil {
+			return entity.Message{}, false, err
+		}
+		msg.Book = book
+
+	default:
+		return entity.Message{}, false, fmt.Errorf("unsupported stream %q", m.Stream)
+	}
+
+	return msg, true, nil
+}
+
+// ToOrder converts the trade to a match of productID with the side of the maker like the
+// matches of Coinbase. It has no sequence, trade ids are a counter of their own and not
+// tracked like the sequences of Coinbase
+func (e *TradeEvent) ToOrder(productID string) (*entity.Order, error) {
+	price, err := decimal.NewFromString(e.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+	size, err := decimal.NewFromString(e.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size: %w", err)
+	}
+
+	side := "sell"
+	if e.BuyerIsMaker {
+		side = "buy"
+	}
+
+	return &entity.Order{
+		Type:      "match",
+		Timestamp: time.UnixMilli(e.TradeTime).UnixNano(),
+		ProductID: productID,
+		Side:      side,
+		Size:      size,
+		Price:     price,
+		TradeID:   e.TradeID,
+	}, nil
+}
+
+// ToTicker converts the best bid and ask of productID received at receivedAt, the update id
+// is the sequence of the book and skips the updates below the best levels
+func (e *BookTickerEvent) ToTicker(productID string, receivedAt time.Time) (*entity.Ticker, error) {
+	ticker := &entity.Ticker{
+		Timestamp:  receivedAt.UnixNano(),
+		ReceivedAt: receivedAt.UnixNano(),
+		Symbol:     productID,
+		Sequence:   e.UpdateID,
+	}
+
+	var err error
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *decimal.Decimal
+	}{
+		{"bid", e.BidPrice, &ticker.Bid},
+		{"bid size", e.BidQty, &ticker.BidSize.Decimal},
+		{"ask", e.AskPrice, &ticker.Ask},
+		{"ask size", e.AskQty, &ticker.AskSize.Decimal},
+	} {
+		if *field.dst, err = decimal.NewFromString(field.value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+	}
+	ticker.BidSize.Valid, ticker.AskSize.Valid = true, true
+
+	return ticker, nil
+}
+
+// ToBookUpdate converts the partial book of productID received at receivedAt,
+// it replaces the whole book
+func (e *DepthEvent) ToBookUpdate(productID string, receivedAt time.Time) (*entity.BookUpdate, error) {
+	update := &entity.BookUpdate{ProductID: productID, Snapshot: true, Time: receivedAt}
+
+	for _, side := range []struct {
+		name   string
+		levels [][2]string
+	}{
+		{"buy", e.Bids},
+		{"sell", e.Asks},
+	} {
+		for _, level := range side.levels {
+			price, err := decimal.NewFromString(level[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid book price: %w", err)
+			}
+			size, err := decimal.NewFromString(level[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid book size: %w", err)
+			}
+			update.Changes = append(update.Changes, entity.BookChange{Side: side.name, Price: price, Size: size})
+		}
+	}
+
+	return update, nil
+}
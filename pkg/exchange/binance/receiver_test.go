@@ -0,0 +1,148 @@
+package binance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+var receivedAt = time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)
+
+func toMessage(t *testing.T, message string) entity.Message {
+	t.Helper()
+	m, err := ParseMessage([]byte(message))
+	require.NoError(t, err)
+
+	msg, ok, err := m.ToMessage(receivedAt)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, Source, msg.Source)
+	return msg
+}
+
+func TestProductID(t *testing.T) {
+	for symbol, productID := range map[string]string{
+		"BTCUSDT":   "BTC-USDT",
+		"ethbtc":    "ETH-BTC",
+		"USDCUSDT":  "USDC-USDT",
+		"SOLFDUSD":  "SOL-FDUSD",
+		"1INCHUSDC": "1INCH-USDC",
+	} {
+		got, ok := ProductID(symbol)
+		assert.True(t, ok, symbol)
+		assert.Equal(t, productID, got)
+
+		// and back
+		stream, ok := Symbol(productID)
+		assert.True(t, ok)
+		assert.Equal(t, strings.ToLower(symbol), stream)
+	}
+
+	_, ok := ProductID("USDT")
+	assert.False(t, ok)
+	_, ok = ProductID("BTCXYZ")
+	assert.False(t, ok)
+	// DOGE is only traded as a base asset
+	_, ok = ProductID("SHIBDOGE")
+	assert.False(t, ok)
+	_, ok = Symbol("BTCUSDT")
+	assert.False(t, ok)
+}
+
+func TestMessage_ToMessage_Trade(t *testing.T) {
+	// the buyer is the maker, so the taker sold
+	msg := toMessage(t, `{"stream":"btcusdt@trade","data":{"e":"trade","E":1704164645001,"s":"BTCUSDT","t":3001,"p":"42000.10","q":"1.50000000","T":1704164645000,"m":true,"M":true}}`)
+	assert.Equal(t, &entity.Order{
+		Type:      "match",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(),
+		ProductID: "BTC-USDT",
+		Side:      "buy",
+		Size:      dec("1.50000000"),
+		Price:     dec("42000.10"),
+		TradeID:   3001,
+	}, msg.Order)
+
+	msg = toMessage(t, `{"stream":"btcusdt@trade","data":{"e":"trade","s":"BTCUSDT","t":3002,"p":"1","q":"1","T":1704164645000,"m":false}}`)
+	assert.Equal(t, "sell", msg.Order.Side)
+}
+
+func TestMessage_ToMessage_BookTicker(t *testing.T) {
+	msg := toMessage(t, `{"stream":"ethusdt@bookTicker","data":{"u":400900217,"s":"ETHUSDT","b":"2200.10","B":"31.21","a":"2200.20","A":"40.66"}}`)
+	assert.Equal(t, &entity.Ticker{
+		Timestamp:  receivedAt.UnixNano(),
+		ReceivedAt: receivedAt.UnixNano(),
+		Symbol:     "ETH-USDT",
+		Sequence:   400900217,
+		Bid:        dec("2200.10"),
+		BidSize:    decimal.NewNullDecimal(dec("31.21")),
+		Ask:        dec("2200.20"),
+		AskSize:    decimal.NewNullDecimal(dec("40.66")),
+	}, msg.Ticker)
+}
+
+func TestMessage_ToMessage_Depth(t *testing.T) {
+	// a partial book replaces the book
+	msg := toMessage(t, `{"stream":"btcusdt@depth5@100ms","data":{"lastUpdateId":160,"bids":[["42000.00","1"]],"asks":[["42000.10","2"],["42000.20","3"]]}}`)
+	assert.Equal(t, &entity.BookUpdate{
+		ProductID: "BTC-USDT",
+		Snapshot:  true,
+		Time:      receivedAt,
+		Changes: []entity.BookChange{
+			{Side: "buy", Price: dec("42000.00"), Size: dec("1")},
+			{Side: "sell", Price: dec("42000.10"), Size: dec("2")},
+			{Side: "sell", Price: dec("42000.20"), Size: dec("3")},
+		},
+	}, msg.Book)
+
+}
+
+func TestSupported(t *testing.T) {
+	for _, stream := range []string{"trade", "bookTicker", "depth5", "depth20@100ms"} {
+		assert.True(t, Supported(stream), stream)
+	}
+	// the diff depth stream needs a book loaded from the REST API
+	for _, stream := range []string{"depth", "depth@100ms", "depth15", "depth5@250ms", "aggTrade"} {
+		assert.False(t, Supported(stream), stream)
+	}
+}
+
+func TestMessage_responses(t *testing.T) {
+	m, err := ParseMessage([]byte(`{"result":null,"id":1}`))
+	require.NoError(t, err)
+	assert.True(t, m.IsResponse())
+	assert.NoError(t, m.Err())
+	_, ok, err := m.ToMessage(receivedAt)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	m, err = ParseMessage([]byte(`{"error":{"code":2,"msg":"Invalid request: unknown stream"},"id":1}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, m.Err(), "unknown stream")
+
+	_, err = ParseMessage([]byte(`{"e":"trade"}`))
+	assert.Error(t, err)
+
+	for _, message := range []string{
+		`{"stream":"btcusdt","data":{}}`,
+		`{"stream":"btcxyz@trade","data":{}}`,
+		`{"stream":"btcusdt@aggTrade","data":{}}`,
+		`{"stream":"btcusdt@trade","data":{"p":"x","q":"1"}}`,
+		`{"stream":"btcusdt@bookTicker","data":{"b":"1","B":"1","a":"","A":"1"}}`,
+		`{"stream":"btcusdt@depth20","data":{"bids":[["1","x"]]}}`,
+		`{"stream":"btcusdt@depth@100ms","data":{"e":"depthUpdate","U":157,"u":160,"b":[["42000.00","0"]],"a":[]}}`,
+	} {
+		m, err := ParseMessage([]byte(message))
+		require.NoError(t, err)
+		_, _, err = m.ToMessage(receivedAt)
+		assert.Error(t, err, message)
+	}
+}
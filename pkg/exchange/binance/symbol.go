@@ -0,0 +1,40 @@
+package binance
+
+import (
+	"strings"
+
+	"github.com/nel349/bz-findata/pkg/currency"
+)
+
+// quotes are the quote assets of the spot symbols, longest first so BTCUSDT is
+// BTC-USDT and not BTCU-SDT
+var quotes = []string{
+	"FDUSD",
+	"USDT", "USDC", "TUSD", "BUSD", "EURI",
+	"DAI", "EUR", "GBP", "TRY", "BRL", "JPY", "AUD", "ARS", "MXN", "ZAR", "PLN", "RON", "UAH",
+	"BTC", "ETH", "BNB", "XRP", "TRX", "SOL",
+}
+
+// ProductID normalizes a Binance symbol such as BTCUSDT or btcusdt to the product id
+// BTC-USDT, false when its quote asset is not known
+func ProductID(symbol string) (string, bool) {
+	symbol = strings.ToUpper(symbol)
+	for _, quote := range quotes {
+		base, ok := strings.CutSuffix(symbol, quote)
+		if ok && base != "" {
+			return base + "-" + quote, true
+		}
+	}
+
+	return "", false
+}
+
+// Symbol is the stream symbol of a product id, BTC-USDT is btcusdt
+func Symbol(productID string) (string, bool) {
+	base, quote, ok := currency.Split(productID)
+	if !ok {
+		return "", false
+	}
+
+	return strings.ToLower(base + quote), true
+}
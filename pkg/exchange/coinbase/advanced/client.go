@@ -0,0 +1,72 @@
+package advanced
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/auth"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/nel349/bz-findata/pkg/exchange/coinbase"
+	"github.com/nel349/bz-findata/pkg/secrets"
+)
+
+// Subscribe message of a channel, the feed takes one channel per message
+type Subscribe struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Channel    string   `json:"channel"`
+	JWT        string   `json:"jwt,omitempty"`
+}
+
+// NewSubscribe builds the subscription of channel for products signed with a new JWT of
+// the trading key, a JWT expires after 2 minutes so it is built again on every reconnect.
+// Without a trading key the subscription is not signed, market data does not need it
+func NewSubscribe(ctx context.Context, channel string, products []string) ([]byte, error) {
+	jwt, err := auth.BuildJWT(ctx, "")
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		return nil, err
+	}
+
+	return json.Marshal(Subscribe{
+		Type:       "subscribe",
+		ProductIDs: products,
+		Channel:    channel,
+		JWT:        jwt,
+	})
+}
+
+// client is the connection to the Advanced Trade feed, it reconnects and replays
+// its subscriptions like the Exchange feed connection
+type client struct {
+	exchange.Manager
+}
+
+// NewAdvancedTradeClient init client for Coinbase Advanced Trade at cfg.Exchange.AdvancedUrl
+func NewAdvancedTradeClient(cfg *config.Config) (*client, error) {
+	advanced := *cfg
+	advanced.Exchange.Url = cfg.Exchange.AdvancedUrl
+	advanced.Exchange.Symbols = cfg.Exchange.AdvancedSymbols
+
+	conn, err := coinbase.NewCoinbaseClient(&advanced)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{Manager: conn}, nil
+}
+
+// SubscribeToHeartbeats subscribes to the heartbeats of the connection,
+// they keep it open while the products are quiet
+func (c *client) SubscribeToHeartbeats(ctx context.Context) error {
+	err := c.Subscribe(func() ([]byte, error) {
+		return NewSubscribe(ctx, Heartbeats, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to heartbeats: %w", err)
+	}
+
+	return nil
+}
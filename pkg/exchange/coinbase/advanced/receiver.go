@@ -0,0 +1,375 @@
+package advanced
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Channels of the Advanced Trade feed
+const (
+	MarketTrades  = "market_trades"
+	Level2        = "level2"
+	Ticker        = "ticker"
+	Candles       = "candles"
+	Heartbeats    = "heartbeats"
+	Subscriptions = "subscriptions"
+
+	// l2Data is the channel of the messages of a level2 subscription
+	l2Data = "l2_data"
+)
+
+// CandleGranularity is the length of the bars of the candles channel, in seconds
+const CandleGranularity = 300
+
+// Message is the envelope of every message of the feed, the events depend on the channel.
+// Errors only have Type and Message
+type Message struct {
+	Type        string          `json:"type"`
+	Message     string          `json:"message"`
+	Channel     string          `json:"channel"`
+	ClientID    string          `json:"client_id"`
+	Timestamp   time.Time       `json:"timestamp"`
+	SequenceNum int64           `json:"sequence_num"` // of the connection, across every channel
+	Events      json.RawMessage `json:"events"`
+}
+
+// TradesEvent of the market_trades channel, a snapshot has the latest trades
+type TradesEvent struct {
+	Type   string  `json:"type"`
+	Trades []Trade `json:"trades"`
+}
+
+// Trade of the market_trades channel, Side is the side of the taker
+type Trade struct {
+	TradeID   string    `json:"trade_id"`
+	ProductID string    `json:"product_id"`
+	Price     string    `json:"price"`
+	Size      string    `json:"size"`
+	Side      string    `json:"side"`
+	Time      time.Time `json:"time"`
+}
+
+// L2Event of the level2 channel, a snapshot replaces the whole book
+type L2Event struct {
+	Type      string     `json:"type"`
+	ProductID string     `json:"product_id"`
+	Updates   []L2Update `json:"updates"`
+}
+
+// L2Update of a price level, Side is bid or offer
+type L2Update struct {
+	Side        string    `json:"side"`
+	EventTime   time.Time `json:"event_time"`
+	PriceLevel  string    `json:"price_level"`
+	NewQuantity string    `json:"new_quantity"`
+}
+
+// TickerEvent of the ticker channel
+type TickerEvent struct {
+	Type    string       `json:"type"`
+	Tickers []TickerData `json:"tickers"`
+}
+
+// TickerData of a product
+type TickerData struct {
+	Type            string `json:"type"`
+	ProductID       string `json:"product_id"`
+	Price           string `json:"price"`
+	Volume24h       string `json:"volume_24_h"`
+	Low24h          string `json:"low_24_h"`
+	High24h         string `json:"high_24_h"`
+	BestBid         string `json:"best_bid"`
+	BestBidQuantity string `json:"best_bid_quantity"`
+	BestAsk         string `json:"best_ask"`
+	BestAskQuantity string `json:"best_ask_quantity"`
+}
+
+// CandlesEvent of the candles channel
+type CandlesEvent struct {
+	Type    string       `json:"type"`
+	Candles []CandleData `json:"candles"`
+}
+
+// CandleData of a bar, Start is in unix seconds
+type CandleData struct {
+	Start     string `json:"start"`
+	High      string `json:"high"`
+	Low       string `json:"low"`
+	Open      string `json:"open"`
+	Close     string `json:"close"`
+	Volume    string `json:"volume"`
+	ProductID string `json:"product_id"`
+}
+
+// ParseMessage parses a message of the feed
+func ParseMessage(message []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(message, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if m.Type != "error" && m.Channel == "" {
+		return nil, errors.New("message without channel")
+	}
+
+	return &m, nil
+}
+
+// Err is the error reported by an error message
+func (m *Message) Err() error {
+	if m.Type != "error" {
+		return nil
+	}
+	return fmt.Errorf("API error: %s", m.Message)
+}
+
+// ToMessages converts the events of the message received at receivedAt to entity messages.
+// Trades are matches with the side of the maker like the matches of the Exchange feed, they
+// have no sequence since trade ids are not contiguous and snapshots are sent newest first, the
+// snapshot sent after every subscribe repeats trades already sent. A heartbeat is for every product of the connection,
+// it has no product and no sequence. Subscriptions and errors have no message
+func (m *Message) ToMessages(receivedAt time.Time) ([]entity.Message, error) {
+	switch m.Channel {
+	case MarketTrades:
+		var events []TradesEvent
+		if err := json.Unmarshal(m.Events, &events); err != nil {
+			return nil, fmt.Errorf("invalid %s events: %w", m.Channel, err)
+		}
+		var messages []entity.Message
+		for _, event := range events {
+			for _, trade := range event.Trades {
+				order, err := trade.ToOrder()
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, entity.Message{Order: order})
+			}
+		}
+		// a snapshot has the latest trades first
+		sort.SliceStable(messages, func(i, j int) bool {
+			return messages[i].Order.TradeID < messages[j].Order.TradeID
+		})
+		return messages, nil
+
+	case l2Data, Level2:
+		var events []L2Event
+		if err := json.Unmarshal(m.Events, &events); err != nil {
+			return nil, fmt.Errorf("invalid %s events: %w", m.Channel, err)
+		}
+		messages := make([]entity.Message, 0, len(events))
+		for _, event := range events {
+			book, err := event.ToBookUpdate(m.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, entity.Message{Book: book})
+		}
+		return messages, nil
+
+	case Ticker:
+		var events []TickerEvent
+		if err := json.Unmarshal(m.Events, &events); err != nil {
+			return nil, fmt.Errorf("invalid %s events: %w", m.Channel, err)
+		}
+		var messages []entity.Message
+		for _, event := range events {
+			for _, data := range event.Tickers {
+				ticker, err := data.ToTicker(m.Timestamp, receivedAt, m.SequenceNum)
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, entity.Message{Ticker: ticker})
+			}
+		}
+		return messages, nil
+
+	case Candles:
+		var events []CandlesEvent
+		if err := json.Unmarshal(m.Events, &events); err != nil {
+			return nil, fmt.Errorf("invalid %s events: %w", m.Channel, err)
+		}
+		var messages []entity.Message
+		for _, event := range events {
+			for _, data := range event.Candles {
+				candle, err := data.ToCandle(m.Timestamp)
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, entity.Message{Candle: candle})
+			}
+		}
+		return messages, nil
+
+	case Heartbeats:
+		heartbeatTime := m.Timestamp
+		if heartbeatTime.IsZero() {
+			heartbeatTime = receivedAt
+		}
+		return []entity.Message{{Heartbeat: &entity.Heartbeat{Type: Heartbeats, Time: heartbeatTime}}}, nil
+
+	case Subscriptions:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown channel: %s", m.Channel)
+}
+
+// ToOrder converts the trade to a match
+func (t *Trade) ToOrder() (*entity.Order, error) {
+	tradeID, err := strconv.ParseInt(t.TradeID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trade id: %w", err)
+	}
+	price, err := decimal.NewFromString(t.Price)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+	size, err := decimal.NewFromString(t.Size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size: %w", err)
+	}
+
+	var side string
+	switch strings.ToUpper(t.Side) {
+	case "BUY":
+		side = "sell"
+	case "SELL":
+		side = "buy"
+	default:
+		return nil, fmt.Errorf("invalid side %q", t.Side)
+	}
+
+	return &entity.Order{
+		Type:      "match",
+		Timestamp: t.Time.UnixNano(),
+		ProductID: t.ProductID,
+		Side:      side,
+		Size:      size,
+		Price:     price,
+		TradeID:   tradeID,
+	}, nil
+}
+
+// ToBookUpdate converts the event of a message sent at sent, bids are buys and offers sells
+func (e *L2Event) ToBookUpdate(sent time.Time) (*entity.BookUpdate, error) {
+	changes := make([]entity.BookChange, 0, len(e.Updates))
+	for _, u := range e.Updates {
+		var side string
+		switch u.Side {
+		case "bid":
+			side = "buy"
+		case "offer", "ask":
+			side = "sell"
+		default:
+			return nil, fmt.Errorf("invalid book side %q", u.Side)
+		}
+
+		price, err := decimal.NewFromString(u.PriceLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid book price: %w", err)
+		}
+		size, err := decimal.NewFromString(u.NewQuantity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid book size: %w", err)
+		}
+		changes = append(changes, entity.BookChange{Side: side, Price: price, Size: size})
+	}
+
+	return &entity.BookUpdate{
+		ProductID: e.ProductID,
+		Snapshot:  e.Type == "snapshot",
+		Time:      sent,
+		Changes:   changes,
+	}, nil
+}
+
+// ToTicker converts the ticker of a message sent at sent with sequence, the fields the feed
+// does not have, such as the last trade, are null
+func (d *TickerData) ToTicker(sent, receivedAt time.Time, sequence int64) (*entity.Ticker, error) {
+	bid, err := decimal.NewFromString(d.BestBid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bid: %w", err)
+	}
+
+	ask, err := decimal.NewFromString(d.BestAsk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ask: %w", err)
+	}
+
+	ticker := &entity.Ticker{
+		Timestamp:  receivedAt.UnixNano(),
+		ReceivedAt: receivedAt.UnixNano(),
+		Symbol:     d.ProductID,
+		Sequence:   sequence,
+		Bid:        bid,
+		Ask:        ask,
+	}
+	if !sent.IsZero() {
+		ticker.Timestamp = sent.UnixNano()
+	}
+
+	optional := []struct {
+		name  string
+		value string
+		dst   *decimal.NullDecimal
+	}{
+		{"price", d.Price, &ticker.Price},
+		{"bid size", d.BestBidQuantity, &ticker.BidSize},
+		{"ask size", d.BestAskQuantity, &ticker.AskSize},
+		{"high 24h", d.High24h, &ticker.High24h},
+		{"low 24h", d.Low24h, &ticker.Low24h},
+		{"volume 24h", d.Volume24h, &ticker.Volume24h},
+	}
+	for _, field := range optional {
+		if field.value == "" {
+			continue
+		}
+		if field.dst.Decimal, err = decimal.NewFromString(field.value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		field.dst.Valid = true
+	}
+
+	return ticker, nil
+}
+
+// ToCandle converts the bar of a message sent at sent, the bar is not over before its end
+// so its close time is when it was sent
+func (d *CandleData) ToCandle(sent time.Time) (*entity.Candle, error) {
+	start, err := strconv.ParseInt(d.Start, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid candle start: %w", err)
+	}
+
+	candle := &entity.Candle{
+		ProductID:   d.ProductID,
+		Granularity: CandleGranularity,
+		Timestamp:   time.Unix(start, 0).UnixNano(),
+		OpenTime:    time.Unix(start, 0).UnixNano(),
+		CloseTime:   sent.UnixNano(),
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *decimal.Decimal
+	}{
+		{"open", d.Open, &candle.Open},
+		{"high", d.High, &candle.High},
+		{"low", d.Low, &candle.Low},
+		{"close", d.Close, &candle.Close},
+		{"volume", d.Volume, &candle.Volume},
+	} {
+		if *field.dst, err = decimal.NewFromString(field.value); err != nil {
+			return nil, fmt.Errorf("invalid candle %s: %w", field.name, err)
+		}
+	}
+
+	return candle, nil
+}
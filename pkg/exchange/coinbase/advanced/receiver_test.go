@@ -0,0 +1,135 @@
+package advanced
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+var receivedAt = time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)
+
+func toMessages(t *testing.T, message string) []entity.Message {
+	t.Helper()
+	m, err := ParseMessage([]byte(message))
+	require.NoError(t, err)
+	require.NoError(t, m.Err())
+
+	messages, err := m.ToMessages(receivedAt)
+	require.NoError(t, err)
+	return messages
+}
+
+func TestMessage_ToMessages_MarketTrades(t *testing.T) {
+	// a snapshot has the latest trades first, Side is the taker side
+	messages := toMessages(t, `{"channel":"market_trades","timestamp":"2024-01-02T03:04:05Z","sequence_num":3,"events":[{"type":"snapshot","trades":[
+		{"trade_id":"12","product_id":"BTC-USD","price":"42000.10","size":"0.5","side":"BUY","time":"2024-01-02T03:04:05Z"},
+		{"trade_id":"11","product_id":"BTC-USD","price":"42000.00","size":"0.25","side":"SELL","time":"2024-01-02T03:04:04Z"}]}]}`)
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, &entity.Order{
+		Type:      "match",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 4, 0, time.UTC).UnixNano(),
+		ProductID: "BTC-USD",
+		Side:      "buy",
+		Size:      dec("0.25"),
+		Price:     dec("42000.00"),
+		TradeID:   11,
+	}, messages[0].Order)
+	assert.Equal(t, int64(12), messages[1].Order.TradeID)
+	assert.Equal(t, "sell", messages[1].Order.Side)
+}
+
+func TestMessage_ToMessages_Level2(t *testing.T) {
+	messages := toMessages(t, `{"channel":"l2_data","timestamp":"2024-01-02T03:04:05Z","sequence_num":4,"events":[{"type":"update","product_id":"ETH-USD","updates":[
+		{"side":"bid","event_time":"2024-01-02T03:04:05Z","price_level":"2200.10","new_quantity":"1.5"},
+		{"side":"offer","event_time":"2024-01-02T03:04:05Z","price_level":"2200.20","new_quantity":"0"}]}]}`)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, &entity.BookUpdate{
+		ProductID: "ETH-USD",
+		Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Changes: []entity.BookChange{
+			{Side: "buy", Price: dec("2200.10"), Size: dec("1.5")},
+			{Side: "sell", Price: dec("2200.20"), Size: dec("0")},
+		},
+	}, messages[0].Book)
+}
+
+func TestMessage_ToMessages_Ticker(t *testing.T) {
+	messages := toMessages(t, `{"channel":"ticker","timestamp":"2024-01-02T03:04:05Z","sequence_num":9,"events":[{"type":"update","tickers":[
+		{"type":"ticker","product_id":"BTC-USD","price":"42000.5","volume_24_h":"1000","low_24_h":"41000","high_24_h":"43000","best_bid":"42000.4","best_bid_quantity":"0.1","best_ask":"42000.6","best_ask_quantity":"0.2"}]}]}`)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, &entity.Ticker{
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(),
+		ReceivedAt: receivedAt.UnixNano(),
+		Symbol:     "BTC-USD",
+		Sequence:   9,
+		Price:      decimal.NewNullDecimal(dec("42000.5")),
+		Bid:        dec("42000.4"),
+		BidSize:    decimal.NewNullDecimal(dec("0.1")),
+		Ask:        dec("42000.6"),
+		AskSize:    decimal.NewNullDecimal(dec("0.2")),
+		High24h:    decimal.NewNullDecimal(dec("43000")),
+		Low24h:     decimal.NewNullDecimal(dec("41000")),
+		Volume24h:  decimal.NewNullDecimal(dec("1000")),
+	}, messages[0].Ticker)
+}
+
+func TestMessage_ToMessages_Candles(t *testing.T) {
+	messages := toMessages(t, `{"channel":"candles","timestamp":"2024-01-02T03:04:05Z","sequence_num":1,"events":[{"type":"update","candles":[
+		{"start":"1704164400","high":"2","low":"0.5","open":"1","close":"1.5","volume":"10","product_id":"SOL-USD"}]}]}`)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, &entity.Candle{
+		ProductID:   "SOL-USD",
+		Granularity: CandleGranularity,
+		Timestamp:   time.Unix(1704164400, 0).UnixNano(),
+		OpenTime:    time.Unix(1704164400, 0).UnixNano(),
+		CloseTime:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(),
+		Open:        dec("1"),
+		High:        dec("2"),
+		Low:         dec("0.5"),
+		Close:       dec("1.5"),
+		Volume:      dec("10"),
+	}, messages[0].Candle)
+}
+
+func TestMessage_ToMessages_Heartbeats(t *testing.T) {
+	messages := toMessages(t, `{"channel":"heartbeats","timestamp":"2024-01-02T03:04:05Z","sequence_num":2,"events":[{"current_time":"2024-01-02 03:04:05","heartbeat_counter":7}]}`)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, &entity.Heartbeat{Type: Heartbeats, Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, messages[0].Heartbeat)
+
+	assert.Empty(t, toMessages(t, `{"channel":"subscriptions","events":[{"subscriptions":{"ticker":["BTC-USD"]}}]}`))
+}
+
+func TestMessage_errors(t *testing.T) {
+	m, err := ParseMessage([]byte(`{"type":"error","message":"authentication failure"}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, m.Err(), "authentication failure")
+
+	_, err = ParseMessage([]byte(`{"type":"update"}`))
+	assert.Error(t, err)
+
+	for _, message := range []string{
+		`{"channel":"status","events":[]}`,
+		`{"channel":"market_trades","events":[{"trades":[{"trade_id":"x","price":"1","size":"1","side":"BUY"}]}]}`,
+		`{"channel":"market_trades","events":[{"trades":[{"trade_id":"1","price":"1","size":"1","side":"UNKNOWN"}]}]}`,
+		`{"channel":"l2_data","events":[{"updates":[{"side":"middle","price_level":"1","new_quantity":"1"}]}]}`,
+		`{"channel":"candles","events":[{"candles":[{"start":"now"}]}]}`,
+	} {
+		m, err := ParseMessage([]byte(message))
+		require.NoError(t, err)
+		_, err = m.ToMessages(receivedAt)
+		assert.Error(t, err, message)
+	}
+}
@@ -1,12 +1,13 @@
 package coinbase
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"time"
-	awslocal "github.com/nel349/bz-findata/pkg/aws"
+	"github.com/nel349/bz-findata/pkg/secrets"
 )
 
 // authentication for web sockets
@@ -16,9 +17,13 @@ type Auth struct {
 	Passphrase string
 }
 
-func NewAuth() *Auth {
-	wsApiKey, wsApiSecret, wsApiPassphrase := GetWSCredentials()
-	return &Auth{Key: wsApiKey, Secret: wsApiSecret, Passphrase: wsApiPassphrase}
+// NewAuth loads the websocket credentials from the default secret provider
+func NewAuth(ctx context.Context) (*Auth, error) {
+	wsApiKey, wsApiSecret, wsApiPassphrase, err := GetWSCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Auth{Key: wsApiKey, Secret: wsApiSecret, Passphrase: wsApiPassphrase}, nil
 }
 // generate signature
 func (a *Auth) GenerateSignature() (string, int64, error) {
@@ -50,27 +55,12 @@ func (a *Auth) GenerateSignature() (string, int64, error) {
 	return signature, timestamp, nil
 }
 
-func GetWSCredentials() (string, string, string) {
-    // wsApiKey := os.Getenv("COINBASE_WS_API_KEY")
-    // wsApiSecret := os.Getenv("COINBASE_WS_API_SECRET")
-    // wsApiPassphrase := os.Getenv("COINBASE_WS_API_PASSPHRASE")
-
-	secret, err := awslocal.GetDefaultCoinbaseSecret()
+// GetWSCredentials looks the websocket credentials up in the default secret provider,
+// all of them are needed
+func GetWSCredentials(ctx context.Context) (string, string, string, error) {
+	values, err := secrets.GetAll(ctx, "COINBASE_WS_API_KEY", "COINBASE_WS_API_SECRET", "COINBASE_WS_API_PASSPHRASE")
 	if err != nil {
-		fmt.Println("Failed to retrieve secret", err)
+		return "", "", "", fmt.Errorf("coinbase websocket credentials: %w", err)
 	}
-
-	wsApiKey := secret.COINBASE_WS_API_KEY
-	wsApiSecret := secret.COINBASE_WS_API_SECRET
-	wsApiPassphrase := secret.COINBASE_WS_API_PASSPHRASE
-
-    // fmt.Printf("Debug: API Key length: %d\n", len(wsApiKey))
-    // fmt.Printf("Debug: API Secret length: %d\n", len(wsApiSecret))
-    // fmt.Printf("Debug: API Passphrase length: %d\n", len(wsApiPassphrase))
-
-    if wsApiKey == "" || wsApiSecret == "" || wsApiPassphrase == "" {
-        fmt.Println("One or more required environment variables are not set")
-    }
-
-	return wsApiKey, wsApiSecret, wsApiPassphrase
+	return values[0], values[1], values[2], nil
 }
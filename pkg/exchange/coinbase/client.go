@@ -4,11 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/exchange"
 	"golang.org/x/net/websocket"
 )
 
@@ -30,12 +29,10 @@ type SubscribeHeartbeat struct {
 	} `json:"channels"`
 }
 
+// client is the reconnecting connection to the Exchange feed with its heartbeat channel
 type client struct {
+	*exchange.Conn
 	cfg *config.Config
-	*websocket.Conn
-	lastHeartbeat     time.Time
-	reconnectAttempts int
-	lastReconnectTime time.Time
 }
 
 // NewCoinbaseClient init client for Coinbase
@@ -44,17 +41,38 @@ func NewCoinbaseClient(cfg *config.Config) (*client, error) {
 		return nil, fmt.Errorf("%s", ErrRequireConfigParameters)
 	}
 
-	conn, err := websocket.Dial(cfg.Exchange.Url, cfg.Exchange.Protocol, cfg.Exchange.Origin)
+	conn := exchange.NewConn(
+		func() (*websocket.Conn, error) { return dial(cfg.Exchange) },
+		exchange.NewBackoff(cfg.Exchange.ReconnectDelay, cfg.Exchange.ReconnectMaxDelay),
+		cfg.Exchange.ReconnectAttempts,
+	)
+	if err := conn.Connect(); err != nil {
+		return nil, err
+	}
+
+	return &client{Conn: conn, cfg: cfg}, nil
+}
+
+// dial opens the websocket connection, frames above the max frame size are rejected
+func dial(cfg config.ExchangeConfig) (*websocket.Conn, error) {
+	conn, err := websocket.Dial(cfg.Url, cfg.Protocol, cfg.Origin)
 	if err != nil {
 		return nil, err
 	}
+	conn.MaxPayloadBytes = maxFrameSize(cfg)
 
-	return &client{cfg, conn, time.Now(), 0, time.Now()}, nil
+	return conn, nil
 }
 
-func (c *client) SubscribeToHeartbeats(ctx context.Context) {
+func maxFrameSize(cfg config.ExchangeConfig) int {
+	if cfg.MaxFrameSize <= 0 {
+		return exchange.DefaultMaxFrameSize
+	}
+	return cfg.MaxFrameSize
+}
 
-	fmt.Println("Subscribing to heartbeats...")
+// SubscribeToHeartbeats subscribes to the heartbeats of the products
+func (c *client) SubscribeToHeartbeats(ctx context.Context) error {
 	subscribeMsg := SubscribeHeartbeat{
 		Type: "subscribe",
 		Channels: []struct {
@@ -68,21 +86,18 @@ func (c *client) SubscribeToHeartbeats(ctx context.Context) {
 		},
 	}
 
-	subscribeBytes, err := json.Marshal(subscribeMsg)
+	err := c.Subscribe(func() ([]byte, error) {
+		return json.Marshal(subscribeMsg)
+	})
 	if err != nil {
-		fmt.Println("error marshaling subscribe message: %w", err)
-		return
+		return fmt.Errorf("failed to subscribe to heartbeats: %w", err)
 	}
 
-	// Send initial subscription message
-	_, err = c.WriteData(subscribeBytes)
-	if err != nil {
-		fmt.Printf("error writing initial subscribe message: %v\n", err)
-		return
-	}
+	return nil
 }
 
-// Add a method to check and handle heartbeat timeout
+// MonitorHeartbeat drops a connection without heartbeat for timeout,
+// the reader then reconnects it
 func (c *client) MonitorHeartbeat(ctx context.Context, timeout time.Duration) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -91,90 +106,10 @@ func (c *client) MonitorHeartbeat(ctx context.Context, timeout time.Duration) {
 		select {
 		case <-ctx.Done():
 			return
+		case <-c.Closed():
+			return
 		case <-ticker.C:
-			if time.Since(c.lastHeartbeat) > timeout {
-
-				// Increment attempts before trying to reconnect
-				c.reconnectAttempts++
-
-				// check if we've exceeded the retry limit first
-				if c.reconnectAttempts >= 5 {
-					log.Println("Too many reconnection attempts, terminating process for container restart")
-					// Signal for clean shutdown instead of panic
-					os.Exit(1) // Non-zero exit code signals error to Docker
-				}
-
-				fmt.Printf("Heartbeat timeout detected, time since last heartbeat: %s\n", time.Since(c.lastHeartbeat))
-				fmt.Printf("Time since last reconnection: %s\n", time.Since(c.lastReconnectTime))
-				fmt.Printf("Reconnection attempts: %d\n", c.reconnectAttempts)
-
-				// Add backoff between reconnection attempts
-				backoff := time.Duration(1) * time.Second // TODO: make it dynamic based on attempts
-				time.Sleep(backoff)
-
-				if err := c.reconnect(ctx, c.cfg); err != nil {
-					c.reconnectAttempts++
-					c.lastReconnectTime = time.Now()
-					log.Printf("Reconnection failed: %v", err)
-					continue
-				}
-
-				// Only reset attempts on successful reconnection
-				c.lastReconnectTime = time.Now()
-			}
+			c.DropStale(timeout, "heartbeat timeout")
 		}
 	}
 }
-
-// Add method to update last heartbeat time
-func (c *client) UpdateHeartbeat() {
-	c.lastHeartbeat = time.Now()
-	// Reset attempts after successful heartbeat
-	if c.reconnectAttempts > 0 {
-		log.Printf("Connection stabilized, resetting reconnection attempts")
-		c.reconnectAttempts = 0
-	}
-}
-
-// Add reconnect method
-func (c *client) reconnect(ctx context.Context, cfg *config.Config) error {
-	if err := c.CloseConnection(); err != nil {
-		log.Printf("Warning: error closing connection: %v", err)
-	}
-
-	fmt.Println("Reconnecting...")
-
-	conn, err := websocket.Dial(cfg.Exchange.Url, cfg.Exchange.Protocol, cfg.Exchange.Origin)
-	if err != nil {
-		return fmt.Errorf("reconnection failed: %w", err)
-	}
-
-	c.Conn = conn
-
-	// Pass context through subscription
-	c.SubscribeToHeartbeats(ctx)
-
-	// Update heartbeat time after successful connection
-	c.lastHeartbeat = time.Now()
-
-	return nil
-}
-
-func (c *client) WriteData(message []byte) (int, error) {
-	return c.Write(message)
-}
-
-func (c *client) ReadData() ([]byte, error) {
-	var message = make([]byte, 512) //TODO need change global? 1MB
-
-	n, err := c.Read(message)
-	if err != nil {
-		return nil, err
-	}
-
-	return message[:n], nil
-}
-
-func (c *client) CloseConnection() error {
-	return c.Close()
-}
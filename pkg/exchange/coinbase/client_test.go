@@ -0,0 +1,146 @@
+package coinbase
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/config"
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestClient_ReadData(t *testing.T) {
+	large := `{"type":"ticker","product_id":"` + strings.Repeat("X", 2048) + `"}`
+	frames := []string{`{"type":"subscriptions"}`, large, `{"type":"heartbeat"}`}
+
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		for _, frame := range frames {
+			if err := websocket.Message.Send(ws, frame); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Url:          "ws" + strings.TrimPrefix(server.URL, "http"),
+		Origin:       server.URL,
+		MaxFrameSize: 1024,
+	}}
+	c, err := NewCoinbaseClient(cfg)
+	require.NoError(t, err)
+	defer c.CloseConnection()
+
+	message, err := c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, frames[0], string(message))
+
+	_, err = c.ReadData()
+	var tooLarge *exchange.FrameTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, 1024, tooLarge.Max)
+
+	// the oversized frame is drained, the next one is whole
+	message, err = c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, frames[2], string(message))
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	var mu sync.Mutex
+	var received [][]string
+	connections := 0
+
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		mu.Lock()
+		connections++
+		n := connections
+		received = append(received, nil)
+		mu.Unlock()
+
+		// the first connection drops after the subscriptions
+		for i := 0; i < 2; i++ {
+			var message string
+			if err := websocket.Message.Receive(ws, &message); err != nil {
+				return
+			}
+			mu.Lock()
+			received[n-1] = append(received[n-1], message)
+			mu.Unlock()
+		}
+		if n == 1 {
+			return
+		}
+		_ = websocket.Message.Send(ws, `{"type":"heartbeat"}`)
+		var ignored string
+		_ = websocket.Message.Receive(ws, &ignored)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Url:               "ws" + strings.TrimPrefix(server.URL, "http"),
+		Origin:            server.URL,
+		ReconnectDelay:    time.Millisecond,
+		ReconnectMaxDelay: time.Millisecond,
+	}}
+	c, err := NewCoinbaseClient(cfg)
+	require.NoError(t, err)
+	defer c.CloseConnection()
+
+	var events []exchange.State
+	c.OnEvent(func(e exchange.Event) { events = append(events, e.State) })
+
+	signed := 0
+	require.NoError(t, c.Subscribe(func() ([]byte, error) {
+		signed++
+		return []byte(`{"type":"subscribe","signature":"` + string(rune('0'+signed)) + `"}`), nil
+	}))
+	require.NoError(t, c.Subscribe(func() ([]byte, error) {
+		return []byte(`{"type":"subscribe","channels":["heartbeat"]}`), nil
+	}))
+
+	// the reader survives the dropped connection
+	message, err := c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"heartbeat"}`, string(message))
+
+	assert.Equal(t, 2, signed)
+	assert.Equal(t, []exchange.State{exchange.Connected, exchange.Disconnected, exchange.Reconnecting, exchange.Reconnected}, events)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	assert.Equal(t, []string{`{"type":"subscribe","signature":"1"}`, `{"type":"subscribe","channels":["heartbeat"]}`}, received[0])
+	assert.Equal(t, []string{`{"type":"subscribe","signature":"2"}`, `{"type":"subscribe","channels":["heartbeat"]}`}, received[1])
+}
+
+func TestClient_ReconnectFailed(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {}))
+
+	cfg := &config.Config{Exchange: config.ExchangeConfig{
+		Url:               "ws" + strings.TrimPrefix(server.URL, "http"),
+		Origin:            server.URL,
+		ReconnectDelay:    time.Millisecond,
+		ReconnectMaxDelay: time.Millisecond,
+		ReconnectAttempts: 2,
+	}}
+	c, err := NewCoinbaseClient(cfg)
+	require.NoError(t, err)
+	defer c.CloseConnection()
+
+	// nothing to reconnect to
+	server.Close()
+
+	var last exchange.Event
+	c.OnEvent(func(e exchange.Event) { last = e })
+
+	_, err = c.ReadData()
+	assert.ErrorIs(t, err, exchange.ErrReconnectFailed)
+	assert.Equal(t, exchange.ReconnectFailed, last.State)
+	assert.Equal(t, 2, last.Attempt)
+}
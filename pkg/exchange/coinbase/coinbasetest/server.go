@@ -0,0 +1,502 @@
+// Package coinbasetest provides a local Coinbase Exchange websocket feed for tests
+package coinbasetest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Credentials expected from authenticated subscriptions, Secret is base64 like Coinbase secrets
+type Credentials struct {
+	Key        string
+	Secret     string
+	Passphrase string
+}
+
+// Options of the server
+type Options struct {
+	// Credentials checks the signature of product subscriptions when set
+	Credentials *Credentials
+	// HeartbeatInterval of the heartbeat channel, heartbeats are only sent by SendHeartbeats when zero
+	HeartbeatInterval time.Duration
+}
+
+// Subscription is a subscribe message received by the server
+type Subscription struct {
+	ProductIDs []string
+	Channels   []string
+	Key        string
+	Signature  string
+	Timestamp  string
+	Passphrase string
+}
+
+// subscribeMessage accepts channels as names or as {name, product_ids} objects
+type subscribeMessage struct {
+	Type       string            `json:"type"`
+	ProductIDs []string          `json:"product_ids"`
+	Channels   []json.RawMessage `json:"channels"`
+	Key        string            `json:"key"`
+	Signature  string            `json:"signature"`
+	Timestamp  json.Number       `json:"timestamp"`
+	Passphrase string            `json:"passphrase"`
+}
+
+type channel struct {
+	Name       string   `json:"name"`
+	ProductIDs []string `json:"product_ids"`
+}
+
+// Server is a local websocket feed speaking the Coinbase Exchange protocol
+type Server struct {
+	// URL for EXCHANGE_URL and Origin for EXCHANGE_ORIGIN
+	URL    string
+	Origin string
+
+	opts   Options
+	server *httptest.Server
+
+	mu            sync.Mutex
+	conns         map[*conn]struct{}
+	connections   int
+	subscriptions []Subscription
+	sequences     map[string]int64
+	tradeID       int64
+	changed       chan struct{}
+}
+
+type conn struct {
+	mu       sync.Mutex
+	ws       *websocket.Conn
+	channels map[string]map[string]bool // channel > products
+}
+
+// NewServer starts a local feed, Close it when done
+func NewServer(opts Options) *Server {
+	s := &Server{
+		opts:      opts,
+		conns:     make(map[*conn]struct{}),
+		sequences: make(map[string]int64),
+		changed:   make(chan struct{}),
+	}
+
+	s.server = httptest.NewServer(websocket.Handler(s.handle))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+	s.Origin = s.server.URL
+
+	return s
+}
+
+// Close drops every connection and stops the server
+func (s *Server) Close() {
+	s.Disconnect()
+	s.server.Close()
+}
+
+// Connections returns the number of connections accepted so far
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connections
+}
+
+// Subscriptions returns the accepted subscribe messages in order
+func (s *Server) Subscriptions() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Subscription(nil), s.subscriptions...)
+}
+
+// WaitSubscriptions waits until n subscribe messages were accepted
+func (s *Server) WaitSubscriptions(n int, timeout time.Duration) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		got, changed := len(s.subscriptions), s.changed
+		s.mu.Unlock()
+
+		if got >= n {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return fmt.Errorf("got %d subscriptions, expected %d", got, n)
+		}
+	}
+}
+
+// Disconnect drops every connection, as the exchange does on maintenance
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	conns := s.conns
+	s.conns = make(map[*conn]struct{})
+	s.mu.Unlock()
+
+	for c := range conns {
+		_ = c.ws.Close()
+	}
+}
+
+// Send writes message as JSON to every connection subscribed to its product,
+// messages without product_id go to every connection
+func (s *Server) Send(message map[string]interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		panic(err)
+	}
+
+	productID, _ := message["product_id"].(string)
+	for _, c := range s.connected() {
+		if productID == "" || c.subscribed("", productID) {
+			_ = c.send(data)
+		}
+	}
+}
+
+// SendFrame writes a raw frame to every connection, it does not need to be valid JSON
+func (s *Server) SendFrame(frame string) {
+	for _, c := range s.connected() {
+		_ = c.send([]byte(frame))
+	}
+}
+
+// SendError writes an error message to every connection
+func (s *Server) SendError(message, reason string) {
+	s.Send(map[string]interface{}{"type": "error", "message": message, "reason": reason})
+}
+
+// SendTicker writes a ticker of product after a trade between bid and ask, and returns its sequence
+func (s *Server) SendTicker(productID string, bid, ask float64) int64 {
+	seq := s.next(productID)
+
+	s.mu.Lock()
+	s.tradeID++
+	tradeID := s.tradeID
+	s.mu.Unlock()
+
+	price := (bid + ask) / 2
+	s.Send(map[string]interface{}{
+		"type":          "ticker",
+		"sequence":      seq,
+		"product_id":    productID,
+		"price":         formatFloat(price),
+		"open_24h":      formatFloat(price * 0.99),
+		"volume_24h":    "1000",
+		"low_24h":       formatFloat(price * 0.98),
+		"high_24h":      formatFloat(price * 1.02),
+		"best_bid":      formatFloat(bid),
+		"best_bid_size": "1",
+		"best_ask":      formatFloat(ask),
+		"best_ask_size": "1",
+		"side":          "buy",
+		"time":          time.Now().UTC().Format(time.RFC3339Nano),
+		"trade_id":      tradeID,
+		"last_size":     "0.1",
+	})
+
+	return seq
+}
+
+// SendMatch writes a full channel match of product and returns its sequence
+func (s *Server) SendMatch(productID, side string, size, price float64) int64 {
+	seq := s.next(productID)
+
+	s.mu.Lock()
+	s.tradeID++
+	tradeID := s.tradeID
+	s.mu.Unlock()
+
+	s.Send(map[string]interface{}{
+		"type":           "match",
+		"trade_id":       tradeID,
+		"sequence":       seq,
+		"maker_order_id": fmt.Sprintf("maker-%d", tradeID),
+		"taker_order_id": fmt.Sprintf("taker-%d", tradeID),
+		"time":           time.Now().UTC().Format(time.RFC3339Nano),
+		"product_id":     productID,
+		"size":           formatFloat(size),
+		"price":          formatFloat(price),
+		"side":           side,
+	})
+
+	return seq
+}
+
+// Skip drops n sequences of product, the next message reveals a gap
+func (s *Server) Skip(productID string, n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequences[productID] += n
+}
+
+// SendHeartbeats writes a heartbeat of every product to the connections subscribed to heartbeats
+func (s *Server) SendHeartbeats() {
+	for _, c := range s.connected() {
+		s.sendHeartbeats(c)
+	}
+}
+
+// sendHeartbeats announces the last sequence of every product c subscribed to heartbeats for
+func (s *Server) sendHeartbeats(c *conn) {
+	for _, productID := range c.products("heartbeat") {
+		s.mu.Lock()
+		seq, tradeID := s.sequences[productID], s.tradeID
+		s.mu.Unlock()
+
+		data, _ := json.Marshal(map[string]interface{}{
+			"type":          "heartbeat",
+			"sequence":      seq,
+			"last_trade_id": tradeID,
+			"product_id":    productID,
+			"time":          time.Now().UTC().Format(time.RFC3339Nano),
+		})
+		_ = c.send(data)
+	}
+}
+
+func (s *Server) handle(ws *websocket.Conn) {
+	c := &conn{ws: ws, channels: make(map[string]map[string]bool)}
+
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.connections++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	if s.opts.HeartbeatInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go s.heartbeats(c, done)
+	}
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return
+		}
+
+		var msg subscribeMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "subscribe" {
+			_ = c.sendError("Failed to subscribe", "invalid message")
+			continue
+		}
+
+		sub, channels, err := parseSubscription(msg)
+		if err != nil {
+			_ = c.sendError("Failed to subscribe", err.Error())
+			continue
+		}
+		if err := s.authenticate(sub, channels); err != nil {
+			_ = c.sendError("Authentication Failed", err.Error())
+			continue
+		}
+
+		c.subscribe(channels)
+		_ = c.sendSubscriptions()
+
+		s.mu.Lock()
+		s.subscriptions = append(s.subscriptions, sub)
+		close(s.changed)
+		s.changed = make(chan struct{})
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) heartbeats(c *conn, done <-chan struct{}) {
+	ticker := time.NewTicker(s.opts.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.sendHeartbeats(c)
+		}
+	}
+}
+
+// authenticate checks the signature of subscriptions to more than heartbeats
+func (s *Server) authenticate(sub Subscription, channels []channel) error {
+	creds := s.opts.Credentials
+	if creds == nil {
+		return nil
+	}
+
+	heartbeatOnly := true
+	for _, ch := range channels {
+		heartbeatOnly = heartbeatOnly && ch.Name == "heartbeat"
+	}
+	if heartbeatOnly {
+		return nil
+	}
+
+	if sub.Key != creds.Key || sub.Passphrase != creds.Passphrase {
+		return fmt.Errorf("invalid key")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(creds.Secret)
+	if err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(sub.Timestamp + "GET/users/self/verify"))
+	if base64.StdEncoding.EncodeToString(mac.Sum(nil)) != sub.Signature {
+		return fmt.Errorf("invalid signature")
+	}
+
+	return nil
+}
+
+func (s *Server) connected() []*conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+
+	return conns
+}
+
+func (s *Server) next(productID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequences[productID]++
+
+	return s.sequences[productID]
+}
+
+func parseSubscription(msg subscribeMessage) (Subscription, []channel, error) {
+	sub := Subscription{
+		ProductIDs: msg.ProductIDs,
+		Key:        msg.Key,
+		Signature:  msg.Signature,
+		Timestamp:  msg.Timestamp.String(),
+		Passphrase: msg.Passphrase,
+	}
+
+	channels := make([]channel, 0, len(msg.Channels))
+	for _, raw := range msg.Channels {
+		var ch channel
+		if err := json.Unmarshal(raw, &ch.Name); err != nil {
+			if err := json.Unmarshal(raw, &ch); err != nil {
+				return sub, nil, fmt.Errorf("invalid channel %s", raw)
+			}
+		}
+		if len(ch.ProductIDs) == 0 {
+			ch.ProductIDs = msg.ProductIDs
+		}
+		if ch.Name == "" || len(ch.ProductIDs) == 0 {
+			return sub, nil, fmt.Errorf("channel %s has no products", raw)
+		}
+
+		channels = append(channels, ch)
+		sub.Channels = append(sub.Channels, ch.Name)
+	}
+	if len(channels) == 0 {
+		return sub, nil, fmt.Errorf("no channels")
+	}
+
+	return sub, channels, nil
+}
+
+func (c *conn) subscribe(channels []channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ch := range channels {
+		if c.channels[ch.Name] == nil {
+			c.channels[ch.Name] = make(map[string]bool)
+		}
+		for _, productID := range ch.ProductIDs {
+			c.channels[ch.Name][productID] = true
+		}
+	}
+}
+
+// subscribed reports whether productID is subscribed on name, or on any channel when name is empty
+func (c *conn) subscribed(name, productID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch, products := range c.channels {
+		if (name == "" || ch == name) && products[productID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *conn) products(name string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	products := make([]string, 0, len(c.channels[name]))
+	for productID := range c.channels[name] {
+		products = append(products, productID)
+	}
+
+	return products
+}
+
+func (c *conn) sendSubscriptions() error {
+	c.mu.Lock()
+	channels := make([]channel, 0, len(c.channels))
+	for name, products := range c.channels {
+		ch := channel{Name: name}
+		for productID := range products {
+			ch.ProductIDs = append(ch.ProductIDs, productID)
+		}
+		channels = append(channels, ch)
+	}
+	c.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]interface{}{"type": "subscriptions", "channels": channels})
+
+	return c.send(data)
+}
+
+func (c *conn) sendError(message, reason string) error {
+	data, _ := json.Marshal(map[string]interface{}{"type": "error", "message": message, "reason": reason})
+
+	return c.send(data)
+}
+
+// send writes a frame, writes of heartbeats and scripted messages don't interleave
+func (c *conn) send(data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return websocket.Message.Send(c.ws, string(data))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
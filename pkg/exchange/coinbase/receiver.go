@@ -1,12 +1,13 @@
 package coinbase
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
 )
 
 // Base Response struct
@@ -20,8 +21,20 @@ type Response struct {
 // TickerResponse for ticker data
 type TickerResponse struct {
 	Response
-	BestBid string `json:"best_bid"`
-	BestAsk string `json:"best_ask"`
+	Sequence    int64     `json:"sequence"`
+	Price       string    `json:"price"`
+	Open24h     string    `json:"open_24h"`
+	Volume24h   string    `json:"volume_24h"`
+	Low24h      string    `json:"low_24h"`
+	High24h     string    `json:"high_24h"`
+	BestBid     string    `json:"best_bid"`
+	BestBidSize string    `json:"best_bid_size"`
+	BestAsk     string    `json:"best_ask"`
+	BestAskSize string    `json:"best_ask_size"`
+	Side        string    `json:"side"`
+	Time        time.Time `json:"time"`
+	TradeID     int64     `json:"trade_id"`
+	LastSize    string    `json:"last_size"`
 }
 
 // OrderResponse to handler all order data (received, open, done, match, etc.)
@@ -41,6 +54,8 @@ type OrderResponse struct {
 	TradeID       int64     `json:"trade_id,omitempty"` // Only for match
 	MakerOrderID  string    `json:"maker_order_id,omitempty"` // Only for match
 	TakerOrderID  string    `json:"taker_order_id,omitempty"` // Only for match
+	NewSize       string    `json:"new_size,omitempty"`       // Only for change
+	OldSize       string    `json:"old_size,omitempty"`       // Only for change
 }
 
 type HeartbeatResponse struct {
@@ -50,6 +65,21 @@ type HeartbeatResponse struct {
 	ProductID   string    `json:"product_id"`
 	Time        time.Time `json:"time"`
 }
+// SnapshotResponse for level2 book snapshot, levels are [price, size] pairs
+type SnapshotResponse struct {
+	Response
+	Bids [][2]string `json:"bids"`
+	Asks [][2]string `json:"asks"`
+	Time time.Time   `json:"time"`
+}
+
+// L2UpdateResponse for level2 book changes, changes are [side, price, size] triples
+type L2UpdateResponse struct {
+	Response
+	Changes [][3]string `json:"changes"`
+	Time    time.Time   `json:"time"`
+}
+
 type ResponseType int
 
 const (
@@ -64,6 +94,8 @@ const (
 	Done
 	Match
 	Change
+	Snapshot
+	L2Update
 )
 
 var responseTypeNames = [...]string{
@@ -78,6 +110,8 @@ var responseTypeNames = [...]string{
 	"done",
 	"match",
 	"change",
+	"snapshot",
+	"l2update",
 }
 
 func (r ResponseType) String() string {
@@ -85,59 +119,109 @@ func (r ResponseType) String() string {
 }
 
 // Conversion methods
-func (r *TickerResponse) ToTicker() (*entity.Ticker, error) {
-	bid, err := strconv.ParseFloat(r.BestBid, 64)
+// ToTicker converts the ticker received at receivedAt (unix nano), the receive time
+// stands for the exchange time when the ticker has none. Fields missing from
+// the ticker, such as the last trade of a ticker sent on subscribe, are null.
+func (r *TickerResponse) ToTicker(receivedAt int64) (*entity.Ticker, error) {
+	bid, err := decimal.NewFromString(r.BestBid)
 	if err != nil {
 		return nil, fmt.Errorf("invalid bid: %w", err)
 	}
 
-	ask, err := strconv.ParseFloat(r.BestAsk, 64)
+	ask, err := decimal.NewFromString(r.BestAsk)
 	if err != nil {
 		return nil, fmt.Errorf("invalid ask: %w", err)
 	}
 
-	return &entity.Ticker{
-		Timestamp: time.Now().UnixNano(),
-		Bid:       bid,
-		Ask:       ask,
-		Symbol:    r.ProductID,
-	}, nil
+	ticker := &entity.Ticker{
+		Timestamp:  receivedAt,
+		ReceivedAt: receivedAt,
+		Symbol:     r.ProductID,
+		Sequence:   r.Sequence,
+		Side:       sql.NullString{String: r.Side, Valid: r.Side != ""},
+		TradeID:    sql.NullInt64{Int64: r.TradeID, Valid: r.TradeID != 0},
+		Bid:        bid,
+		Ask:        ask,
+	}
+	if !r.Time.IsZero() {
+		ticker.Timestamp = r.Time.UnixNano()
+	}
+
+	optional := []struct {
+		name  string
+		value string
+		dst   *decimal.NullDecimal
+	}{
+		{"price", r.Price, &ticker.Price},
+		{"last size", r.LastSize, &ticker.LastSize},
+		{"bid size", r.BestBidSize, &ticker.BidSize},
+		{"ask size", r.BestAskSize, &ticker.AskSize},
+		{"open 24h", r.Open24h, &ticker.Open24h},
+		{"high 24h", r.High24h, &ticker.High24h},
+		{"low 24h", r.Low24h, &ticker.Low24h},
+		{"volume 24h", r.Volume24h, &ticker.Volume24h},
+	}
+	for _, field := range optional {
+		if field.value == "" {
+			continue
+		}
+		if field.dst.Decimal, err = decimal.NewFromString(field.value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		field.dst.Valid = true
+	}
+
+	return ticker, nil
 }
 
 // Update the ToOrderResponse method to handle string conversions
 func (r *OrderResponse) ToOrderResponse() (*entity.Order, error) {
 
-	var size, price, funds, remainingSize float64
+	var size, price, funds, remainingSize, newSize, oldSize decimal.Decimal
 	var err error
 
 	if r.Size != "" {
-		size, err = strconv.ParseFloat(r.Size, 64)
+		size, err = decimal.NewFromString(r.Size)
 		if err != nil {
 			return nil, fmt.Errorf("invalid size: %w", err)
 		}
 	}
 
 	if r.Price != "" {
-		price, err = strconv.ParseFloat(r.Price, 64)
+		price, err = decimal.NewFromString(r.Price)
 		if err != nil {
 			return nil, fmt.Errorf("invalid price: %w", err)
 		}
 	}
 
 	if r.Funds != "" {
-		funds, err = strconv.ParseFloat(r.Funds, 64)
+		funds, err = decimal.NewFromString(r.Funds)
 		if err != nil {
 			return nil, fmt.Errorf("invalid funds: %w", err)
 		}
 	}
 
 	if r.RemainingSize != "" {
-		remainingSize, err = strconv.ParseFloat(r.RemainingSize, 64)
+		remainingSize, err = decimal.NewFromString(r.RemainingSize)
 		if err != nil {
 			return nil, fmt.Errorf("invalid remaining size: %w", err)
 		}
 	}
 
+	if r.NewSize != "" {
+		newSize, err = decimal.NewFromString(r.NewSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid new size: %w", err)
+		}
+	}
+
+	if r.OldSize != "" {
+		oldSize, err = decimal.NewFromString(r.OldSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid old size: %w", err)
+		}
+	}
+
 	return &entity.Order{
 		Type:          r.Type,
 		Timestamp:     r.Time.UnixNano(),
@@ -155,6 +239,8 @@ func (r *OrderResponse) ToOrderResponse() (*entity.Order, error) {
 		TradeID:       r.TradeID,
 		MakerOrderID:  r.MakerOrderID,
 		TakerOrderID:  r.TakerOrderID,
+		NewSize:       newSize,
+		OldSize:       oldSize,
 		// Set other fields as needed
 	}, nil
 }
@@ -181,6 +267,61 @@ func (r *HeartbeatResponse) ToHeartbeat() (*entity.Heartbeat, error) {
 	}, nil
 }
 
+func (r *SnapshotResponse) ToBookUpdate() (*entity.BookUpdate, error) {
+	changes := make([]entity.BookChange, 0, len(r.Bids)+len(r.Asks))
+
+	for _, sideLevels := range []struct {
+		side   string
+		levels [][2]string
+	}{{"buy", r.Bids}, {"sell", r.Asks}} {
+		for _, level := range sideLevels.levels {
+			change, err := parseBookChange(sideLevels.side, level[0], level[1])
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return &entity.BookUpdate{
+		ProductID: r.ProductID,
+		Snapshot:  true,
+		Time:      r.Time,
+		Changes:   changes,
+	}, nil
+}
+
+func (r *L2UpdateResponse) ToBookUpdate() (*entity.BookUpdate, error) {
+	changes := make([]entity.BookChange, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		change, err := parseBookChange(c[0], c[1], c[2])
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return &entity.BookUpdate{
+		ProductID: r.ProductID,
+		Time:      r.Time,
+		Changes:   changes,
+	}, nil
+}
+
+func parseBookChange(side, price, size string) (entity.BookChange, error) {
+	p, err := decimal.NewFromString(price)
+	if err != nil {
+		return entity.BookChange{}, fmt.Errorf("invalid book price: %w", err)
+	}
+
+	s, err := decimal.NewFromString(size)
+	if err != nil {
+		return entity.BookChange{}, fmt.Errorf("invalid book size: %w", err)
+	}
+
+	return entity.BookChange{Side: side, Price: p, Size: s}, nil
+}
+
 func ParseResponse(message []byte) (interface{}, error) {
 
 	var baseResponse Response
@@ -212,7 +353,21 @@ func ParseResponse(message []byte) (interface{}, error) {
 		}
 		return &orderResponse, nil
 
-	case Subscriptions.String():
+	case Snapshot.String():
+		var snapshotResponse SnapshotResponse
+		if err := json.Unmarshal(message, &snapshotResponse); err != nil {
+			return nil, err
+		}
+		return &snapshotResponse, nil
+
+	case L2Update.String():
+		var l2UpdateResponse L2UpdateResponse
+		if err := json.Unmarshal(message, &l2UpdateResponse); err != nil {
+			return nil, err
+		}
+		return &l2UpdateResponse, nil
+
+	case Error.String(), Subscriptions.String():
 		return &baseResponse, nil
 	default:
 		fmt.Printf("Unknown response type: %s and product: %s\n", baseResponse.Type, baseResponse.ProductID)
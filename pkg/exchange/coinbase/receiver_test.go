@@ -0,0 +1,116 @@
+package coinbase
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dec spells values as the feed sends them, parsing keeps their exact digits
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestParseResponse_Snapshot(t *testing.T) {
+	message := []byte(`{"type":"snapshot","product_id":"BTC-USD","bids":[["10101.10","0.45054140"]],"asks":[["10102.55","0.57753524"],["10103.00","1"]]}`)
+
+	response, err := ParseResponse(message)
+	require.NoError(t, err)
+
+	snapshot, ok := response.(*SnapshotResponse)
+	require.True(t, ok)
+
+	book, err := snapshot.ToBookUpdate()
+	require.NoError(t, err)
+	assert.Equal(t, &entity.BookUpdate{
+		ProductID: "BTC-USD",
+		Snapshot:  true,
+		Changes: []entity.BookChange{
+			{Side: "buy", Price: dec("10101.10"), Size: dec("0.45054140")},
+			{Side: "sell", Price: dec("10102.55"), Size: dec("0.57753524")},
+			{Side: "sell", Price: dec("10103.00"), Size: dec("1")},
+		},
+	}, book)
+}
+
+func TestParseResponse_L2Update(t *testing.T) {
+	message := []byte(`{"type":"l2update","product_id":"BTC-USD","time":"2019-08-14T20:42:27.265Z","changes":[["buy","10101.80000000","0.162567"],["sell","10102.00","0"]]}`)
+
+	response, err := ParseResponse(message)
+	require.NoError(t, err)
+
+	update, ok := response.(*L2UpdateResponse)
+	require.True(t, ok)
+
+	book, err := update.ToBookUpdate()
+	require.NoError(t, err)
+	assert.Equal(t, &entity.BookUpdate{
+		ProductID: "BTC-USD",
+		Time:      time.Date(2019, 8, 14, 20, 42, 27, 265000000, time.UTC),
+		Changes: []entity.BookChange{
+			{Side: "buy", Price: dec("10101.80000000"), Size: dec("0.162567")},
+			{Side: "sell", Price: dec("10102.00"), Size: dec("0")},
+		},
+	}, book)
+}
+
+func TestL2UpdateResponse_ToBookUpdate_InvalidPrice(t *testing.T) {
+	update := &L2UpdateResponse{Changes: [][3]string{{"buy", "abc", "1"}}}
+
+	_, err := update.ToBookUpdate()
+	assert.Error(t, err)
+}
+
+func TestParseResponse_Ticker(t *testing.T) {
+	message := []byte(`{"type":"ticker","sequence":37475248783,"product_id":"ETH-USD","price":"1285.22","open_24h":"1310.79","volume_24h":"245532.79269678","low_24h":"1280.52","high_24h":"1313.8","volume_30d":"9788783.60117027","best_bid":"1285.04","best_bid_size":"0.46688654","best_ask":"1285.27","best_ask_size":"1.56637040","side":"buy","time":"2022-10-19T23:28:22.061769Z","trade_id":370843401,"last_size":"11.4396987"}`)
+	receivedAt := time.Date(2022, 10, 19, 23, 28, 22, 90000000, time.UTC).UnixNano()
+
+	response, err := ParseResponse(message)
+	require.NoError(t, err)
+
+	ticker, ok := response.(*TickerResponse)
+	require.True(t, ok)
+
+	got, err := ticker.ToTicker(receivedAt)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Ticker{
+		Timestamp:  time.Date(2022, 10, 19, 23, 28, 22, 61769000, time.UTC).UnixNano(),
+		ReceivedAt: receivedAt,
+		Symbol:     "ETH-USD",
+		Sequence:   37475248783,
+		Price:      decimal.NewNullDecimal(dec("1285.22")),
+		LastSize:   decimal.NewNullDecimal(dec("11.4396987")),
+		Side:       sql.NullString{String: "buy", Valid: true},
+		TradeID:    sql.NullInt64{Int64: 370843401, Valid: true},
+		Bid:        dec("1285.04"),
+		BidSize:    decimal.NewNullDecimal(dec("0.46688654")),
+		Ask:        dec("1285.27"),
+		AskSize:    decimal.NewNullDecimal(dec("1.56637040")),
+		Open24h:    decimal.NewNullDecimal(dec("1310.79")),
+		High24h:    decimal.NewNullDecimal(dec("1313.8")),
+		Low24h:     decimal.NewNullDecimal(dec("1280.52")),
+		Volume24h:  decimal.NewNullDecimal(dec("245532.79269678")),
+	}, got)
+}
+
+func TestTickerResponse_ToTicker_WithoutTrade(t *testing.T) {
+	// ticker sent on subscribe, before any trade
+	ticker := &TickerResponse{Response: Response{ProductID: "BTC-USD"}, BestBid: "1", BestAsk: "2"}
+
+	got, err := ticker.ToTicker(42)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got.Timestamp)
+	assert.False(t, got.Price.Valid)
+	assert.False(t, got.Side.Valid)
+	assert.False(t, got.TradeID.Valid)
+	assert.False(t, got.Volume24h.Valid)
+
+	ticker.Volume24h = "abc"
+	_, err = ticker.ToTicker(42)
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultRestURL is the Coinbase Exchange REST api
+const DefaultRestURL = "https://api.exchange.coinbase.com"
+
+// l3BookResponse of GET /products/{product_id}/book?level=3, levels are [price, size, order_id]
+type l3BookResponse struct {
+	Sequence int64       `json:"sequence"`
+	Bids     [][3]string `json:"bids"`
+	Asks     [][3]string `json:"asks"`
+}
+
+type snapshotSource struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSnapshotSource init full book snapshot source from Coinbase REST api
+func NewSnapshotSource(baseURL string) *snapshotSource {
+	if baseURL == "" {
+		baseURL = DefaultRestURL
+	}
+
+	return &snapshotSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *snapshotSource) L3Snapshot(ctx context.Context, productID string) (*orderbook.L3Snapshot, error) {
+	url := fmt.Sprintf("%s/products/%s/book?level=3", s.baseURL, productID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch book: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch book: %s", resp.Status)
+	}
+
+	var book l3BookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&book); err != nil {
+		return nil, fmt.Errorf("failed to parse book: %w", err)
+	}
+
+	return book.toL3Snapshot()
+}
+
+func (r *l3BookResponse) toL3Snapshot() (*orderbook.L3Snapshot, error) {
+	snapshot := &orderbook.L3Snapshot{
+		Sequence: r.Sequence,
+		Orders:   make([]orderbook.L3Order, 0, len(r.Bids)+len(r.Asks)),
+	}
+
+	for _, sideLevels := range []struct {
+		side   string
+		levels [][3]string
+	}{{orderbook.Buy, r.Bids}, {orderbook.Sell, r.Asks}} {
+		for _, level := range sideLevels.levels {
+			price, err := decimal.NewFromString(level[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid book price: %w", err)
+			}
+			size, err := decimal.NewFromString(level[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid book size: %w", err)
+			}
+
+			snapshot.Orders = append(snapshot.Orders, orderbook.L3Order{
+				OrderID: level[2],
+				Side:    sideLevels.side,
+				Price:   price,
+				Size:    size,
+			})
+		}
+	}
+
+	return snapshot, nil
+}
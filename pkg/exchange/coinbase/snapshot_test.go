@@ -0,0 +1,41 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nel349/bz-findata/pkg/orderbook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotSource_L3Snapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/products/BTC-USD/book", r.URL.Path)
+		assert.Equal(t, "3", r.URL.Query().Get("level"))
+		_, _ = w.Write([]byte(`{"sequence":3,"bids":[["295.96","0.05088265","3b0f1225-7f84-490b-a29f-0faef9de823a"]],"asks":[["295.97","5.72036512","da863862-25f4-4868-ac41-005d11ab0a5f"]]}`))
+	}))
+	defer server.Close()
+
+	snapshot, err := NewSnapshotSource(server.URL).L3Snapshot(context.Background(), "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, &orderbook.L3Snapshot{
+		Sequence: 3,
+		Orders: []orderbook.L3Order{
+			{OrderID: "3b0f1225-7f84-490b-a29f-0faef9de823a", Side: orderbook.Buy, Price: dec("295.96"), Size: dec("0.05088265")},
+			{OrderID: "da863862-25f4-4868-ac41-005d11ab0a5f", Side: orderbook.Sell, Price: dec("295.97"), Size: dec("5.72036512")},
+		},
+	}, snapshot)
+}
+
+func TestSnapshotSource_L3Snapshot_Status(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewSnapshotSource(server.URL).L3Snapshot(context.Background(), "BTC-USD")
+	assert.Error(t, err)
+}
@@ -0,0 +1,288 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Conn is a websocket connection of a feed that is dialed again with backoff when it is
+// lost, its subscriptions are replayed on the new connection. It implements Manager but
+// the heartbeat subscription and monitor, which the feeds add on top of it
+type Conn struct {
+	dial     func() (*websocket.Conn, error)
+	backoff  Backoff
+	attempts int
+
+	mu            sync.Mutex
+	conn          *websocket.Conn
+	reconnecting  bool
+	dropCause     error
+	subscriptions []func() ([]byte, error)
+	handlers      []func(Event)
+
+	// writeMu orders the frames written, a ping switches the payload type of the connection
+	writeMu sync.Mutex
+	// lastHeartbeat is the time of the last sign of life of the connection, unix nano
+	lastHeartbeat atomic.Int64
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewConn init a connection opened by dial, a lost connection is dialed again up to
+// attempts times, forever when attempts is 0. Connect opens the first connection
+func NewConn(dial func() (*websocket.Conn, error), backoff Backoff, attempts int) *Conn {
+	c := &Conn{
+		dial:     dial,
+		backoff:  backoff,
+		attempts: attempts,
+		closed:   make(chan struct{}),
+	}
+	c.UpdateHeartbeat()
+
+	return c
+}
+
+// Connect dials the first connection, the other methods need it
+func (c *Conn) Connect() error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	c.UpdateHeartbeat()
+
+	return nil
+}
+
+// OnEvent registers a handler of connection state changes, handlers must not block
+func (c *Conn) OnEvent(handler func(Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers = append(c.handlers, handler)
+}
+
+func (c *Conn) emit(event Event) {
+	event.Time = time.Now()
+
+	c.mu.Lock()
+	handlers := c.handlers
+	c.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Subscribe sends the subscription and keeps it to be replayed after a reconnect,
+// the first one emits Connected
+func (c *Conn) Subscribe(subscription func() ([]byte, error)) error {
+	message, err := subscription()
+	if err != nil {
+		return fmt.Errorf("failed to build subscription: %w", err)
+	}
+
+	if _, err := c.WriteData(message); err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	c.mu.Lock()
+	first := len(c.subscriptions) == 0
+	c.subscriptions = append(c.subscriptions, subscription)
+	c.mu.Unlock()
+
+	// the handlers are registered after the dial, the first subscription announces the connection
+	if first {
+		c.emit(Event{State: Connected})
+	}
+
+	return nil
+}
+
+// UpdateHeartbeat is updating the time of the last sign of life
+func (c *Conn) UpdateHeartbeat() {
+	c.lastHeartbeat.Store(time.Now().UnixNano())
+}
+
+// DropStale drops a connection without sign of life for timeout, the reader then
+// reconnects it and the Disconnected event has reason as cause. Nothing is dropped
+// while reconnecting
+func (c *Conn) DropStale(timeout time.Duration, reason string) {
+	since := time.Since(time.Unix(0, c.lastHeartbeat.Load()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reconnecting || since <= timeout {
+		return
+	}
+
+	c.dropCause = fmt.Errorf("%s after %s", reason, since.Round(time.Second))
+	// give the new connection a full timeout
+	c.UpdateHeartbeat()
+	_ = c.conn.Close()
+}
+
+// Reconnecting reports whether the connection is being dialed again
+func (c *Conn) Reconnecting() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.reconnecting
+}
+
+// Closed is closed by CloseConnection
+func (c *Conn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// reconnect redials with backoff until a connection accepts every subscription
+func (c *Conn) reconnect(cause error) error {
+	c.mu.Lock()
+	c.reconnecting = true
+	if c.dropCause != nil {
+		cause, c.dropCause = c.dropCause, nil
+	}
+	_ = c.conn.Close()
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.reconnecting = false
+		c.mu.Unlock()
+	}()
+
+	c.emit(Event{State: Disconnected, Err: cause})
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		if max := c.attempts; max > 0 && attempt > max {
+			c.emit(Event{State: ReconnectFailed, Attempt: max, Err: lastErr})
+			return fmt.Errorf("%w after %d attempts: %v", ErrReconnectFailed, max, lastErr)
+		}
+
+		delay := c.backoff.Delay(attempt)
+		c.emit(Event{State: Reconnecting, Attempt: attempt, Delay: delay, Err: lastErr})
+
+		select {
+		case <-time.After(delay):
+		case <-c.closed:
+			return net.ErrClosed
+		}
+
+		conn, err := c.dial()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if err := c.resubscribe(conn); err != nil {
+			_ = conn.Close()
+			lastErr = err
+			continue
+		}
+
+		c.mu.Lock()
+		select {
+		case <-c.closed:
+			// closed while dialing
+			c.mu.Unlock()
+			_ = conn.Close()
+			return net.ErrClosed
+		default:
+		}
+		c.conn = conn
+		c.UpdateHeartbeat()
+		c.mu.Unlock()
+
+		c.emit(Event{State: Reconnected, Attempt: attempt})
+		return nil
+	}
+}
+
+// resubscribe replays the subscriptions in their original order
+func (c *Conn) resubscribe(conn *websocket.Conn) error {
+	c.mu.Lock()
+	subscriptions := c.subscriptions
+	c.mu.Unlock()
+
+	for _, subscription := range subscriptions {
+		message, err := subscription()
+		if err != nil {
+			return fmt.Errorf("failed to build subscription: %w", err)
+		}
+		if _, err := conn.Write(message); err != nil {
+			return fmt.Errorf("failed to resubscribe: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Conn) current() *websocket.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn
+}
+
+func (c *Conn) WriteData(message []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.current().Write(message)
+}
+
+// Ping writes a ping frame, the pong is swallowed by the connection
+func (c *Conn) Ping() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	conn := c.current()
+	conn.PayloadType = websocket.PingFrame
+	defer func() { conn.PayloadType = websocket.TextFrame }()
+
+	_, err := conn.Write(nil)
+	return err
+}
+
+// ReadData reads one whole message frame, reconnecting a lost connection
+func (c *Conn) ReadData() ([]byte, error) {
+	for {
+		conn := c.current()
+
+		var message []byte
+		err := websocket.Message.Receive(conn, &message)
+		if err == nil {
+			return message, nil
+		}
+		if errors.Is(err, websocket.ErrFrameTooLarge) {
+			// the frame is drained by the next receive
+			return nil, &FrameTooLargeError{Max: conn.MaxPayloadBytes}
+		}
+
+		select {
+		case <-c.closed:
+			return nil, err
+		default:
+		}
+
+		if err := c.reconnect(err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Conn) CloseConnection() error {
+	c.once.Do(func() { close(c.closed) })
+
+	return c.current().Close()
+}
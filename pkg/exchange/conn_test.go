@@ -0,0 +1,61 @@
+package exchange
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestConn_DropStale(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var message string
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			return
+		}
+		_ = websocket.Message.Send(ws, message)
+		// silent until dropped
+		_ = websocket.Message.Receive(ws, &message)
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	c := NewConn(func() (*websocket.Conn, error) { return websocket.Dial(url, "", server.URL) }, NewBackoff(time.Millisecond, time.Millisecond), 0)
+	require.NoError(t, c.Connect())
+	defer c.CloseConnection()
+
+	var mu sync.Mutex
+	var events []Event
+	c.OnEvent(func(e Event) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+	require.NoError(t, c.Subscribe(func() ([]byte, error) { return []byte("subscribe"), nil }))
+
+	message, err := c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, "subscribe", string(message))
+
+	// a fresh connection is kept
+	c.DropStale(time.Minute, "heartbeat timeout")
+	time.Sleep(20 * time.Millisecond)
+	c.DropStale(10*time.Millisecond, "heartbeat timeout")
+
+	// the subscription is replayed on the new connection
+	message, err = c.ReadData()
+	require.NoError(t, err)
+	assert.Equal(t, "subscribe", string(message))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 4)
+	assert.Equal(t, Disconnected, events[1].State)
+	assert.ErrorContains(t, events[1].Err, "heartbeat timeout after")
+	assert.Equal(t, Reconnected, events[3].State)
+}
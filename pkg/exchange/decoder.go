@@ -0,0 +1,131 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultMaxFrameSize is the largest frame accepted when no limit is configured
+const DefaultMaxFrameSize = 1 << 20 // 1MB
+
+// FrameReader reads whole message frames
+type FrameReader interface {
+	ReadData() ([]byte, error)
+}
+
+// ReceiveClock is a FrameReader telling when its last frame was received,
+// e.g. a replayed feed returning the recorded time
+type ReceiveClock interface {
+	ReceivedAt() time.Time
+}
+
+// FrameTooLargeError is returned for a frame above the max frame size,
+// the frame is discarded and the next one can be read
+type FrameTooLargeError struct {
+	Size int // 0 when the frame was rejected by the connection before being read
+	Max  int
+}
+
+func (e *FrameTooLargeError) Error() string {
+	if e.Size == 0 {
+		return fmt.Sprintf("frame exceeds max frame size of %d bytes", e.Max)
+	}
+	return fmt.Sprintf("frame of %d bytes exceeds max frame size of %d bytes", e.Size, e.Max)
+}
+
+// MalformedFrameError is returned for a frame that is not a stream of JSON objects,
+// the rest of the frame is discarded and the next one can be read
+type MalformedFrameError struct {
+	Frame []byte
+	Err   error
+}
+
+func (e *MalformedFrameError) Error() string {
+	return fmt.Sprintf("malformed frame: %v", e.Err)
+}
+
+func (e *MalformedFrameError) Unwrap() error {
+	return e.Err
+}
+
+// IsFrameError reports whether err only affects a single frame,
+// reading can go on after it
+func IsFrameError(err error) bool {
+	var tooLarge *FrameTooLargeError
+	var malformed *MalformedFrameError
+
+	return errors.As(err, &tooLarge) || errors.As(err, &malformed)
+}
+
+// Decoder streams the JSON objects of the frames of a connection,
+// a frame may carry several objects
+type Decoder struct {
+	reader       FrameReader
+	maxFrameSize int
+	frame        []byte
+	receivedAt   time.Time
+	dec          *json.Decoder
+}
+
+// NewDecoder init JSON object decoder of frames read from reader
+func NewDecoder(reader FrameReader, maxFrameSize int) *Decoder {
+	if maxFrameSize <= 0 {
+		maxFrameSize = DefaultMaxFrameSize
+	}
+
+	return &Decoder{
+		reader:       reader,
+		maxFrameSize: maxFrameSize,
+	}
+}
+
+// Next returns the next JSON object, reading a new frame when the current one is consumed.
+// Errors other than *FrameTooLargeError and *MalformedFrameError come from the reader.
+func (d *Decoder) Next() (json.RawMessage, error) {
+	for {
+		if d.dec == nil {
+			frame, err := d.reader.ReadData()
+			if err != nil {
+				return nil, err
+			}
+			if len(frame) > d.maxFrameSize {
+				return nil, &FrameTooLargeError{Size: len(frame), Max: d.maxFrameSize}
+			}
+			d.frame = frame
+			d.receivedAt = time.Now()
+			if clock, ok := d.reader.(ReceiveClock); ok {
+				d.receivedAt = clock.ReceivedAt()
+			}
+			d.dec = json.NewDecoder(bytes.NewReader(frame))
+		}
+
+		var object json.RawMessage
+		err := d.dec.Decode(&object)
+		if err == io.EOF {
+			d.dec = nil
+			continue
+		}
+		if err != nil {
+			frame := d.frame
+			d.dec = nil
+			return nil, &MalformedFrameError{Frame: frame, Err: err}
+		}
+
+		if len(object) == 0 || object[0] != '{' {
+			frame := d.frame
+			d.dec = nil
+			return nil, &MalformedFrameError{Frame: frame, Err: errors.New("not a JSON object")}
+		}
+
+		return object, nil
+	}
+}
+
+// ReceivedAt returns when the frame of the last object was received
+func (d *Decoder) ReceivedAt() time.Time {
+	return d.receivedAt
+}
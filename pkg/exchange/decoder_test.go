@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type frames [][]byte
+
+func (f *frames) ReadData() ([]byte, error) {
+	if len(*f) == 0 {
+		return nil, io.EOF
+	}
+	frame := (*f)[0]
+	*f = (*f)[1:]
+	return frame, nil
+}
+
+func TestDecoder_Next(t *testing.T) {
+	tests := []struct {
+		name    string
+		frames  frames
+		want    []string
+		wantErr []error
+	}{
+		{
+			name:   "one object per frame",
+			frames: frames{[]byte(`{"type":"ticker"}`), []byte(`{"type":"heartbeat"}`)},
+			want:   []string{`{"type":"ticker"}`, `{"type":"heartbeat"}`},
+		},
+		{
+			name:   "several objects in a frame",
+			frames: frames{[]byte("{\"a\":1}\n{\"b\":{\"c\":2}} ")},
+			want:   []string{`{"a":1}`, `{"b":{"c":2}}`},
+		},
+		{
+			name:   "braces inside strings",
+			frames: frames{[]byte(`{"message":"}{","reason":"{"}`)},
+			want:   []string{`{"message":"}{","reason":"{"}`},
+		},
+		{
+			name:   "empty frame",
+			frames: frames{[]byte{}, []byte(`{"a":1}`)},
+			want:   []string{`{"a":1}`},
+		},
+		{
+			name:    "malformed frame is skipped",
+			frames:  frames{[]byte(`{"a":1}{"b":`), []byte(`{"c":3}`)},
+			want:    []string{`{"a":1}`, "", `{"c":3}`},
+			wantErr: []error{nil, &MalformedFrameError{}, nil},
+		},
+		{
+			name:    "not an object",
+			frames:  frames{[]byte(`[1,2]`), []byte(`{"c":3}`)},
+			want:    []string{"", `{"c":3}`},
+			wantErr: []error{&MalformedFrameError{}, nil},
+		},
+		{
+			name:    "oversized frame is skipped",
+			frames:  frames{[]byte(`{"a":"0123456789012345678901234567890123456789"}`), []byte(`{"c":3}`)},
+			want:    []string{"", `{"c":3}`},
+			wantErr: []error{&FrameTooLargeError{}, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(&tt.frames, 32)
+
+			for i, want := range tt.want {
+				got, err := d.Next()
+				if tt.wantErr != nil && tt.wantErr[i] != nil {
+					assert.True(t, IsFrameError(err), "frame error expected, got %v", err)
+					assert.IsType(t, tt.wantErr[i], err)
+					continue
+				}
+				require.NoError(t, err)
+				assert.JSONEq(t, want, string(got))
+			}
+
+			_, err := d.Next()
+			assert.ErrorIs(t, err, io.EOF)
+			assert.False(t, IsFrameError(err))
+		})
+	}
+}
+
+// clockFrames are frames received at recorded times
+type clockFrames struct {
+	frames
+	times []time.Time
+	last  time.Time
+}
+
+func (c *clockFrames) ReadData() ([]byte, error) {
+	c.last, c.times = c.times[0], c.times[1:]
+	return c.frames.ReadData()
+}
+
+func (c *clockFrames) ReceivedAt() time.Time {
+	return c.last
+}
+
+func TestDecoder_ReceivedAt(t *testing.T) {
+	first, second := time.Unix(100, 0), time.Unix(200, 0)
+	reader := &clockFrames{
+		frames: frames{[]byte(`{"a":1}{"b":2}`), []byte(`{"c":3}`)},
+		times:  []time.Time{first, second, second},
+	}
+	d := NewDecoder(reader, 0)
+
+	for _, want := range []time.Time{first, first, second} {
+		_, err := d.Next()
+		require.NoError(t, err)
+		assert.Equal(t, want, d.ReceivedAt())
+	}
+
+	// stamped on read without a clock
+	before := time.Now()
+	d = NewDecoder(&frames{[]byte(`{"a":1}`)}, 0)
+	_, err := d.Next()
+	require.NoError(t, err)
+	assert.False(t, d.ReceivedAt().Before(before))
+}
+
+func TestFrameTooLargeError(t *testing.T) {
+	err := error(&FrameTooLargeError{Size: 64, Max: 32})
+	assert.EqualError(t, err, "frame of 64 bytes exceeds max frame size of 32 bytes")
+
+	var tooLarge *FrameTooLargeError
+	assert.True(t, errors.As(err, &tooLarge))
+	assert.Equal(t, 32, tooLarge.Max)
+}
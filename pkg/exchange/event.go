@@ -0,0 +1,62 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReconnectFailed is returned by ReadData once every reconnect attempt failed
+var ErrReconnectFailed = errors.New("reconnect failed")
+
+// State of the exchange connection
+type State int
+
+const (
+	// Connected once the first connection accepted its first subscription
+	Connected State = iota
+	// Disconnected connection was lost, Err is the cause
+	Disconnected
+	// Reconnecting waits Delay before Attempt, Err is the failure of the previous attempt
+	Reconnecting
+	// Reconnected after Attempt, subscriptions were replayed
+	Reconnected
+	// ReconnectFailed gave up after Attempt
+	ReconnectFailed
+)
+
+var stateNames = [...]string{
+	"connected",
+	"disconnected",
+	"reconnecting",
+	"reconnected",
+	"reconnect_failed",
+}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// Event is a connection state change
+type Event struct {
+	State   State
+	Attempt int
+	Delay   time.Duration
+	Err     error
+	Time    time.Time
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("exchange connection %s", e.State)
+	if e.Attempt > 0 {
+		s += fmt.Sprintf(" (attempt %d)", e.Attempt)
+	}
+	if e.Delay > 0 {
+		s += fmt.Sprintf(" in %s", e.Delay)
+	}
+	if e.Err != nil {
+		s += fmt.Sprintf(": %v", e.Err)
+	}
+
+	return s
+}
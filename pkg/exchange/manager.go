@@ -7,8 +7,13 @@ import (
 
 // Manager is an interface exchange of application
 type Manager interface {
+	// Subscribe sends the message built by subscription and replays it after every reconnect,
+	// the message is rebuilt each time so signatures are fresh
+	Subscribe(subscription func() ([]byte, error)) error
+	// OnEvent registers a handler of connection state changes
+	OnEvent(handler func(Event))
 	// SubscribeToHeartbeats is subscribing to heartbeat messages
-	SubscribeToHeartbeats(ctx context.Context)
+	SubscribeToHeartbeats(ctx context.Context) error
 	// MonitorHeartbeat is monitoring heartbeat
 	MonitorHeartbeat(ctx context.Context, timeout time.Duration)
 	// UpdateHeartbeat is updating heartbeat
@@ -17,6 +22,8 @@ type Manager interface {
 	CloseConnection() error
 	// WriteData command write data to exchange connection
 	WriteData(message []byte) (int, error)
-	// ReadData command is reading from receiver data
+	// ReadData command is reading one whole message frame from receiver,
+	// frames above the max frame size return *FrameTooLargeError.
+	// A lost connection is reconnected transparently.
 	ReadData() ([]byte, error)
 }
@@ -0,0 +1,217 @@
+package feedlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/exchange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, "coinbase", 60, time.Hour)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	frames := []string{`{"type":"ticker"}`, `{"type":"heartbeat"}`, `not json {`, `{"type":"match"}`}
+	for i, frame := range frames {
+		require.NoError(t, w.Write(start.Add(time.Duration(i)*time.Millisecond), []byte(frame)))
+	}
+	// rotated on age
+	require.NoError(t, w.Write(start.Add(2*time.Hour), []byte(`{"type":"done"}`)))
+	require.NoError(t, w.Close())
+
+	files, err := Files(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Equal(t, "coinbase-20240102T030405.000000000Z.jsonl.gz", filepath.Base(files[0]))
+
+	r, err := NewReader(dir)
+	require.NoError(t, err)
+	defer r.Close()
+
+	var got []string
+	var times []int64
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, record.Frame)
+		times = append(times, record.ReceivedAt)
+	}
+	assert.Equal(t, append(frames, `{"type":"done"}`), got)
+	assert.Equal(t, start.UnixNano(), times[0])
+	assert.Equal(t, start.Add(2*time.Hour).UnixNano(), times[4])
+}
+
+func TestReader_Truncated(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, "coinbase", 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(time.Now(), []byte(`{"type":"ticker"}`)))
+	require.NoError(t, w.Close())
+
+	files, err := Files(dir)
+	require.NoError(t, err)
+
+	// a crashed recorder leaves the file without its gzip footer
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(files[0], data[:len(data)-8], 0o644))
+
+	r, err := NewReader(files[0])
+	require.NoError(t, err)
+
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"ticker"}`, record.Frame)
+
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFiles_NoMatch(t *testing.T) {
+	_, err := Files(t.TempDir())
+	assert.Error(t, err)
+}
+
+// frames is an exchange.Manager reading prepared frames
+type frames struct {
+	exchange.Manager
+	data [][]byte
+}
+
+func (f *frames) ReadData() ([]byte, error) {
+	if len(f.data) == 0 {
+		return nil, io.EOF
+	}
+	frame := f.data[0]
+	f.data = f.data[1:]
+	return frame, nil
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, "coinbase", 0, 0)
+	require.NoError(t, err)
+
+	r := NewRecorder(&frames{data: [][]byte{[]byte(`{"a":1}`), []byte(`{"b":2}`)}}, w, nil)
+	var received []time.Time
+	for range 2 {
+		_, err := r.ReadData()
+		require.NoError(t, err)
+		received = append(received, r.ReceivedAt())
+	}
+	_, err = r.ReadData()
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, w.Close())
+
+	p, err := NewPlayer(dir, MaxSpeed)
+	require.NoError(t, err)
+
+	// frames are replayed with the time they were received
+	for i, want := range []string{`{"a":1}`, `{"b":2}`} {
+		frame, err := p.ReadData()
+		require.NoError(t, err)
+		assert.Equal(t, want, string(frame))
+		assert.Equal(t, received[i].UnixNano(), p.ReceivedAt().UnixNano())
+	}
+	_, err = p.ReadData()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, int64(2), p.Frames())
+}
+
+func TestPlayer_Speed(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, "coinbase", 0, 0)
+	require.NoError(t, err)
+	start := time.Now()
+	require.NoError(t, w.Write(start, []byte(`{"a":1}`)))
+	require.NoError(t, w.Write(start.Add(10*time.Second), []byte(`{"b":2}`)))
+	require.NoError(t, w.Write(start.Add(20*time.Second), []byte(`{"c":3}`)))
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		speed Speed
+		max   []time.Duration // upper bound of the waits
+	}{
+		{MaxSpeed, nil},
+		{Realtime, []time.Duration{10 * time.Second, 20 * time.Second}},
+		{10, []time.Duration{time.Second, 2 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.speed.String(), func(t *testing.T) {
+			p, err := NewPlayer(dir, tt.speed)
+			require.NoError(t, err)
+
+			var waits []time.Duration
+			p.sleep = func(d time.Duration, _ <-chan struct{}) { waits = append(waits, d) }
+
+			for range 3 {
+				_, err := p.ReadData()
+				require.NoError(t, err)
+			}
+
+			require.Len(t, waits, len(tt.max))
+			for i, max := range tt.max {
+				assert.LessOrEqual(t, waits[i], max)
+				assert.Greater(t, waits[i], max-time.Second)
+			}
+		})
+	}
+}
+
+func TestPlayer_Close(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(dir, "coinbase", 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(time.Now(), []byte(`{"a":1}`)))
+	require.NoError(t, w.Write(time.Now().Add(time.Hour), []byte(`{"b":2}`)))
+	require.NoError(t, w.Close())
+
+	p, err := NewPlayer(dir, Realtime)
+	require.NoError(t, err)
+
+	_, err = p.ReadData()
+	require.NoError(t, err)
+
+	// the wait for the next frame is cut short
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = p.CloseConnection()
+	}()
+	_, err = p.ReadData()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestParseSpeed(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Speed
+		wantErr bool
+	}{
+		{"", Realtime, false},
+		{"realtime", Realtime, false},
+		{"MAX", MaxSpeed, false},
+		{"10x", 10, false},
+		{"2.5", 2.5, false},
+		{"0", 0, true},
+		{"fast", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpeed(tt.in)
+		if tt.wantErr {
+			assert.Error(t, err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
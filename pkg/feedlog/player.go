@@ -0,0 +1,165 @@
+package feedlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/exchange"
+)
+
+// Speed of a replay relative to the recording, MaxSpeed does not wait between frames
+type Speed float64
+
+const (
+	// MaxSpeed replays as fast as possible
+	MaxSpeed Speed = 0
+	// Realtime replays with the recorded delays
+	Realtime Speed = 1
+)
+
+// ParseSpeed reads "realtime", "max" or a multiplier such as "10" or "10x"
+func ParseSpeed(s string) (Speed, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "realtime":
+		return Realtime, nil
+	case "max":
+		return MaxSpeed, nil
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid replay speed %q, expected realtime, max or a multiplier like 10x", s)
+	}
+
+	return Speed(v), nil
+}
+
+func (s Speed) String() string {
+	switch s {
+	case MaxSpeed:
+		return "max"
+	case Realtime:
+		return "realtime"
+	}
+	return strconv.FormatFloat(float64(s), 'f', -1, 64) + "x"
+}
+
+// player is an exchange.Manager serving recorded frames, writes are dropped
+type player struct {
+	reader *Reader
+	speed  Speed
+
+	// wall clock and record time of the first frame
+	start    time.Time
+	recorded int64
+	frames   int64
+	last     int64 // record time of the last frame
+
+	closed chan struct{}
+	once   sync.Once
+	sleep  func(d time.Duration, cancel <-chan struct{})
+}
+
+// NewPlayer init manager replaying the recorded files of path at speed
+func NewPlayer(path string, speed Speed) (*player, error) {
+	reader, err := NewReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &player{
+		reader: reader,
+		speed:  speed,
+		closed: make(chan struct{}),
+		sleep:  sleep,
+	}, nil
+}
+
+// Frames returns the number of frames replayed
+func (p *player) Frames() int64 {
+	return p.frames
+}
+
+// ReadData returns the next recorded frame once its time has come, io.EOF at the end
+func (p *player) ReadData() ([]byte, error) {
+	if p.isClosed() {
+		return nil, io.EOF
+	}
+
+	record, err := p.reader.Next()
+	if err != nil {
+		_ = p.reader.Close()
+		return nil, err
+	}
+
+	if p.frames == 0 {
+		p.start, p.recorded = time.Now(), record.ReceivedAt
+	} else if p.speed > 0 {
+		offset := time.Duration(float64(record.ReceivedAt-p.recorded) / float64(p.speed))
+		p.sleep(time.Until(p.start.Add(offset)), p.closed)
+
+		if p.isClosed() {
+			return nil, io.EOF
+		}
+	}
+	p.frames++
+	p.last = record.ReceivedAt
+
+	return []byte(record.Frame), nil
+}
+
+// ReceivedAt returns the record time of the last frame, not the time it was replayed
+func (p *player) ReceivedAt() time.Time {
+	return time.Unix(0, p.last)
+}
+
+// isClosed closes the files once the player is closed, only the reading goroutine uses them
+func (p *player) isClosed() bool {
+	select {
+	case <-p.closed:
+		_ = p.reader.Close()
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *player) Subscribe(func() ([]byte, error)) error { return nil }
+
+func (p *player) OnEvent(func(exchange.Event)) {}
+
+func (p *player) SubscribeToHeartbeats(context.Context) error { return nil }
+
+func (p *player) MonitorHeartbeat(context.Context, time.Duration) {}
+
+func (p *player) UpdateHeartbeat() {}
+
+func (p *player) WriteData(message []byte) (int, error) {
+	return len(message), nil
+}
+
+// CloseConnection stops the replay, a pending ReadData returns io.EOF
+func (p *player) CloseConnection() error {
+	p.once.Do(func() { close(p.closed) })
+
+	return nil
+}
+
+func sleep(d time.Duration, cancel <-chan struct{}) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-cancel:
+	}
+}
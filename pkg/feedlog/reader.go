@@ -0,0 +1,130 @@
+package feedlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxRecordSize is the longest line read back from a file
+const maxRecordSize = 64 << 20
+
+// Files returns the recorded files of path in recording order,
+// path is a file, a directory or a glob pattern
+func Files(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		path = filepath.Join(path, "*.jsonl*")
+	case err == nil:
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded files match %s", path)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Reader reads the records of files in order, plain .jsonl files are read too
+type Reader struct {
+	files   []string
+	file    *os.File
+	gz      *gzip.Reader
+	scanner *bufio.Scanner
+}
+
+// NewReader init reader of the recorded files of path
+func NewReader(path string) (*Reader, error) {
+	files, err := Files(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{files: files}, nil
+}
+
+// Next returns the next record, io.EOF after the last file
+func (r *Reader) Next() (Record, error) {
+	for {
+		if r.scanner == nil {
+			if len(r.files) == 0 {
+				return Record{}, io.EOF
+			}
+			if err := r.open(r.files[0]); err != nil {
+				return Record{}, err
+			}
+			r.files = r.files[1:]
+		}
+
+		if r.scanner.Scan() {
+			var record Record
+			if err := json.Unmarshal(r.scanner.Bytes(), &record); err != nil {
+				return Record{}, fmt.Errorf("invalid record in %s: %w", r.file.Name(), err)
+			}
+			return record, nil
+		}
+
+		err := r.scanner.Err()
+		name := r.file.Name()
+		r.closeFile()
+		// the last file of a crashed recorder ends without the gzip footer
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return Record{}, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+}
+
+// Close closes the current file
+func (r *Reader) Close() error {
+	r.closeFile()
+	r.files = nil
+
+	return nil
+}
+
+func (r *Reader) open(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	var src io.Reader = file
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		r.gz = gz
+		src = gz
+	}
+
+	r.file = file
+	r.scanner = bufio.NewScanner(src)
+	r.scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+
+	return nil
+}
+
+func (r *Reader) closeFile() {
+	if r.gz != nil {
+		_ = r.gz.Close()
+	}
+	if r.file != nil {
+		_ = r.file.Close()
+	}
+	r.file, r.gz, r.scanner = nil, nil, nil
+}
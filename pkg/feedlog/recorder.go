@@ -0,0 +1,50 @@
+package feedlog
+
+import (
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/exchange"
+)
+
+// recorder is an exchange.Manager writing every frame it reads
+type recorder struct {
+	exchange.Manager
+	writer  *Writer
+	onError func(error)
+	now     func() time.Time
+	last    time.Time // receive time of the last frame
+}
+
+// NewRecorder init manager recording the frames read from conn with writer,
+// recording errors go to onError and never fail a read
+func NewRecorder(conn exchange.Manager, writer *Writer, onError func(error)) *recorder {
+	if onError == nil {
+		onError = func(error) {}
+	}
+
+	return &recorder{
+		Manager: conn,
+		writer:  writer,
+		onError: onError,
+		now:     time.Now,
+	}
+}
+
+func (r *recorder) ReadData() ([]byte, error) {
+	frame, err := r.Manager.ReadData()
+	if err != nil {
+		return frame, err
+	}
+
+	r.last = r.now()
+	if err := r.writer.Write(r.last, frame); err != nil {
+		r.onError(err)
+	}
+
+	return frame, nil
+}
+
+// ReceivedAt returns the time recorded with the last frame
+func (r *recorder) ReceivedAt() time.Time {
+	return r.last
+}
@@ -0,0 +1,131 @@
+package feedlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Ext of the recorded files
+const Ext = ".jsonl.gz"
+
+// fileTimeLayout sorts the file names in recording order
+const fileTimeLayout = "20060102T150405.000000000Z"
+
+// Record is a raw frame with the time it was received
+type Record struct {
+	ReceivedAt int64  `json:"received_at"` // unix nano
+	Frame      string `json:"frame"`       // kept as is, even when it is not valid JSON
+}
+
+// Writer appends records to gzip compressed JSONL files rotated on size or age
+type Writer struct {
+	mu       sync.Mutex
+	dir      string
+	prefix   string
+	maxBytes int64
+	maxAge   time.Duration
+
+	file    *os.File
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	written int64
+	opened  time.Time
+}
+
+// NewWriter init writer of files named prefix-<time>.jsonl.gz in dir,
+// a file is rotated after maxBytes of records or maxAge, zero disables the limit
+func NewWriter(dir, prefix string, maxBytes int64, maxAge time.Duration) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create record dir: %w", err)
+	}
+
+	return &Writer{
+		dir:      dir,
+		prefix:   prefix,
+		maxBytes: maxBytes,
+		maxAge:   maxAge,
+	}, nil
+}
+
+// Write appends a frame received at
+func (w *Writer) Write(at time.Time, frame []byte) error {
+	line, err := json.Marshal(Record{ReceivedAt: at.UnixNano(), Frame: string(frame)})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil && w.expired(at) {
+		if err := w.close(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.open(at); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.buf.Write(line)
+	w.written += int64(n)
+
+	return err
+}
+
+// Close flushes and closes the current file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	return w.close()
+}
+
+func (w *Writer) expired(at time.Time) bool {
+	return (w.maxBytes > 0 && w.written >= w.maxBytes) ||
+		(w.maxAge > 0 && at.Sub(w.opened) >= w.maxAge)
+}
+
+func (w *Writer) open(at time.Time) error {
+	name := filepath.Join(w.dir, fmt.Sprintf("%s-%s%s", w.prefix, at.UTC().Format(fileTimeLayout), Ext))
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create record file: %w", err)
+	}
+
+	w.file = file
+	w.gz = gzip.NewWriter(file)
+	w.buf = bufio.NewWriterSize(w.gz, 64*1024)
+	w.written = 0
+	w.opened = at
+
+	return nil
+}
+
+func (w *Writer) close() error {
+	defer func() { w.file, w.gz, w.buf = nil, nil, nil }()
+
+	if err := w.buf.Flush(); err != nil {
+		_ = w.file.Close()
+		return fmt.Errorf("failed to flush record file: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		_ = w.file.Close()
+		return fmt.Errorf("failed to flush record file: %w", err)
+	}
+
+	return w.file.Close()
+}
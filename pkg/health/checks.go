@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Ping checks a dependency answering ping, e.g. sql.DB.PingContext,
+// the dependency is down while ping fails
+func Ping(ping func(ctx context.Context) error) Check {
+	return func(ctx context.Context) Result {
+		if err := ping(ctx); err != nil {
+			return Result{Status: Down, Error: err.Error()}
+		}
+		return Result{Status: OK}
+	}
+}
+
+// State is the last known state of a dependency, e.g. an upstream connection
+// updated from its events
+type State struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	status Status
+	err    error
+	since  time.Time
+}
+
+// NewState init state of a working dependency
+func NewState() *State {
+	return &State{now: time.Now, status: OK, since: time.Now()}
+}
+
+// Set changes the state, err tells why the dependency is not ok
+func (s *State) Set(status Status, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status != s.status {
+		s.since = s.now()
+	}
+	s.status, s.err = status, err
+}
+
+// Check reports the state and how long it lasted
+func (s *State) Check(context.Context) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := Result{
+		Status:  s.status,
+		Details: map[string]interface{}{"since": s.since.UTC().Format(time.RFC3339)},
+	}
+	if s.err != nil {
+		result.Error = s.err.Error()
+	}
+
+	return result
+}
+
+// Activity tracks the last time something happened, e.g. a message was received
+type Activity struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	maxAge time.Duration
+	last   time.Time
+}
+
+// NewActivity init activity degraded once nothing happened for maxAge
+func NewActivity(maxAge time.Duration) *Activity {
+	return &Activity{now: time.Now, maxAge: maxAge}
+}
+
+// Touch records that something happened now
+func (a *Activity) Touch() {
+	a.mu.Lock()
+	a.last = a.now()
+	a.mu.Unlock()
+}
+
+// Check reports the age of the last activity, degraded when older than the max age
+func (a *Activity) Check(context.Context) Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.last.IsZero() {
+		return Result{Status: Degraded, Error: "nothing received yet"}
+	}
+
+	age := a.now().Sub(a.last)
+	result := Result{Status: OK, Details: map[string]interface{}{"age_seconds": age.Seconds()}}
+	if age > a.maxAge {
+		result.Status = Degraded
+		result.Error = "nothing received for " + age.Round(time.Second).String()
+	}
+
+	return result
+}
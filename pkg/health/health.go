@@ -0,0 +1,142 @@
+// Package health reports the state of a service to an orchestrator
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Paths the probes are served on
+const (
+	LivePath  = "/healthz"
+	ReadyPath = "/readyz"
+)
+
+// checkTimeout bounds every check of a report
+const checkTimeout = 2 * time.Second
+
+// Status of a service or of one of its dependencies
+type Status string
+
+const (
+	// OK works as expected
+	OK Status = "ok"
+	// Degraded works partially and is expected to recover, e.g. while reconnecting
+	Degraded Status = "degraded"
+	// Down does not work
+	Down Status = "down"
+)
+
+var severity = map[Status]int{OK: 0, Degraded: 1, Down: 2}
+
+// Result of a check
+type Result struct {
+	Status  Status                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Check reports the state of a dependency
+type Check func(ctx context.Context) Result
+
+// Report of every check, Status is the worst of them
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Mux routes the probes, e.g. http.ServeMux or a chi router
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Checker runs the checks of a service
+type Checker struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewChecker init checker without checks
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Add registers check under name, replacing the previous check of that name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+// Report runs every check concurrently
+func (c *Checker) Report(ctx context.Context) Report {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: OK, Checks: make(map[string]Result, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if severity[results[i].Status] > severity[report.Status] {
+			report.Status = results[i].Status
+		}
+	}
+
+	return report
+}
+
+// Live answers the liveness probe, the service is alive as long as it answers
+func (c *Checker) Live(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]Status{"status": OK})
+}
+
+// Ready answers the readiness probe with the report,
+// a degraded service is still ready while a service with a check down is not
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Report(r.Context())
+
+	code := http.StatusOK
+	if report.Status == Down {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, report)
+}
+
+// Mount routes the probes on mux
+func (c *Checker) Mount(mux Mux) {
+	mux.Handle(LivePath, http.HandlerFunc(c.Live))
+	mux.Handle(ReadyPath, http.HandlerFunc(c.Ready))
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	feed := NewState()
+	database := errors.New("connection refused")
+
+	c := NewChecker()
+	c.Add("feed", feed.Check)
+	c.Add("database", Ping(func(context.Context) error { return database }))
+
+	mux := http.NewServeMux()
+	c.Mount(mux)
+
+	probe := func(path string) (int, Report) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var report Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		return rec.Code, report
+	}
+
+	code, report := probe(ReadyPath)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, Down, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+	assert.Equal(t, OK, report.Checks["feed"].Status)
+
+	// a degraded service is still ready
+	database = nil
+	feed.Set(Degraded, errors.New("reconnecting"))
+	code, report = probe(ReadyPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Degraded, report.Status)
+	assert.Equal(t, "reconnecting", report.Checks["feed"].Error)
+
+	feed.Set(OK, nil)
+	_, report = probe(ReadyPath)
+	assert.Equal(t, OK, report.Status)
+
+	// alive whatever the checks
+	feed.Set(Down, errors.New("reconnect failed"))
+	code, report = probe(LivePath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, OK, report.Status)
+}
+
+func TestChecker_Report_concurrentAdd(t *testing.T) {
+	c := NewChecker()
+	ok := func(context.Context) Result { return Result{Status: OK} }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			c.Add(fmt.Sprint("check", i), ok)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, OK, c.Report(context.Background()).Status)
+	}
+	<-done
+
+	assert.Len(t, c.Report(context.Background()).Checks, 100)
+}
+
+func TestActivity_Check(t *testing.T) {
+	now := time.Unix(100, 0)
+	a := NewActivity(30 * time.Second)
+	a.now = func() time.Time { return now }
+
+	assert.Equal(t, Degraded, a.Check(context.Background()).Status)
+
+	a.Touch()
+	now = now.Add(10 * time.Second)
+	result := a.Check(context.Background())
+	assert.Equal(t, OK, result.Status)
+	assert.Equal(t, 10.0, result.Details["age_seconds"])
+
+	now = now.Add(time.Minute)
+	result = a.Check(context.Background())
+	assert.Equal(t, Degraded, result.Status)
+	assert.Equal(t, "nothing received for 1m10s", result.Error)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Age is a gauge of the seconds since each label value was last touched,
+// e.g. since the last heartbeat of a product. Values never touched are not exposed.
+type Age struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewAge creates an age gauge with one label and registers it in the default registry
+func NewAge(name, help, label string) *Age {
+	a := newAge(name, help, label)
+	prometheus.MustRegister(a)
+
+	return a
+}
+
+func newAge(name, help, label string) *Age {
+	return &Age{
+		desc: prometheus.NewDesc(name, help, []string{label}, nil),
+		now:  time.Now,
+		last: make(map[string]time.Time),
+	}
+}
+
+// Touch resets the age of value
+func (a *Age) Touch(value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.last[value] = a.now()
+}
+
+// Describe implements prometheus.Collector
+func (a *Age) Describe(ch chan<- *prometheus.Desc) {
+	ch <- a.desc
+}
+
+// Collect implements prometheus.Collector
+func (a *Age) Collect(ch chan<- prometheus.Metric) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for value, t := range a.last {
+		ch <- prometheus.MustNewConstMetric(a.desc, prometheus.GaugeValue, now.Sub(t).Seconds(), value)
+	}
+}
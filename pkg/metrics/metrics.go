@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path the metrics are served on
+const Path = "/metrics"
+
+// shutdownTimeout bounds the scrapes in flight on shutdown
+const shutdownTimeout = 5 * time.Second
+
+// Handler serves the metrics of the default registry
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve exposes the metrics and the other routes of mux on addr until ctx is done,
+// mux may be nil and an empty addr disables it
+func Serve(ctx context.Context, addr string, mux *http.ServeMux) error {
+	if addr == "" {
+		return nil
+	}
+
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	mux.Handle(Path, Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAge(t *testing.T) {
+	now := time.Unix(100, 0)
+	a := newAge("test_heartbeat_age_seconds", "Seconds since the last heartbeat.", "product")
+	a.now = func() time.Time { return now }
+
+	assert.Equal(t, 0, testutil.CollectAndCount(a))
+
+	a.Touch("BTC-USD")
+	now = now.Add(90 * time.Second)
+
+	expected := `
+# HELP test_heartbeat_age_seconds Seconds since the last heartbeat.
+# TYPE test_heartbeat_age_seconds gauge
+test_heartbeat_age_seconds{product="BTC-USD"} 90
+`
+	assert.NoError(t, testutil.CollectAndCompare(a, strings.NewReader(expected)))
+}
+
+func TestServe(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_served_total", Help: "Test counter."})
+	prometheus.MustRegister(counter)
+	defer prometheus.Unregister(counter)
+	counter.Inc()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, addr, nil)
+	}()
+
+	var body []byte
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + Path)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, err = io.ReadAll(resp.Body)
+		return err == nil && resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, string(body), "test_served_total 1")
+
+	cancel()
+	assert.NoError(t, <-done)
+
+	// disabled without an address
+	assert.NoError(t, Serve(context.Background(), "", nil))
+}
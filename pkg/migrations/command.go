@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Usage of the migrate subcommand
+const Usage = "migrate [up | down [steps] | status | force <version>]"
+
+// Up applies the pending migrations of dialect to db, e.g. on the startup of a service
+func Up(ctx context.Context, db *sqlx.DB, dialect string) ([]Migration, error) {
+	m, err := newDialectMigrator(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Up(ctx)
+}
+
+// Command runs the migrate subcommand args on db and reports to out:
+// up applies the pending migrations and is the default, down reverts the last steps migrations (1 by default),
+// status lists the migrations and force records the migrations up to version as applied
+func Command(ctx context.Context, db *sqlx.DB, dialect string, args []string, out io.Writer) error {
+	m, err := newDialectMigrator(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "up":
+		done, err := m.Up(ctx)
+		report(out, "applied", done)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps %q, usage: %s", args[0], Usage)
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		report(out, "reverted", done)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%s %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	case "force":
+		if len(args) == 0 {
+			return fmt.Errorf("missing version, usage: %s", Usage)
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %q, usage: %s", args[0], Usage)
+		}
+		if err := m.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "forced version %d\n", version)
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q, usage: %s", command, Usage)
+}
+
+func newDialectMigrator(db *sqlx.DB, dialect string) (*migrator, error) {
+	source, err := Source(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(db, source)
+}
+
+func report(out io.Writer, verb string, migrations []Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(out, "nothing %s\n", verb)
+	}
+	for _, migration := range migrations {
+		fmt.Fprintf(out, "%s %04d_%s\n", verb, migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,145 @@
+// Package migrations versions the database schemas,
+// migrations are SQL files named <version>_<name>.up.sql and <version>_<name>.down.sql
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dialects of the embedded migrations
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres" // Supabase included
+	SQLite   = "sqlite"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one versioned change of a schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Source returns the embedded migrations of dialect
+func Source(dialect string) (fs.FS, error) {
+	switch dialect {
+	case MySQL, Postgres, SQLite:
+		return fs.Sub(files, dialect)
+	}
+
+	return nil, fmt.Errorf("unknown migrations dialect %q", dialect)
+}
+
+// Load reads the migrations of source ordered by version, every migration needs both files
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s needs an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// statements splits a migration into statements ending with a semicolon, comments are dropped.
+// Semicolons and dashes of quoted strings, identifiers and $$ quoted bodies, like postgres
+// DO blocks, neither end a statement nor start a comment
+func statements(script string) []string {
+	var stmts []string
+	var stmt strings.Builder
+	add := func() {
+		if s := strings.TrimSpace(stmt.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		stmt.Reset()
+	}
+
+	// quote closes the quoted text being read, a doubled quote closes and opens it again
+	quote := ""
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != "":
+			if strings.HasPrefix(script[i:], quote) {
+				stmt.WriteString(quote)
+				i += len(quote) - 1
+				quote = ""
+				continue
+			}
+		case strings.HasPrefix(script[i:], "--"):
+			// the comment runs to the end of the line, the line break is kept
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end - 1
+			}
+			continue
+		case strings.HasPrefix(script[i:], "$$"):
+			quote = "$$"
+			stmt.WriteString(quote)
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = string(c)
+		case c == ';':
+			add()
+			continue
+		}
+		stmt.WriteByte(c)
+	}
+	add()
+
+	return stmts
+}
@@ -0,0 +1,222 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSource = fstest.MapFS{
+	"0001_init.up.sql":      {Data: []byte("CREATE TABLE ticks (symbol VARCHAR(8) NOT NULL); -- first\nCREATE TABLE orders (product_id VARCHAR(8) NOT NULL);\n")},
+	"0001_init.down.sql":    {Data: []byte("DROP TABLE orders;\nDROP TABLE ticks;\n")},
+	"0002_candles.up.sql":   {Data: []byte("CREATE TABLE candles (product_id VARCHAR(8) NOT NULL);\n")},
+	"0002_candles.down.sql": {Data: []byte("DROP TABLE candles;\n")},
+	"README.md":             {Data: []byte("not a migration")},
+}
+
+func openDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// every connection of an in memory database is a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testSource)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "candles", migrations[1].Name)
+
+	_, err = Load(fstest.MapFS{"0003_half.up.sql": {Data: []byte("SELECT 1;")}})
+	assert.ErrorContains(t, err, "needs an up and a down file")
+
+	for _, dialect := range []string{MySQL, Postgres, SQLite} {
+		source, err := Source(dialect)
+		require.NoError(t, err)
+		migrations, err := Load(source)
+		require.NoError(t, err, dialect)
+		assert.Equal(t, "init", migrations[0].Name)
+	}
+	_, err = Source("oracle")
+	assert.Error(t, err)
+}
+
+func TestStatements(t *testing.T) {
+	stmts := statements("-- header; with a semicolon\nCREATE TABLE a (\n  x int -- comment\n);\n\nDROP TABLE b;")
+	assert.Equal(t, []string{"CREATE TABLE a (\n  x int \n)", "DROP TABLE b"}, stmts)
+}
+
+func TestStatements_dollarQuoted(t *testing.T) {
+	stmts := statements("CREATE TABLE a (x int);\nDO $$\nBEGIN\n  PERFORM 1;\nEND\n$$;\nDROP TABLE b;")
+	assert.Equal(t, []string{"CREATE TABLE a (x int)", "DO $$\nBEGIN\n  PERFORM 1;\nEND\n$$", "DROP TABLE b"}, stmts)
+}
+
+func TestStatements_quoted(t *testing.T) {
+	stmts := statements("INSERT INTO a VALUES ('a--b', 'it''s; -- not a comment'); -- comment\nSELECT \"x--y\", `z;` FROM a;")
+	assert.Equal(t, []string{"INSERT INTO a VALUES ('a--b', 'it''s; -- not a comment')", "SELECT \"x--y\", `z;` FROM a"}, stmts)
+}
+
+func TestLoad_legacyUpgrade(t *testing.T) {
+	// every change runs as a prepared statement of the same session
+	source, err := Source(MySQL)
+	require.NoError(t, err)
+	migrations, err := Load(source)
+	require.NoError(t, err)
+	require.Equal(t, "legacy_upgrade", migrations[1].Name)
+	for _, stmt := range statements(migrations[1].Up) {
+		assert.Regexp(t, `^(SET @upgrade|PREPARE upgrade|EXECUTE upgrade|DEALLOCATE PREPARE upgrade)`, stmt)
+	}
+
+	source, err = Source(Postgres)
+	require.NoError(t, err)
+	migrations, err = Load(source)
+	require.NoError(t, err)
+	require.Equal(t, "legacy_upgrade", migrations[2].Name)
+	stmts := statements(migrations[2].Up)
+	assert.Len(t, stmts, 7)
+	assert.Contains(t, stmts[6], "ALTER COLUMN value TYPE NUMERIC(38,18)")
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m, err := NewMigrator(db, testSource)
+	require.NoError(t, err)
+
+	done, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, done, 2)
+	_, err = db.Exec("INSERT INTO candles (product_id) VALUES ('BTC-USD')")
+	require.NoError(t, err)
+
+	// nothing left to apply
+	done, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	done, err = m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, 2, done[0].Version)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+	assert.False(t, statuses[1].Applied)
+
+	// a database created before the migrations
+	require.NoError(t, m.Force(ctx, 2))
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[1].Applied)
+	_, err = db.Exec("CREATE TABLE candles (product_id VARCHAR(8) NOT NULL)")
+	require.NoError(t, err)
+
+	done, err = m.Down(ctx, 5)
+	require.NoError(t, err)
+	assert.Len(t, done, 2)
+	_, err = db.Exec("SELECT * FROM ticks")
+	assert.Error(t, err)
+}
+
+func TestUp_sqlite(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	done, err := Up(ctx, db, SQLite)
+	require.NoError(t, err)
+	assert.NotEmpty(t, done)
+	_, err = db.Exec("INSERT INTO swap_transactions (tx_hash, version, exchange, amount_in, to_address, token_path_from, token_path_to) VALUES ('0x1', 'V2', 'uniswap', '1', '0x2', '0x3', '0x4')")
+	require.NoError(t, err)
+
+	m, err := newDialectMigrator(db, SQLite)
+	require.NoError(t, err)
+	_, err = m.Down(ctx, len(done))
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT * FROM ticks")
+	assert.Error(t, err)
+}
+
+func TestUp_sqliteWideProductIDs(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m, err := newDialectMigrator(db, SQLite)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	done, err := m.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, "wide_product_ids", done[1].Name)
+
+	// the rows stored before the tables are rebuilt are kept
+	_, err = db.Exec("INSERT INTO ticks (timestamp, symbol, bid, ask) VALUES (1, 'BTC-USD', 1, 2)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO orders (timestamp, product_id, type, sequence) VALUES (1, 'BTC-USD', 'match', 7)")
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM ticks WHERE symbol = 'BTC-USD'"))
+	assert.Equal(t, 1, count)
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM orders WHERE sequence = 7"))
+	assert.Equal(t, 1, count)
+	var sql string
+	require.NoError(t, db.Get(&sql, "SELECT sql FROM sqlite_master WHERE name = 'orders'"))
+	assert.Contains(t, sql, "product_id     VARCHAR(16)")
+	require.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'orders_type_idx'"))
+	assert.Equal(t, 1, count)
+}
+
+func TestMigrator_failed(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	source := fstest.MapFS{
+		"0001_init.up.sql":     testSource["0001_init.up.sql"],
+		"0001_init.down.sql":   testSource["0001_init.down.sql"],
+		"0002_broken.up.sql":   {Data: []byte("ALTER TABLE missing ADD COLUMN x int;")},
+		"0002_broken.down.sql": {Data: []byte("SELECT 1;")},
+	}
+	m, err := NewMigrator(db, source)
+	require.NoError(t, err)
+
+	done, err := m.Up(ctx)
+	assert.ErrorContains(t, err, "failed to migrate 2_broken up")
+	assert.Len(t, done, 1)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestCommand(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	// the embedded MySQL migrations are not SQLite, run the commands on the status only
+	var out bytes.Buffer
+	require.NoError(t, Command(ctx, db, MySQL, []string{"status"}, &out))
+	assert.Equal(t, "0001_init pending\n0002_legacy_upgrade pending\n0003_wide_product_ids pending\n0004_orders_trade_key pending\n", out.String())
+
+	out.Reset()
+	require.NoError(t, Command(ctx, db, MySQL, []string{"force", "4"}, &out))
+	assert.Equal(t, "forced version 4\n", out.String())
+
+	out.Reset()
+	require.NoError(t, Command(ctx, db, MySQL, nil, &out))
+	assert.Equal(t, "nothing applied\n", out.String())
+
+	assert.ErrorContains(t, Command(ctx, db, MySQL, []string{"down", "zero"}, &out), "invalid steps")
+	assert.ErrorContains(t, Command(ctx, db, MySQL, []string{"sideways"}, &out), "unknown migrate command")
+}
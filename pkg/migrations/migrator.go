@@ -0,0 +1,250 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// table tracks the applied migrations
+const table = "schema_migrations"
+
+// lockTimeout bounds the wait for another service migrating the same database
+const lockTimeout = time.Minute
+
+// Status of a migration in a database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator init migrator of db to the migrations of source
+func NewMigrator(db *sqlx.DB, source fs.FS) (*migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in order and returns them
+func (m *migrator) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.apply(ctx, migration, true); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations and returns them
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := m.apply(ctx, migration, false); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Force records the migrations up to version as applied and the later ones as not,
+// without running them, e.g. for a database created before the migrations
+func (m *migrator) Force(ctx context.Context, version int) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := m.applied(ctx); err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if err := m.record(ctx, tx, migration); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Status lists every migration with the time it was applied
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = time.Unix(0, at).UTC()
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// lock keeps the services sharing the database from migrating it at the same time,
+// the lock is held by a session of MySQL or Postgres, other databases are not locked
+func (m *migrator) lock(ctx context.Context) (func(), error) {
+	var lock, unlock string
+	switch m.db.DriverName() {
+	case "mysql":
+		lock = fmt.Sprintf("SELECT GET_LOCK('%s', %d)", table, int(lockTimeout.Seconds()))
+		unlock = fmt.Sprintf("SELECT RELEASE_LOCK('%s')", table)
+	case "pgx", "postgres":
+		lock = fmt.Sprintf("SELECT pg_advisory_lock(hashtext('%s'))::text", table)
+		unlock = fmt.Sprintf("SELECT pg_advisory_unlock(hashtext('%s'))::text", table)
+	default:
+		return func() {}, nil
+	}
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired sql.NullString
+	if err := conn.QueryRowContext(ctx, lock).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", table, err)
+	}
+	if acquired.String == "0" {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock %s: still locked after %s", table, lockTimeout)
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(context.Background(), unlock)
+		conn.Close()
+	}, nil
+}
+
+// applied creates the migrations table when missing and returns the applied versions
+// with the time they were applied
+func (m *migrator) applied(ctx context.Context) (map[int]int64, error) {
+	_, err := m.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+` (
+    version    BIGINT NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at BIGINT NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", table, err)
+	}
+
+	var rows []struct {
+		Version   int   `db:"version"`
+		AppliedAt int64 `db:"applied_at"`
+	}
+	if err := m.db.SelectContext(ctx, &rows, "SELECT version, applied_at FROM "+table); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	applied := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+
+	return applied, nil
+}
+
+// apply runs the up or down script of migration and records it in a transaction,
+// MySQL commits its DDL statements on its own so a failed MySQL migration may be half applied
+func (m *migrator) apply(ctx context.Context, migration Migration, up bool) error {
+	script, direction := migration.Up, "up"
+	if !up {
+		script, direction = migration.Down, "down"
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range statements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+		}
+	}
+
+	if up {
+		err = m.record(ctx, tx, migration)
+	} else {
+		_, err = tx.ExecContext(ctx, tx.Rebind("DELETE FROM "+table+" WHERE version = ?"), migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record %d_%s %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	return tx.Commit()
+}
+
+func (m *migrator) record(ctx context.Context, tx *sqlx.Tx, migration Migration) error {
+	_, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO "+table+" (version, name, applied_at) VALUES (?, ?, ?)"),
+		migration.Version, migration.Name, time.Now().UnixNano())
+	return err
+}
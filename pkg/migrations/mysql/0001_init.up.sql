@@ -0,0 +1,141 @@
+-- tables of the collector and of the dex monitor
+
+CREATE TABLE IF NOT EXISTS `ticks`
+(
+    `timestamp`   bigint unsigned NOT NULL, -- exchange time
+    `symbol`      varchar(8) NOT NULL,
+    `received_at` bigint unsigned NOT NULL DEFAULT 0, -- receive time, received_at - timestamp is the feed latency
+    `sequence`    bigint unsigned NOT NULL DEFAULT 0, -- tickers of one taker order share their time
+    `price`       decimal(38,18) NULL, -- last trade
+    `last_size`   decimal(38,18) NULL,
+    `side`        varchar(8) NULL, -- taker side of the last trade
+    `trade_id`    bigint NULL,
+    `bid`         decimal(38,18) NOT NULL,
+    `bid_size`    decimal(38,18) NULL,
+    `ask`         decimal(38,18) NOT NULL,
+    `ask_size`    decimal(38,18) NULL,
+    `open_24h`    decimal(38,18) NULL,
+    `high_24h`    decimal(38,18) NULL,
+    `low_24h`     decimal(38,18) NULL,
+    `volume_24h`  decimal(38,18) NULL,
+    CONSTRAINT ticks_pk
+        PRIMARY KEY (`timestamp`, `symbol`, `sequence`)
+    -- # TODO maybe need some indexes ?
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `orders`
+(
+    `timestamp` bigint unsigned NOT NULL,
+    `product_id`    varchar(8) NOT NULL,
+    `type`      varchar(8) NOT NULL,
+    `order_id`  varchar(64) NULL,
+    `funds`     decimal(38,18) NULL, -- funds in USD
+    `side`      varchar(8) NULL, -- buy or sell
+    `size`      decimal(38,18) NULL, -- size of order
+    `price`     decimal(38,18) NULL, -- price of order
+    `order_type` varchar(8) NULL, -- market or limit
+    `client_oid` varchar(64) NULL, -- client order id
+    `sequence` bigint unsigned NOT NULL,
+    `remaining_size` decimal(38,18) NULL,
+    `reason` varchar(64) NULL,
+    `trade_id` BIGINT NULL,
+    `maker_order_id` varchar(64) NULL,
+    `taker_order_id` varchar(64) NULL,
+    `usd_value` decimal(38,18) NULL, -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk
+        PRIMARY KEY (`timestamp`, `product_id`, `type`, `sequence`)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `book_snapshots`
+(
+    `timestamp`  bigint unsigned NOT NULL, -- time the snapshot was taken
+    `product_id` varchar(16) NOT NULL,
+    `best_bid`   decimal(38,18) NOT NULL,
+    `best_ask`   decimal(38,18) NOT NULL,
+    `mid`        decimal(38,18) NOT NULL,
+    `spread`     decimal(38,18) NOT NULL,
+    `depth_bps`  double NOT NULL, -- band around mid used for bid_depth/ask_depth
+    `bid_depth`  decimal(38,18) NOT NULL,
+    `ask_depth`  decimal(38,18) NOT NULL,
+    `bids`       json NOT NULL, -- [[price, size], ...] best first
+    `asks`       json NOT NULL, -- [[price, size], ...] best first
+    CONSTRAINT book_snapshots_pk
+        PRIMARY KEY (`timestamp`, `product_id`)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `feed_gaps`
+(
+    `product_id`    varchar(16) NOT NULL,
+    `from_sequence` bigint unsigned NOT NULL, -- first missing sequence
+    `to_sequence`   bigint unsigned NOT NULL, -- last missing sequence
+    `missing`       bigint unsigned NOT NULL,
+    `start_time`    bigint unsigned NOT NULL, -- time of the last message before the gap
+    `end_time`      bigint unsigned NOT NULL, -- time of the first message after the gap
+    `source`        varchar(16) NOT NULL, -- message type that revealed the gap
+    `detected_at`   bigint unsigned NOT NULL,
+    CONSTRAINT feed_gaps_pk -- a sequence can be missed again after the tracker is reset
+        PRIMARY KEY (`product_id`, `from_sequence`, `detected_at`),
+    INDEX feed_gaps_time_idx (`start_time`, `end_time`)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `candles`
+(
+    `product_id`     varchar(16) NOT NULL,
+    `granularity`    int unsigned NOT NULL, -- bar length in seconds
+    `timestamp`      bigint unsigned NOT NULL, -- bar start
+    `open`           decimal(38,18) NOT NULL,
+    `high`           decimal(38,18) NOT NULL,
+    `low`            decimal(38,18) NOT NULL,
+    `close`          decimal(38,18) NOT NULL,
+    `volume`         decimal(38,18) NOT NULL, -- base size traded
+    `quote_volume`   decimal(38,18) NOT NULL, -- sum of price * size
+    `vwap`           decimal(38,18) NOT NULL,
+    `trades`         bigint unsigned NOT NULL,
+    `open_time`      bigint unsigned NOT NULL, -- time of the first trade
+    `open_trade_id`  bigint NOT NULL,
+    `close_time`     bigint unsigned NOT NULL, -- time of the last trade
+    `close_trade_id` bigint NOT NULL,
+    CONSTRAINT candles_pk
+        PRIMARY KEY (`product_id`, `granularity`, `timestamp`)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `swap_transactions`
+(
+    `tx_hash` varchar(66) NOT NULL,
+    `version` varchar(8) NOT NULL,
+    `exchange` varchar(100) NOT NULL, -- dex name (e.g. uniswap)
+    `amount_in` varchar(100) NOT NULL,
+    `to_address` varchar(42) NOT NULL,
+    -- `deadline` datetime NOT NULL,
+    `token_path_from` varchar(42) NOT NULL,
+    `token_path_to` varchar(42) NOT NULL,
+    `value` decimal(38,18) NOT NULL DEFAULT 0, -- USD
+    `amount_token_desired` varchar(100) NULL, -- Uniswap V2 add liquidity
+    `amount_token_min` varchar(100) NULL, -- Uniswap V2 add liquidity
+    `amount_eth_min` varchar(100) NULL, -- Uniswap V2 add liquidity
+    `amount_out_min` varchar(100) NULL, -- Uniswap V3 swap
+    `amount_out` varchar(100) NULL, -- Uniswap V2 swap
+    `method_id` varchar(10) NULL,
+    `method_name` varchar(100) NULL,
+    `liquidity` varchar(100) NULL, -- Uniswap V2 remove liquidity
+    `last_updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `token_a` varchar(42) NULL, -- Uniswap V2 add/remove liquidity
+    `token_b` varchar(42) NULL, -- Uniswap V2 add/remove liquidity
+    `amount_a_desired` varchar(100) NULL, -- Uniswap V2 add/remove liquidity
+    `amount_b_desired` varchar(100) NULL, -- Uniswap V2 add/remove liquidity
+    `amount_a_min` varchar(100) NULL, -- Uniswap V2 add/remove liquidity
+    `amount_b_min` varchar(100) NULL, -- Uniswap V2 add/remove liquidity
+    `amount_in_max` varchar(100) NULL, -- Uniswap V3 swap
+    `fee` varchar(100) NULL, -- Uniswap V3 swap
+    CONSTRAINT swap_transactions_pk
+        PRIMARY KEY (`tx_hash`)
+) ENGINE = InnoDB;
+
+CREATE TABLE IF NOT EXISTS `token_metadata` (
+    `address` varchar(42) NOT NULL,
+    `decimals` tinyint UNSIGNED NULL,
+    `symbol` varchar(10) NULL,
+    `price` decimal(38,24) NULL, -- USD, small caps trade far below a cent
+    `last_updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`address`)
+) ENGINE = InnoDB;
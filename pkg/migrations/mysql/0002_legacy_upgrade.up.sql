@@ -0,0 +1,136 @@
+-- upgrades of databases created by the scripts/schema.sql init script that predates the
+-- migrations, 0001_init adopts their tables as they are. Every change checks
+-- information_schema and only runs on a table that misses it, so a database created by
+-- 0001_init is left as it is
+
+-- ticks stored before the full ticker fields have the receive time as timestamp
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `ticks` ADD COLUMN `received_at` bigint unsigned NOT NULL DEFAULT 0, ADD COLUMN `sequence` bigint unsigned NOT NULL DEFAULT 0, ADD COLUMN `price` decimal(38,18) NULL, ADD COLUMN `last_size` decimal(38,18) NULL, ADD COLUMN `side` varchar(8) NULL, ADD COLUMN `trade_id` bigint NULL, ADD COLUMN `bid_size` decimal(38,18) NULL, ADD COLUMN `ask_size` decimal(38,18) NULL, ADD COLUMN `open_24h` decimal(38,18) NULL, ADD COLUMN `high_24h` decimal(38,18) NULL, ADD COLUMN `low_24h` decimal(38,18) NULL, ADD COLUMN `volume_24h` decimal(38,18) NULL, DROP PRIMARY KEY, ADD PRIMARY KEY (`timestamp`, `symbol`, `sequence`)',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'ticks' AND column_name = 'received_at');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+-- prices and sizes are exact decimals, rows stored as float keep the value the float held
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `ticks` MODIFY `price` decimal(38,18) NULL, MODIFY `last_size` decimal(38,18) NULL, MODIFY `bid` decimal(38,18) NOT NULL, MODIFY `bid_size` decimal(38,18) NULL, MODIFY `ask` decimal(38,18) NOT NULL, MODIFY `ask_size` decimal(38,18) NULL, MODIFY `open_24h` decimal(38,18) NULL, MODIFY `high_24h` decimal(38,18) NULL, MODIFY `low_24h` decimal(38,18) NULL, MODIFY `volume_24h` decimal(38,18) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'ticks' AND column_name = 'bid' AND data_type = 'float');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `orders` MODIFY `funds` decimal(38,18) NULL, MODIFY `size` decimal(38,18) NULL, MODIFY `price` decimal(38,18) NULL, MODIFY `remaining_size` decimal(38,18) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'orders' AND column_name = 'funds' AND data_type = 'float');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `orders` ADD COLUMN `usd_value` decimal(38,18) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'orders' AND column_name = 'usd_value');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `book_snapshots` MODIFY `best_bid` decimal(38,18) NOT NULL, MODIFY `best_ask` decimal(38,18) NOT NULL, MODIFY `mid` decimal(38,18) NOT NULL, MODIFY `spread` decimal(38,18) NOT NULL, MODIFY `bid_depth` decimal(38,18) NOT NULL, MODIFY `ask_depth` decimal(38,18) NOT NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'book_snapshots' AND column_name = 'best_bid' AND data_type = 'double');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `candles` MODIFY `open` decimal(38,18) NOT NULL, MODIFY `high` decimal(38,18) NOT NULL, MODIFY `low` decimal(38,18) NOT NULL, MODIFY `close` decimal(38,18) NOT NULL, MODIFY `volume` decimal(38,18) NOT NULL, MODIFY `quote_volume` decimal(38,18) NOT NULL, MODIFY `vwap` decimal(38,18) NOT NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'candles' AND column_name = 'open' AND data_type = 'double');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `token_metadata` ADD COLUMN `last_updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'token_metadata' AND column_name = 'last_updated');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `token_metadata` MODIFY `price` decimal(38,24) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'token_metadata' AND column_name = 'price' AND data_type = 'float');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) > 0,
+    'ALTER TABLE `swap_transactions` MODIFY `value` decimal(38,18) NOT NULL DEFAULT 0',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'value' AND data_type = 'float');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+-- a sequence can be missed again after the tracker is reset
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `feed_gaps` DROP PRIMARY KEY, ADD PRIMARY KEY (`product_id`, `from_sequence`, `detected_at`)',
+    'DO 0') FROM information_schema.key_column_usage
+    WHERE table_schema = DATABASE() AND table_name = 'feed_gaps' AND constraint_name = 'PRIMARY' AND column_name = 'detected_at');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+-- the add_liquidity columns of swap_transactions
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `amount_a_desired` varchar(100) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'amount_a_desired');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `amount_b_desired` varchar(100) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'amount_b_desired');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `amount_a_min` varchar(100) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'amount_a_min');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `amount_b_min` varchar(100) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'amount_b_min');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `token_a` varchar(42) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'token_a');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
+
+SET @upgrade = (SELECT IF(COUNT(*) = 0,
+    'ALTER TABLE `swap_transactions` ADD COLUMN `token_b` varchar(42) NULL',
+    'DO 0') FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = 'swap_transactions' AND column_name = 'token_b');
+PREPARE upgrade FROM @upgrade;
+EXECUTE upgrade;
+DEALLOCATE PREPARE upgrade;
@@ -0,0 +1,3 @@
+-- fails in strict mode once a product id longer than 8 characters is stored
+ALTER TABLE `orders` MODIFY `product_id` varchar(8) NOT NULL;
+ALTER TABLE `ticks` MODIFY `symbol` varchar(8) NOT NULL;
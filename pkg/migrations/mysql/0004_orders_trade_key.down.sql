@@ -0,0 +1,4 @@
+-- fails once trades that only differ by their trade id are stored
+ALTER TABLE `orders` DROP PRIMARY KEY,
+    ADD PRIMARY KEY (`timestamp`, `product_id`, `type`, `sequence`),
+    MODIFY `trade_id` bigint NULL;
@@ -0,0 +1,8 @@
+-- Binance and Advanced Trade trades have no sequence, trades of one product in the same
+-- millisecond only differ by their trade id. The key includes it so they are not dropped
+-- as duplicates, orders without a trade have trade id 0
+
+UPDATE `orders` SET `trade_id` = 0 WHERE `trade_id` IS NULL;
+ALTER TABLE `orders` MODIFY `trade_id` bigint NOT NULL DEFAULT 0,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (`timestamp`, `product_id`, `type`, `sequence`, `trade_id`);
@@ -0,0 +1,30 @@
+-- tables of the analysis service on Supabase
+
+CREATE TABLE IF NOT EXISTS swap_transactions (
+    tx_hash VARCHAR(66) NOT NULL,
+    version VARCHAR(8),
+    exchange VARCHAR(100), -- dex name (e.g. uniswap)
+    amount_in VARCHAR(100),
+    to_address VARCHAR(42),
+    token_path_from VARCHAR(42),
+    token_path_to VARCHAR(42),
+    value NUMERIC(38,18) NOT NULL DEFAULT 0, -- USD
+    amount_token_desired VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_token_min VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_eth_min VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_out_min VARCHAR(100), -- Uniswap V3 swap
+    amount_out VARCHAR(100), -- Uniswap V2 swap
+    method_id VARCHAR(10),
+    method_name VARCHAR(100),
+    liquidity VARCHAR(100), -- Uniswap V2 remove liquidity
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    token_a VARCHAR(42), -- Uniswap V2 add/remove liquidity
+    token_b VARCHAR(42), -- Uniswap V2 add/remove liquidity
+    amount_a_desired VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_b_desired VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_a_min VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_b_min VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_in_max VARCHAR(100),
+    fee VARCHAR(100),
+    PRIMARY KEY (tx_hash)
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS token_metadata;
+DROP TABLE IF EXISTS candles;
+DROP TABLE IF EXISTS feed_gaps;
+DROP TABLE IF EXISTS book_snapshots;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS ticks;
+
+DROP INDEX IF EXISTS swap_transactions_value_idx;
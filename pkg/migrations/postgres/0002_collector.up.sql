@@ -0,0 +1,118 @@
+-- tables of the collector and of the dex monitor on postgres, times are unix nanoseconds
+-- like on mysql. ticks and orders keep their time column in the primary key so they
+-- can be TimescaleDB hypertables
+
+CREATE TABLE IF NOT EXISTS ticks (
+    timestamp   BIGINT NOT NULL, -- exchange time
+    symbol      VARCHAR(8) NOT NULL,
+    received_at BIGINT NOT NULL DEFAULT 0, -- receive time, received_at - timestamp is the feed latency
+    sequence    BIGINT NOT NULL DEFAULT 0, -- tickers of one taker order share their time
+    price       NUMERIC(38,18), -- last trade
+    last_size   NUMERIC(38,18),
+    side        VARCHAR(8), -- taker side of the last trade
+    trade_id    BIGINT,
+    bid         NUMERIC(38,18) NOT NULL,
+    bid_size    NUMERIC(38,18),
+    ask         NUMERIC(38,18) NOT NULL,
+    ask_size    NUMERIC(38,18),
+    open_24h    NUMERIC(38,18),
+    high_24h    NUMERIC(38,18),
+    low_24h     NUMERIC(38,18),
+    volume_24h  NUMERIC(38,18),
+    CONSTRAINT ticks_pk PRIMARY KEY (timestamp, symbol, sequence)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    timestamp      BIGINT NOT NULL,
+    product_id     VARCHAR(8) NOT NULL,
+    type           VARCHAR(8) NOT NULL,
+    order_id       VARCHAR(64),
+    funds          NUMERIC(38,18), -- funds in USD
+    side           VARCHAR(8), -- buy or sell
+    size           NUMERIC(38,18), -- size of order
+    price          NUMERIC(38,18), -- price of order
+    order_type     VARCHAR(8), -- market or limit
+    client_oid     VARCHAR(64), -- client order id
+    sequence       BIGINT NOT NULL,
+    remaining_size NUMERIC(38,18),
+    reason         VARCHAR(64),
+    trade_id       BIGINT,
+    maker_order_id VARCHAR(64),
+    taker_order_id VARCHAR(64),
+    usd_value      NUMERIC(38,18), -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS orders_type_idx ON orders (type, timestamp);
+
+CREATE TABLE IF NOT EXISTS book_snapshots (
+    timestamp  BIGINT NOT NULL, -- time the snapshot was taken
+    product_id VARCHAR(16) NOT NULL,
+    best_bid   NUMERIC(38,18) NOT NULL,
+    best_ask   NUMERIC(38,18) NOT NULL,
+    mid        NUMERIC(38,18) NOT NULL,
+    spread     NUMERIC(38,18) NOT NULL,
+    depth_bps  DOUBLE PRECISION NOT NULL, -- band around mid used for bid_depth/ask_depth
+    bid_depth  NUMERIC(38,18) NOT NULL,
+    ask_depth  NUMERIC(38,18) NOT NULL,
+    bids       JSONB NOT NULL, -- [[price, size], ...] best first
+    asks       JSONB NOT NULL, -- [[price, size], ...] best first
+    CONSTRAINT book_snapshots_pk PRIMARY KEY (timestamp, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS feed_gaps (
+    product_id    VARCHAR(16) NOT NULL,
+    from_sequence BIGINT NOT NULL, -- first missing sequence
+    to_sequence   BIGINT NOT NULL, -- last missing sequence
+    missing       BIGINT NOT NULL,
+    start_time    BIGINT NOT NULL, -- time of the last message before the gap
+    end_time      BIGINT NOT NULL, -- time of the first message after the gap
+    source        VARCHAR(16) NOT NULL, -- message type that revealed the gap
+    detected_at   BIGINT NOT NULL,
+    CONSTRAINT feed_gaps_pk PRIMARY KEY (product_id, from_sequence, detected_at) -- a sequence can be missed again after the tracker is reset
+);
+
+CREATE INDEX IF NOT EXISTS feed_gaps_time_idx ON feed_gaps (start_time, end_time);
+
+CREATE TABLE IF NOT EXISTS candles (
+    product_id     VARCHAR(16) NOT NULL,
+    granularity    INTEGER NOT NULL, -- bar length in seconds
+    timestamp      BIGINT NOT NULL, -- bar start
+    open           NUMERIC(38,18) NOT NULL,
+    high           NUMERIC(38,18) NOT NULL,
+    low            NUMERIC(38,18) NOT NULL,
+    close          NUMERIC(38,18) NOT NULL,
+    volume         NUMERIC(38,18) NOT NULL, -- base size traded
+    quote_volume   NUMERIC(38,18) NOT NULL, -- sum of price * size
+    vwap           NUMERIC(38,18) NOT NULL,
+    trades         BIGINT NOT NULL,
+    open_time      BIGINT NOT NULL, -- time of the first trade
+    open_trade_id  BIGINT NOT NULL,
+    close_time     BIGINT NOT NULL, -- time of the last trade
+    close_trade_id BIGINT NOT NULL,
+    CONSTRAINT candles_pk PRIMARY KEY (product_id, granularity, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS token_metadata (
+    address      VARCHAR(42) NOT NULL,
+    decimals     SMALLINT,
+    symbol       VARCHAR(10),
+    price        NUMERIC(38,24), -- USD, small caps trade far below a cent
+    last_updated TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (address)
+);
+
+-- swap_transactions stays a plain table keyed by tx_hash, a hypertable would need the
+-- insert time in its key and a swap stored again would no longer conflict
+CREATE INDEX IF NOT EXISTS swap_transactions_value_idx ON swap_transactions (last_updated, value);
+
+-- hypertables when the timescaledb extension is installed, plain tables otherwise.
+-- ticks and orders have one day chunks of nanoseconds
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        PERFORM create_hypertable('ticks', 'timestamp', chunk_time_interval => 86400000000000, if_not_exists => TRUE, migrate_data => TRUE);
+        PERFORM create_hypertable('orders', 'timestamp', chunk_time_interval => 86400000000000, if_not_exists => TRUE, migrate_data => TRUE);
+    END IF;
+END
+$$;
@@ -0,0 +1,2 @@
+-- the upgrades are not reverted, 0001_init down drops the table
+SELECT 1;
@@ -0,0 +1,20 @@
+-- upgrades of the Supabase swap_transactions table created before the migrations,
+-- 0001_init adopts it as it is. Only the changes the table misses are applied
+
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS amount_a_desired VARCHAR(100);
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS amount_b_desired VARCHAR(100);
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS amount_a_min VARCHAR(100);
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS amount_b_min VARCHAR(100);
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS token_a VARCHAR(42);
+ALTER TABLE swap_transactions ADD COLUMN IF NOT EXISTS token_b VARCHAR(42);
+
+-- the USD value was a float
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'swap_transactions'
+                 AND column_name = 'value' AND data_type <> 'numeric') THEN
+        ALTER TABLE swap_transactions ALTER COLUMN value TYPE NUMERIC(38,18);
+    END IF;
+END
+$$;
@@ -0,0 +1,3 @@
+-- fails once a product id longer than 8 characters is stored
+ALTER TABLE orders ALTER COLUMN product_id TYPE VARCHAR(8);
+ALTER TABLE ticks ALTER COLUMN symbol TYPE VARCHAR(8);
@@ -0,0 +1,5 @@
+-- the ticks and orders of the first venues had product ids of at most 8 characters, Binance
+-- and Advanced Trade products are longer. Widened to the 16 characters of the other tables
+
+ALTER TABLE ticks ALTER COLUMN symbol TYPE VARCHAR(16);
+ALTER TABLE orders ALTER COLUMN product_id TYPE VARCHAR(16);
@@ -0,0 +1,5 @@
+-- fails once trades that only differ by their trade id are stored
+ALTER TABLE orders DROP CONSTRAINT orders_pk;
+ALTER TABLE orders ADD CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence);
+ALTER TABLE orders ALTER COLUMN trade_id DROP NOT NULL;
+ALTER TABLE orders ALTER COLUMN trade_id DROP DEFAULT;
@@ -0,0 +1,9 @@
+-- Binance and Advanced Trade trades have no sequence, trades of one product in the same
+-- millisecond only differ by their trade id. The key includes it so they are not dropped
+-- as duplicates, orders without a trade have trade id 0
+
+UPDATE orders SET trade_id = 0 WHERE trade_id IS NULL;
+ALTER TABLE orders ALTER COLUMN trade_id SET DEFAULT 0;
+ALTER TABLE orders ALTER COLUMN trade_id SET NOT NULL;
+ALTER TABLE orders DROP CONSTRAINT orders_pk;
+ALTER TABLE orders ADD CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence, trade_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS token_metadata;
+DROP TABLE IF EXISTS swap_transactions;
+DROP TABLE IF EXISTS candles;
+DROP TABLE IF EXISTS feed_gaps;
+DROP TABLE IF EXISTS book_snapshots;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS ticks;
@@ -0,0 +1,135 @@
+-- tables of the collector, the dex monitor and the analysis on sqlite for local development,
+-- times are unix nanoseconds like on mysql. NUMERIC affinity stores every amount with a
+-- fraction as an 8 byte REAL, about 15 significant digits, so sorting and the candle merge
+-- still compare numbers. Enough to develop against but not the precision of the other databases
+
+CREATE TABLE IF NOT EXISTS ticks (
+    timestamp   INTEGER NOT NULL, -- exchange time
+    symbol      VARCHAR(8) NOT NULL,
+    received_at INTEGER NOT NULL DEFAULT 0, -- receive time, received_at - timestamp is the feed latency
+    sequence    INTEGER NOT NULL DEFAULT 0, -- tickers of one taker order share their time
+    price       NUMERIC, -- last trade
+    last_size   NUMERIC,
+    side        VARCHAR(8), -- taker side of the last trade
+    trade_id    INTEGER,
+    bid         NUMERIC NOT NULL,
+    bid_size    NUMERIC,
+    ask         NUMERIC NOT NULL,
+    ask_size    NUMERIC,
+    open_24h    NUMERIC,
+    high_24h    NUMERIC,
+    low_24h     NUMERIC,
+    volume_24h  NUMERIC,
+    CONSTRAINT ticks_pk PRIMARY KEY (timestamp, symbol, sequence)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    timestamp      INTEGER NOT NULL,
+    product_id     VARCHAR(8) NOT NULL,
+    type           VARCHAR(8) NOT NULL,
+    order_id       VARCHAR(64),
+    funds          NUMERIC, -- funds in USD
+    side           VARCHAR(8), -- buy or sell
+    size           NUMERIC, -- size of order
+    price          NUMERIC, -- price of order
+    order_type     VARCHAR(8), -- market or limit
+    client_oid     VARCHAR(64), -- client order id
+    sequence       INTEGER NOT NULL,
+    remaining_size NUMERIC,
+    reason         VARCHAR(64),
+    trade_id       INTEGER,
+    maker_order_id VARCHAR(64),
+    taker_order_id VARCHAR(64),
+    usd_value      NUMERIC, -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence)
+);
+
+CREATE INDEX IF NOT EXISTS orders_type_idx ON orders (type, timestamp);
+
+CREATE TABLE IF NOT EXISTS book_snapshots (
+    timestamp  INTEGER NOT NULL, -- time the snapshot was taken
+    product_id VARCHAR(16) NOT NULL,
+    best_bid   NUMERIC NOT NULL,
+    best_ask   NUMERIC NOT NULL,
+    mid        NUMERIC NOT NULL,
+    spread     NUMERIC NOT NULL,
+    depth_bps  REAL NOT NULL, -- band around mid used for bid_depth/ask_depth
+    bid_depth  NUMERIC NOT NULL,
+    ask_depth  NUMERIC NOT NULL,
+    bids       TEXT NOT NULL, -- json [[price, size], ...] best first
+    asks       TEXT NOT NULL, -- json [[price, size], ...] best first
+    CONSTRAINT book_snapshots_pk PRIMARY KEY (timestamp, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS feed_gaps (
+    product_id    VARCHAR(16) NOT NULL,
+    from_sequence INTEGER NOT NULL, -- first missing sequence
+    to_sequence   INTEGER NOT NULL, -- last missing sequence
+    missing       INTEGER NOT NULL,
+    start_time    INTEGER NOT NULL, -- time of the last message before the gap
+    end_time      INTEGER NOT NULL, -- time of the first message after the gap
+    source        VARCHAR(16) NOT NULL, -- message type that revealed the gap
+    detected_at   INTEGER NOT NULL,
+    CONSTRAINT feed_gaps_pk PRIMARY KEY (product_id, from_sequence, detected_at) -- a sequence can be missed again after the tracker is reset
+);
+
+CREATE INDEX IF NOT EXISTS feed_gaps_time_idx ON feed_gaps (start_time, end_time);
+
+CREATE TABLE IF NOT EXISTS candles (
+    product_id     VARCHAR(16) NOT NULL,
+    granularity    INTEGER NOT NULL, -- bar length in seconds
+    timestamp      INTEGER NOT NULL, -- bar start
+    open           NUMERIC NOT NULL,
+    high           NUMERIC NOT NULL,
+    low            NUMERIC NOT NULL,
+    close          NUMERIC NOT NULL,
+    volume         NUMERIC NOT NULL, -- base size traded
+    quote_volume   NUMERIC NOT NULL, -- sum of price * size
+    vwap           NUMERIC NOT NULL,
+    trades         INTEGER NOT NULL,
+    open_time      INTEGER NOT NULL, -- time of the first trade
+    open_trade_id  INTEGER NOT NULL,
+    close_time     INTEGER NOT NULL, -- time of the last trade
+    close_trade_id INTEGER NOT NULL,
+    CONSTRAINT candles_pk PRIMARY KEY (product_id, granularity, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS swap_transactions (
+    tx_hash              VARCHAR(66) NOT NULL,
+    version              VARCHAR(8) NOT NULL,
+    exchange             VARCHAR(100) NOT NULL, -- dex name (e.g. uniswap)
+    amount_in            VARCHAR(100) NOT NULL,
+    to_address           VARCHAR(42) NOT NULL,
+    token_path_from      VARCHAR(42) NOT NULL,
+    token_path_to        VARCHAR(42) NOT NULL,
+    value                NUMERIC NOT NULL DEFAULT 0, -- USD
+    amount_token_desired VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_token_min     VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_eth_min       VARCHAR(100), -- Uniswap V2 add liquidity
+    amount_out_min       VARCHAR(100), -- Uniswap V3 swap
+    amount_out           VARCHAR(100), -- Uniswap V2 swap
+    method_id            VARCHAR(10),
+    method_name          VARCHAR(100),
+    liquidity            VARCHAR(100), -- Uniswap V2 remove liquidity
+    last_updated         DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, -- UTC
+    token_a              VARCHAR(42), -- Uniswap V2 add/remove liquidity
+    token_b              VARCHAR(42), -- Uniswap V2 add/remove liquidity
+    amount_a_desired     VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_b_desired     VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_a_min         VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_b_min         VARCHAR(100), -- Uniswap V2 add/remove liquidity
+    amount_in_max        VARCHAR(100), -- Uniswap V3 swap
+    fee                  VARCHAR(100), -- Uniswap V3 swap
+    CONSTRAINT swap_transactions_pk PRIMARY KEY (tx_hash)
+);
+
+CREATE INDEX IF NOT EXISTS swap_transactions_value_idx ON swap_transactions (last_updated, value);
+
+CREATE TABLE IF NOT EXISTS token_metadata (
+    address      VARCHAR(42) NOT NULL,
+    decimals     INTEGER,
+    symbol       VARCHAR(10),
+    price        NUMERIC, -- USD, small caps trade far below a cent
+    last_updated DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (address)
+);
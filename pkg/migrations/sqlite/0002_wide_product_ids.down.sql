@@ -0,0 +1,52 @@
+-- sqlite does not enforce the length, the rebuilt tables keep longer product ids
+
+CREATE TABLE ticks_new (
+    timestamp   INTEGER NOT NULL, -- exchange time
+    symbol      VARCHAR(8) NOT NULL,
+    received_at INTEGER NOT NULL DEFAULT 0, -- receive time, received_at - timestamp is the feed latency
+    sequence    INTEGER NOT NULL DEFAULT 0, -- tickers of one taker order share their time
+    price       NUMERIC, -- last trade
+    last_size   NUMERIC,
+    side        VARCHAR(8), -- taker side of the last trade
+    trade_id    INTEGER,
+    bid         NUMERIC NOT NULL,
+    bid_size    NUMERIC,
+    ask         NUMERIC NOT NULL,
+    ask_size    NUMERIC,
+    open_24h    NUMERIC,
+    high_24h    NUMERIC,
+    low_24h     NUMERIC,
+    volume_24h  NUMERIC,
+    CONSTRAINT ticks_pk PRIMARY KEY (timestamp, symbol, sequence)
+);
+
+CREATE TABLE orders_new (
+    timestamp      INTEGER NOT NULL,
+    product_id     VARCHAR(8) NOT NULL,
+    type           VARCHAR(8) NOT NULL,
+    order_id       VARCHAR(64),
+    funds          NUMERIC, -- funds in USD
+    side           VARCHAR(8), -- buy or sell
+    size           NUMERIC, -- size of order
+    price          NUMERIC, -- price of order
+    order_type     VARCHAR(8), -- market or limit
+    client_oid     VARCHAR(64), -- client order id
+    sequence       INTEGER NOT NULL,
+    remaining_size NUMERIC,
+    reason         VARCHAR(64),
+    trade_id       INTEGER,
+    maker_order_id VARCHAR(64),
+    taker_order_id VARCHAR(64),
+    usd_value      NUMERIC, -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence)
+);
+
+INSERT INTO ticks_new SELECT * FROM ticks;
+DROP TABLE ticks;
+ALTER TABLE ticks_new RENAME TO ticks;
+
+INSERT INTO orders_new SELECT * FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_new RENAME TO orders;
+
+CREATE INDEX IF NOT EXISTS orders_type_idx ON orders (type, timestamp);
@@ -0,0 +1,28 @@
+-- trades that only differ by their trade id keep the first one stored
+
+CREATE TABLE orders_new (
+    timestamp      INTEGER NOT NULL,
+    product_id     VARCHAR(16) NOT NULL,
+    type           VARCHAR(8) NOT NULL,
+    order_id       VARCHAR(64),
+    funds          NUMERIC, -- funds in USD
+    side           VARCHAR(8), -- buy or sell
+    size           NUMERIC, -- size of order
+    price          NUMERIC, -- price of order
+    order_type     VARCHAR(8), -- market or limit
+    client_oid     VARCHAR(64), -- client order id
+    sequence       INTEGER NOT NULL,
+    remaining_size NUMERIC,
+    reason         VARCHAR(64),
+    trade_id       INTEGER,
+    maker_order_id VARCHAR(64),
+    taker_order_id VARCHAR(64),
+    usd_value      NUMERIC, -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence)
+);
+
+INSERT OR IGNORE INTO orders_new SELECT * FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_new RENAME TO orders;
+
+CREATE INDEX IF NOT EXISTS orders_type_idx ON orders (type, timestamp);
@@ -0,0 +1,30 @@
+-- Binance and Advanced Trade trades have no sequence, trades of one product in the same
+-- millisecond only differ by their trade id. The key includes it so they are not dropped
+-- as duplicates, orders without a trade have trade id 0
+
+CREATE TABLE orders_new (
+    timestamp      INTEGER NOT NULL,
+    product_id     VARCHAR(16) NOT NULL,
+    type           VARCHAR(8) NOT NULL,
+    order_id       VARCHAR(64),
+    funds          NUMERIC, -- funds in USD
+    side           VARCHAR(8), -- buy or sell
+    size           NUMERIC, -- size of order
+    price          NUMERIC, -- price of order
+    order_type     VARCHAR(8), -- market or limit
+    client_oid     VARCHAR(64), -- client order id
+    sequence       INTEGER NOT NULL,
+    remaining_size NUMERIC,
+    reason         VARCHAR(64),
+    trade_id       INTEGER NOT NULL DEFAULT 0,
+    maker_order_id VARCHAR(64),
+    taker_order_id VARCHAR(64),
+    usd_value      NUMERIC, -- notional in USD, null when no rate was known
+    CONSTRAINT orders_pk PRIMARY KEY (timestamp, product_id, type, sequence, trade_id)
+);
+
+INSERT INTO orders_new SELECT timestamp, product_id, type, order_id, funds, side, size, price, order_type, client_oid, sequence, remaining_size, reason, IFNULL(trade_id, 0), maker_order_id, taker_order_id, usd_value FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_new RENAME TO orders;
+
+CREATE INDEX IF NOT EXISTS orders_type_idx ON orders (type, timestamp);
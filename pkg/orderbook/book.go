@@ -0,0 +1,301 @@
+package orderbook
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// Buy side of the book
+	Buy = "buy"
+	// Sell side of the book
+	Sell = "sell"
+)
+
+// Level is an aggregated price level of the book
+type Level struct {
+	Price decimal.Decimal
+	Size  decimal.Decimal
+}
+
+// Depth is the resting liquidity around the mid price
+type Depth struct {
+	BidSize     decimal.Decimal
+	AskSize     decimal.Decimal
+	BidNotional decimal.Decimal
+	AskNotional decimal.Decimal
+}
+
+// levels of a side keyed by the canonical price string, 1.10 and 1.1 are the same level
+type levels map[string]Level
+
+// Book is an in-memory level2 order book of a single product
+type Book struct {
+	mu        sync.RWMutex
+	productID string
+	bids      levels
+	asks      levels
+	updated   time.Time
+}
+
+// NewBook init empty book for product
+func NewBook(productID string) *Book {
+	return &Book{
+		productID: productID,
+		bids:      make(levels),
+		asks:      make(levels),
+	}
+}
+
+// ProductID returns the product of the book
+func (b *Book) ProductID() string {
+	return b.productID
+}
+
+// UpdatedAt returns the exchange time of the last applied change
+func (b *Book) UpdatedAt() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.updated
+}
+
+// Reset replaces the book with the given levels
+func (b *Book) Reset(bids, asks []Level, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(levels, len(bids))
+	b.asks = make(levels, len(asks))
+	for _, l := range bids {
+		setLevel(b.bids, l.Price, l.Size)
+	}
+	for _, l := range asks {
+		setLevel(b.asks, l.Price, l.Size)
+	}
+	b.updated = t
+}
+
+// Set changes the size of a price level, a zero size removes the level
+func (b *Book) Set(side string, price, size decimal.Decimal, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if isBuy(side) {
+		setLevel(b.bids, price, size)
+	} else {
+		setLevel(b.asks, price, size)
+	}
+	b.updated = t
+}
+
+// Add changes the size of a price level by delta, the level is removed when it drops to zero
+func (b *Book) Add(side string, price, delta decimal.Decimal, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	levels := b.asks
+	if isBuy(side) {
+		levels = b.bids
+	}
+	setLevel(levels, price, levels[price.String()].Size.Add(delta))
+	b.updated = t
+}
+
+// Apply applies a snapshot or an update message to the book
+func (b *Book) Apply(update *entity.BookUpdate) {
+	if update.Snapshot {
+		var bids, asks []Level
+		for _, c := range update.Changes {
+			if isBuy(c.Side) {
+				bids = append(bids, Level{c.Price, c.Size})
+			} else {
+				asks = append(asks, Level{c.Price, c.Size})
+			}
+		}
+		b.Reset(bids, asks, update.Time)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range update.Changes {
+		if isBuy(c.Side) {
+			setLevel(b.bids, c.Price, c.Size)
+		} else {
+			setLevel(b.asks, c.Price, c.Size)
+		}
+	}
+	b.updated = update.Time
+}
+
+// Top returns the best n levels of each side, n <= 0 returns all levels
+func (b *Book) Top(n int) (bids, asks []Level) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return sortedLevels(b.bids, true, n), sortedLevels(b.asks, false, n)
+}
+
+// BestBid returns the highest bid level
+func (b *Book) BestBid() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return bestLevel(b.bids, true)
+}
+
+// BestAsk returns the lowest ask level
+func (b *Book) BestAsk() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return bestLevel(b.asks, false)
+}
+
+// Mid returns the mid price and spread, ok is false while a side is empty
+func (b *Book) Mid() (mid, spread decimal.Decimal, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.mid()
+}
+
+// DepthWithin returns the liquidity resting within bps basis points of the mid price
+func (b *Book) DepthWithin(bps float64) Depth {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.depthWithin(bps)
+}
+
+// Snapshot builds a persistable snapshot taken at the given time of the best levels
+// and the depth within bps, ok is false while a side of the book is empty
+func (b *Book) Snapshot(at time.Time, levels int, bps float64) (entity.BookSnapshot, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	mid, spread, ok := b.mid()
+	if !ok {
+		return entity.BookSnapshot{}, false
+	}
+
+	bids := sortedLevels(b.bids, true, levels)
+	asks := sortedLevels(b.asks, false, levels)
+	depth := b.depthWithin(bps)
+
+	return entity.BookSnapshot{
+		Timestamp: at.UnixNano(),
+		ProductID: b.productID,
+		BestBid:   bids[0].Price,
+		BestAsk:   asks[0].Price,
+		Mid:       mid,
+		Spread:    spread,
+		DepthBps:  bps,
+		BidDepth:  depth.BidSize,
+		AskDepth:  depth.AskSize,
+		Bids:      encodeLevels(bids),
+		Asks:      encodeLevels(asks),
+	}, true
+}
+
+func (b *Book) depthWithin(bps float64) Depth {
+	var d Depth
+	mid, _, ok := b.mid()
+	if !ok {
+		return d
+	}
+
+	band := mid.Mul(decimal.NewFromFloat(bps)).Div(decimal.NewFromInt(10000))
+	low := mid.Sub(band)
+	high := mid.Add(band)
+
+	for _, l := range b.bids {
+		if l.Price.GreaterThanOrEqual(low) {
+			d.BidSize = d.BidSize.Add(l.Size)
+			d.BidNotional = d.BidNotional.Add(l.Price.Mul(l.Size))
+		}
+	}
+	for _, l := range b.asks {
+		if l.Price.LessThanOrEqual(high) {
+			d.AskSize = d.AskSize.Add(l.Size)
+			d.AskNotional = d.AskNotional.Add(l.Price.Mul(l.Size))
+		}
+	}
+
+	return d
+}
+
+func (b *Book) mid() (mid, spread decimal.Decimal, ok bool) {
+	bid, bidOk := bestLevel(b.bids, true)
+	ask, askOk := bestLevel(b.asks, false)
+	if !bidOk || !askOk {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), ask.Price.Sub(bid.Price), true
+}
+
+func isBuy(side string) bool {
+	return strings.EqualFold(side, Buy)
+}
+
+// setLevel changes the size of a level, sizes are exact so removed orders empty their level
+func setLevel(side levels, price, size decimal.Decimal) {
+	if !size.IsPositive() {
+		delete(side, price.String())
+		return
+	}
+	side[price.String()] = Level{price, size}
+}
+
+func bestLevel(side levels, desc bool) (Level, bool) {
+	var best Level
+	found := false
+	for _, l := range side {
+		if !found || (desc && l.Price.GreaterThan(best.Price)) || (!desc && l.Price.LessThan(best.Price)) {
+			best = l
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func sortedLevels(side levels, desc bool, n int) []Level {
+	result := make([]Level, 0, len(side))
+	for _, l := range side {
+		result = append(result, l)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if desc {
+			return result[i].Price.GreaterThan(result[j].Price)
+		}
+		return result[i].Price.LessThan(result[j].Price)
+	})
+
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+
+	return result
+}
+
+// encodeLevels encodes levels as a JSON array of [price, size] pairs
+func encodeLevels(levels []Level) string {
+	pairs := make([][2]json.Number, len(levels))
+	for i, l := range levels {
+		pairs[i] = [2]json.Number{json.Number(l.Price.String()), json.Number(l.Size.String())}
+	}
+
+	data, _ := json.Marshal(pairs)
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dec keeps the test tables readable, floats are only used to spell exact values
+func dec(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
+func levelStrings(levels []Level) []string {
+	result := make([]string, len(levels))
+	for i, l := range levels {
+		result[i] = l.Price.String() + ":" + l.Size.String()
+	}
+	return result
+}
+
+func newTestBook() *Book {
+	book := NewBook("BTC-USD")
+	book.Apply(&entity.BookUpdate{
+		ProductID: "BTC-USD",
+		Snapshot:  true,
+		Changes: []entity.BookChange{
+			{Side: Buy, Price: dec(99), Size: dec(1)},
+			{Side: Buy, Price: dec(100), Size: dec(2)},
+			{Side: Buy, Price: dec(98), Size: dec(3)},
+			{Side: Sell, Price: dec(101), Size: dec(1.5)},
+			{Side: Sell, Price: dec(102), Size: dec(2.5)},
+			{Side: Sell, Price: dec(110), Size: dec(10)},
+		},
+	})
+
+	return book
+}
+
+func TestBook_Top(t *testing.T) {
+	book := newTestBook()
+
+	bids, asks := book.Top(2)
+	assert.Equal(t, []Level{{dec(100), dec(2)}, {dec(99), dec(1)}}, bids)
+	assert.Equal(t, []Level{{dec(101), dec(1.5)}, {dec(102), dec(2.5)}}, asks)
+
+	bids, asks = book.Top(0)
+	assert.Len(t, bids, 3)
+	assert.Len(t, asks, 3)
+}
+
+func TestBook_Apply(t *testing.T) {
+	book := newTestBook()
+
+	book.Apply(&entity.BookUpdate{
+		ProductID: "BTC-USD",
+		Time:      time.Unix(10, 0),
+		Changes: []entity.BookChange{
+			{Side: Buy, Price: dec(100), Size: dec(0)},    // remove best bid
+			{Side: Buy, Price: dec(99.5), Size: dec(4)},   // new best bid
+			{Side: Sell, Price: dec(101), Size: dec(0.5)}, // reduce best ask
+		},
+	})
+
+	bid, ok := book.BestBid()
+	require.True(t, ok)
+	assert.Equal(t, Level{dec(99.5), dec(4)}, bid)
+
+	ask, ok := book.BestAsk()
+	require.True(t, ok)
+	assert.Equal(t, Level{dec(101), dec(0.5)}, ask)
+
+	assert.Equal(t, time.Unix(10, 0), book.UpdatedAt())
+
+	// a new snapshot replaces every level
+	book.Apply(&entity.BookUpdate{
+		Snapshot: true,
+		Changes:  []entity.BookChange{{Side: Buy, Price: dec(50), Size: dec(1)}},
+	})
+	bids, asks := book.Top(0)
+	assert.Equal(t, []Level{{dec(50), dec(1)}}, bids)
+	assert.Empty(t, asks)
+}
+
+func TestBook_MidAndDepth(t *testing.T) {
+	book := newTestBook()
+
+	mid, spread, ok := book.Mid()
+	require.True(t, ok)
+	assert.Equal(t, "100.5", mid.String())
+	assert.Equal(t, "1", spread.String())
+
+	// 200 bps around 100.5 is [98.49, 102.51]
+	depth := book.DepthWithin(200)
+	assert.Equal(t, "3", depth.BidSize.String())
+	assert.Equal(t, "4", depth.AskSize.String())
+	assert.Equal(t, "299", depth.BidNotional.String())
+	assert.Equal(t, "406.5", depth.AskNotional.String())
+
+	_, _, ok = NewBook("ETH-USD").Mid()
+	assert.False(t, ok)
+}
+
+func TestBook_Snapshot(t *testing.T) {
+	book := newTestBook()
+	at := time.Unix(100, 0)
+
+	snapshot, ok := book.Snapshot(at, 1, 200)
+	require.True(t, ok)
+	assert.Equal(t, at.UnixNano(), snapshot.Timestamp)
+	assert.Equal(t, "BTC-USD", snapshot.ProductID)
+	assert.Equal(t, "100", snapshot.BestBid.String())
+	assert.Equal(t, "101", snapshot.BestAsk.String())
+	assert.Equal(t, "100.5", snapshot.Mid.String())
+	assert.Equal(t, "1", snapshot.Spread.String())
+	assert.Equal(t, 200.0, snapshot.DepthBps)
+	assert.Equal(t, "3", snapshot.BidDepth.String())
+	assert.Equal(t, "4", snapshot.AskDepth.String())
+	assert.Equal(t, "[[100,2]]", snapshot.Bids)
+	assert.Equal(t, "[[101,1.5]]", snapshot.Asks)
+
+	_, ok = NewBook("ETH-USD").Snapshot(at, 1, 200)
+	assert.False(t, ok)
+}
+
+func TestBooks(t *testing.T) {
+	books := NewBooks()
+	books.Apply(&entity.BookUpdate{ProductID: "ETH-USD", Changes: []entity.BookChange{{Side: Buy, Price: dec(1), Size: dec(1)}}})
+	books.Apply(&entity.BookUpdate{ProductID: "BTC-USD", Changes: []entity.BookChange{{Side: Sell, Price: dec(2), Size: dec(1)}}})
+
+	assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, books.Products())
+	assert.Same(t, books.Get("ETH-USD"), books.Get("ETH-USD"))
+}
@@ -0,0 +1,58 @@
+package orderbook
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// Books is a registry of per-product books
+type Books struct {
+	mu    sync.RWMutex
+	books map[string]*Book
+}
+
+// NewBooks init empty books registry
+func NewBooks() *Books {
+	return &Books{books: make(map[string]*Book)}
+}
+
+// Get returns the book of product, creating it when missing
+func (r *Books) Get(productID string) *Book {
+	r.mu.RLock()
+	book, ok := r.books[productID]
+	r.mu.RUnlock()
+	if ok {
+		return book
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if book, ok = r.books[productID]; !ok {
+		book = NewBook(productID)
+		r.books[productID] = book
+	}
+
+	return book
+}
+
+// Apply applies the update to the book of its product
+func (r *Books) Apply(update *entity.BookUpdate) {
+	r.Get(update.ProductID).Apply(update)
+}
+
+// Products returns the sorted products with a book
+func (r *Books) Products() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]string, 0, len(r.books))
+	for p := range r.books {
+		products = append(products, p)
+	}
+	sort.Strings(products)
+
+	return products
+}
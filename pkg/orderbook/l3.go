@@ -0,0 +1,327 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// maxPendingOrders bounds the messages buffered while a product waits for a resync
+	maxPendingOrders = 100000
+	// resyncDelay is the minimal delay between two snapshot loads of a product
+	resyncDelay = time.Second
+)
+
+// L3Order is a resting order of the full book
+type L3Order struct {
+	OrderID string
+	Side    string
+	Price   decimal.Decimal
+	Size    decimal.Decimal // remaining size
+}
+
+// L3Snapshot is the full book of a product at a sequence
+type L3Snapshot struct {
+	Sequence int64
+	Orders   []L3Order
+}
+
+// SnapshotSource loads the full book of a product, e.g. from the exchange REST api
+type SnapshotSource interface {
+	// L3Snapshot returns the current full book of product
+	L3Snapshot(ctx context.Context, productID string) (*L3Snapshot, error)
+}
+
+// L3Book is the full order book of a single product
+type L3Book struct {
+	mu          sync.Mutex
+	productID   string
+	resyncAfter time.Time
+	sequence    int64
+	synced      bool
+	orders      map[string]*L3Order
+	pending     []*entity.Order
+	gaps        int
+	view        *Book
+	// loading is set while a snapshot is fetched, err keeps the failure of the last fetch
+	loading bool
+	err     error
+}
+
+// L3Engine applies full channel messages to per-product full books
+// and keeps an aggregated level2 view of each of them in books
+type L3Engine struct {
+	mu     sync.Mutex
+	source SnapshotSource
+	books  *Books
+	l3     map[string]*L3Book
+	loads  sync.WaitGroup
+}
+
+// NewL3Engine init full book engine, the aggregated views are kept in books
+func NewL3Engine(source SnapshotSource, books *Books) *L3Engine {
+	return &L3Engine{
+		source: source,
+		books:  books,
+		l3:     make(map[string]*L3Book),
+	}
+}
+
+// Books returns the aggregated level2 views
+func (e *L3Engine) Books() *Books {
+	return e.books
+}
+
+// Apply applies a full channel message in sequence order. The first message of a product
+// and every detected sequence gap trigger a resync from the snapshot source in the background,
+// messages received meanwhile are buffered and replayed on top of the snapshot.
+// A failed snapshot load is returned by the next Apply of the product
+func (e *L3Engine) Apply(ctx context.Context, order *entity.Order) error {
+	book := e.book(order.ProductID)
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	seq := int64(order.Sequence)
+
+	if book.synced {
+		switch {
+		case seq <= book.sequence:
+			// duplicate or late message, already part of the book
+			return nil
+		case seq == book.sequence+1:
+			book.apply(order)
+			return nil
+		default:
+			book.synced = false
+			book.gaps++
+		}
+	}
+
+	if len(book.pending) >= maxPendingOrders {
+		book.pending = book.pending[1:]
+	}
+	book.pending = append(book.pending, order)
+
+	e.resync(ctx, book)
+
+	err := book.err
+	book.err = nil
+	return err
+}
+
+// Sequence returns the last applied sequence of product and whether its book is in sync
+func (e *L3Engine) Sequence(productID string) (int64, bool) {
+	book, ok := e.lookup(productID)
+	if !ok {
+		return 0, false
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	return book.sequence, book.synced
+}
+
+// Gaps returns the number of sequence gaps detected for product
+func (e *L3Engine) Gaps(productID string) int {
+	book, ok := e.lookup(productID)
+	if !ok {
+		return 0
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	return book.gaps
+}
+
+// Order returns a resting order of product
+func (e *L3Engine) Order(productID, orderID string) (L3Order, bool) {
+	book, ok := e.lookup(productID)
+	if !ok {
+		return L3Order{}, false
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	o, ok := book.orders[orderID]
+	if !ok {
+		return L3Order{}, false
+	}
+
+	return *o, true
+}
+
+func (e *L3Engine) lookup(productID string) (*L3Book, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	book, ok := e.l3[productID]
+	return book, ok
+}
+
+func (e *L3Engine) book(productID string) *L3Book {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	book, ok := e.l3[productID]
+	if !ok {
+		book = &L3Book{
+			productID: productID,
+			orders:    make(map[string]*L3Order),
+			view:      e.books.Get(productID),
+		}
+		e.l3[productID] = book
+	}
+
+	return book
+}
+
+// resync starts loading the snapshot of book unless one is loading or was loaded
+// less than resyncDelay ago, the book lock is held by the caller and not while loading
+func (e *L3Engine) resync(ctx context.Context, book *L3Book) {
+	if book.loading || time.Now().Before(book.resyncAfter) {
+		return
+	}
+	book.resyncAfter = time.Now().Add(resyncDelay)
+	book.loading = true
+
+	e.loads.Add(1)
+	go func() {
+		defer e.loads.Done()
+
+		snapshot, err := e.source.L3Snapshot(ctx, book.productID)
+
+		book.mu.Lock()
+		defer book.mu.Unlock()
+
+		book.loading = false
+		if err != nil {
+			book.err = fmt.Errorf("failed to load %s snapshot: %w", book.productID, err)
+			return
+		}
+		if !book.replay(snapshot) {
+			// a gap inside the buffer
+			e.resync(ctx, book)
+		}
+	}()
+}
+
+// wait returns once the snapshots being loaded are applied
+func (e *L3Engine) wait() {
+	e.loads.Wait()
+}
+
+// replay swaps snapshot in and applies the buffered messages on top of it,
+// false when the buffered messages have a gap
+func (b *L3Book) replay(snapshot *L3Snapshot) bool {
+	sort.SliceStable(b.pending, func(i, j int) bool {
+		return b.pending[i].Sequence < b.pending[j].Sequence
+	})
+
+	// the snapshot is older than the buffered messages, keep waiting for a newer one
+	if len(b.pending) > 0 && int64(b.pending[0].Sequence) > snapshot.Sequence+1 {
+		return true
+	}
+
+	b.load(snapshot)
+
+	for i, order := range b.pending {
+		seq := int64(order.Sequence)
+		if seq <= b.sequence {
+			continue
+		}
+		if seq != b.sequence+1 {
+			// the messages after the gap wait for the next snapshot
+			b.synced = false
+			b.gaps++
+			b.pending = b.pending[i:]
+			return false
+		}
+		b.apply(order)
+	}
+	b.pending = nil
+
+	return true
+}
+
+func (b *L3Book) load(snapshot *L3Snapshot) {
+	b.orders = make(map[string]*L3Order, len(snapshot.Orders))
+
+	var bids, asks []Level
+	for _, o := range snapshot.Orders {
+		order := o
+		b.orders[order.OrderID] = &order
+		if isBuy(order.Side) {
+			bids = append(bids, Level{order.Price, order.Size})
+		} else {
+			asks = append(asks, Level{order.Price, order.Size})
+		}
+	}
+
+	b.view.Reset(aggregate(bids), aggregate(asks), time.Now())
+	b.sequence = snapshot.Sequence
+	b.synced = true
+	b.resyncAfter = time.Time{}
+}
+
+func (b *L3Book) apply(order *entity.Order) {
+	b.sequence = int64(order.Sequence)
+	t := time.Unix(0, order.Timestamp)
+
+	switch strings.ToLower(order.Type) {
+	case "open":
+		resting := &L3Order{
+			OrderID: order.OrderID,
+			Side:    order.Side,
+			Price:   order.Price,
+			Size:    order.RemainingSize,
+		}
+		b.orders[order.OrderID] = resting
+		b.view.Add(resting.Side, resting.Price, resting.Size, t)
+
+	case "done":
+		if resting, ok := b.orders[order.OrderID]; ok {
+			delete(b.orders, order.OrderID)
+			b.view.Add(resting.Side, resting.Price, resting.Size.Neg(), t)
+		}
+
+	case "match":
+		if resting, ok := b.orders[order.MakerOrderID]; ok {
+			resting.Size = resting.Size.Sub(order.Size)
+			b.view.Add(resting.Side, resting.Price, order.Size.Neg(), t)
+		}
+
+	case "change":
+		if resting, ok := b.orders[order.OrderID]; ok && order.NewSize.IsPositive() {
+			delta := order.NewSize.Sub(resting.Size)
+			resting.Size = order.NewSize
+			b.view.Add(resting.Side, resting.Price, delta, t)
+		}
+	}
+	// received messages never rest on the book, they only advance the sequence
+}
+
+func aggregate(levels []Level) []Level {
+	sizes := make(map[string]Level, len(levels))
+	for _, l := range levels {
+		key := l.Price.String()
+		sizes[key] = Level{l.Price, sizes[key].Size.Add(l.Size)}
+	}
+
+	result := make([]Level, 0, len(sizes))
+	for _, l := range sizes {
+		result = append(result, l)
+	}
+
+	return result
+}
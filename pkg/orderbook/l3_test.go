@@ -0,0 +1,150 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSource serves prepared snapshots in order, the last one is repeated
+type stubSource struct {
+	snapshots []*L3Snapshot
+	calls     int
+	err       error
+}
+
+func (s *stubSource) L3Snapshot(_ context.Context, _ string) (*L3Snapshot, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	i := s.calls
+	if i >= len(s.snapshots) {
+		i = len(s.snapshots) - 1
+	}
+	s.calls++
+
+	return s.snapshots[i], nil
+}
+
+func testSnapshot(seq int64) *L3Snapshot {
+	return &L3Snapshot{
+		Sequence: seq,
+		Orders: []L3Order{
+			{OrderID: "b1", Side: Buy, Price: dec(100), Size: dec(1)},
+			{OrderID: "b2", Side: Buy, Price: dec(100), Size: dec(2)},
+			{OrderID: "a1", Side: Sell, Price: dec(101), Size: dec(1.5)},
+		},
+	}
+}
+
+func TestL3Engine_Apply(t *testing.T) {
+	ctx := context.Background()
+	source := &stubSource{snapshots: []*L3Snapshot{testSnapshot(10)}}
+	engine := NewL3Engine(source, NewBooks())
+
+	// first message loads the snapshot, older messages are dropped
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 9}))
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "open", Sequence: 11, OrderID: "a2", Side: Sell, Price: dec(101), RemainingSize: dec(3)}))
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "match", Sequence: 12, MakerOrderID: "b2", TakerOrderID: "t1", Side: Buy, Price: dec(100), Size: dec(0.5)}))
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "change", Sequence: 13, OrderID: "b1", NewSize: dec(0.25), OldSize: dec(1)}))
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "done", Sequence: 14, OrderID: "a1", Reason: "canceled"}))
+	// duplicate is ignored
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "done", Sequence: 14, OrderID: "a2", Reason: "canceled"}))
+	engine.wait()
+
+	seq, synced := engine.Sequence("BTC-USD")
+	assert.Equal(t, int64(14), seq)
+	assert.True(t, synced)
+	assert.Equal(t, 1, source.calls)
+	assert.Equal(t, 0, engine.Gaps("BTC-USD"))
+
+	order, ok := engine.Order("BTC-USD", "b2")
+	require.True(t, ok)
+	assert.Equal(t, "1.5", order.Size.String())
+
+	_, ok = engine.Order("BTC-USD", "a1")
+	assert.False(t, ok)
+
+	bids, asks := engine.Books().Get("BTC-USD").Top(0)
+	// sizes are summed, compared by value rather than representation
+	assert.Equal(t, []string{"100:1.75"}, levelStrings(bids))
+	assert.Equal(t, []string{"101:3"}, levelStrings(asks))
+}
+
+func TestL3Engine_GapResync(t *testing.T) {
+	ctx := context.Background()
+	resynced := &L3Snapshot{
+		Sequence: 20,
+		Orders:   []L3Order{{OrderID: "b9", Side: Buy, Price: dec(99), Size: dec(4)}, {OrderID: "a9", Side: Sell, Price: dec(102), Size: dec(1)}},
+	}
+	source := &stubSource{snapshots: []*L3Snapshot{testSnapshot(10), resynced}}
+	engine := NewL3Engine(source, NewBooks())
+
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 11}))
+
+	// 12..18 are lost, the next message triggers a resync
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 19}))
+	// 21 is applied on top of the new snapshot
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "done", Sequence: 21, OrderID: "a9"}))
+	engine.wait()
+
+	seq, synced := engine.Sequence("BTC-USD")
+	assert.Equal(t, int64(21), seq)
+	assert.True(t, synced)
+	assert.Equal(t, 1, engine.Gaps("BTC-USD"))
+	assert.Equal(t, 2, source.calls)
+
+	bids, asks := engine.Books().Get("BTC-USD").Top(0)
+	assert.Equal(t, []Level{{dec(99), dec(4)}}, bids)
+	assert.Empty(t, asks)
+}
+
+func TestL3Engine_SnapshotError(t *testing.T) {
+	engine := NewL3Engine(&stubSource{err: errors.New("unavailable")}, NewBooks())
+
+	require.NoError(t, engine.Apply(context.Background(), &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 1}))
+	engine.wait()
+
+	// the failed load is returned by the next message
+	err := engine.Apply(context.Background(), &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 2})
+	assert.ErrorContains(t, err, "unavailable")
+
+	_, synced := engine.Sequence("BTC-USD")
+	assert.False(t, synced)
+}
+
+// blockingSource serves its snapshot once release is closed
+type blockingSource struct {
+	release  chan struct{}
+	snapshot *L3Snapshot
+}
+
+func (s *blockingSource) L3Snapshot(ctx context.Context, _ string) (*L3Snapshot, error) {
+	<-s.release
+	return s.snapshot, nil
+}
+
+func TestL3Engine_LoadOutsideLock(t *testing.T) {
+	ctx := context.Background()
+	source := &blockingSource{release: make(chan struct{}), snapshot: testSnapshot(10)}
+	engine := NewL3Engine(source, NewBooks())
+
+	// the messages arriving while the snapshot loads are buffered, the book stays readable
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "received", Sequence: 11}))
+	require.NoError(t, engine.Apply(ctx, &entity.Order{ProductID: "BTC-USD", Type: "done", Sequence: 12, OrderID: "a1"}))
+	_, synced := engine.Sequence("BTC-USD")
+	assert.False(t, synced)
+
+	close(source.release)
+	engine.wait()
+
+	seq, synced := engine.Sequence("BTC-USD")
+	assert.Equal(t, int64(12), seq)
+	assert.True(t, synced)
+	_, ok := engine.Order("BTC-USD", "a1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// secretsManager is the AWS Secrets Manager API used by the aws provider
+type secretsManager interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// AWSSecret is a JSON secret of AWS Secrets Manager, its keys are the upper cased
+// names of the secrets prefixed with Prefix
+type AWSSecret struct {
+	ID     string
+	Prefix string
+}
+
+var prefixPattern = regexp.MustCompile(`^[A-Z0-9_]+$`)
+
+// ParseAWSSecret parses a secret id with an optional prefix of its keys, PREFIX=id.
+// The password key of an RDS managed secret DB_=rds!db-... is DB_PASSWORD
+func ParseAWSSecret(spec string) AWSSecret {
+	if prefix, id, ok := strings.Cut(spec, "="); ok && prefixPattern.MatchString(prefix) {
+		return AWSSecret{ID: id, Prefix: prefix}
+	}
+	return AWSSecret{ID: spec}
+}
+
+type awsProvider struct {
+	region  string
+	secrets []AWSSecret
+
+	mu     sync.Mutex
+	client secretsManager
+}
+
+// NewAWSProvider reads the secrets from the JSON secrets of AWS Secrets Manager in region,
+// specs as parsed by ParseAWSSecret. The AWS credentials are only needed on first lookup
+func NewAWSProvider(region string, specs []string) *awsProvider {
+	var secrets []AWSSecret
+	for _, spec := range specs {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			secrets = append(secrets, ParseAWSSecret(spec))
+		}
+	}
+	return &awsProvider{region: region, secrets: secrets}
+}
+
+func (a *awsProvider) Get(ctx context.Context, name string) (string, error) {
+	client, err := a.connect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// a failing secret doesn't hide the key from the next ones
+	var errs []error
+	for _, secret := range a.secrets {
+		if !strings.HasPrefix(name, secret.Prefix) {
+			continue
+		}
+
+		out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secret.ID)})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get aws secret %s: %w", secret.ID, err))
+			continue
+		}
+		if out.SecretString == nil {
+			continue
+		}
+
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(*out.SecretString), &values); err != nil {
+			errs = append(errs, fmt.Errorf("parse aws secret %s: %w", secret.ID, err))
+			continue
+		}
+		for key, value := range values {
+			if secret.Prefix+strings.ToUpper(key) != name || value == nil {
+				continue
+			}
+			if s := fmt.Sprint(value); s != "" {
+				return s, nil
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return "", ErrNotFound
+}
+
+func (a *awsProvider) connect(ctx context.Context) (secretsManager, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.client == nil {
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(a.region))
+		if err != nil {
+			return nil, fmt.Errorf("unable to load SDK config: %w", err)
+		}
+		a.client = secretsmanager.NewFromConfig(cfg)
+	}
+	return a.client, nil
+}
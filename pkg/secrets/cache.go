@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type cached struct {
+	value   string
+	fetched time.Time
+}
+
+type cache struct {
+	provider SecretProvider
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	values map[string]cached
+}
+
+// NewCache keeps the secrets of provider, a secret is looked up again once older than refresh
+// so a rotated one is picked up, 0 keeps them. When the lookup fails for another reason than
+// ErrNotFound the value already known is served
+func NewCache(provider SecretProvider, refresh time.Duration) *cache {
+	return &cache{
+		provider: provider,
+		refresh:  refresh,
+		now:      time.Now,
+		values:   make(map[string]cached),
+	}
+}
+
+func (c *cache) Get(ctx context.Context, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known, ok := c.values[name]
+	if ok && (c.refresh <= 0 || c.now().Sub(known.fetched) < c.refresh) {
+		return known.value, nil
+	}
+
+	value, err := c.provider.Get(ctx, name)
+	if err != nil {
+		if ok && !errors.Is(err, ErrNotFound) {
+			return known.value, nil
+		}
+		delete(c.values, name)
+		return "", err
+	}
+
+	c.values[name] = cached{value: value, fetched: c.now()}
+	return value, nil
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type envProvider struct{}
+
+// NewEnvProvider reads the secrets from the environment variables of their names
+func NewEnvProvider() *envProvider {
+	return &envProvider{}
+}
+
+func (envProvider) Get(_ context.Context, name string) (string, error) {
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+type fileProvider struct {
+	path string
+}
+
+// NewFileProvider reads the secrets from the JSON object of the file at path,
+// the file is read on every lookup so an edit is picked up on refresh
+func NewFileProvider(path string) *fileProvider {
+	return &fileProvider{path: path}
+}
+
+func (f *fileProvider) Get(_ context.Context, name string) (string, error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("read secrets file: %w", err)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(b, &values); err != nil {
+		return "", fmt.Errorf("parse secrets file %s: %w", f.path, err)
+	}
+	if value, ok := values[name]; ok && value != "" {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+type mountedProvider struct {
+	dir string
+}
+
+// NewMountedProvider reads the secrets mounted as files in dir by Docker (/run/secrets)
+// or a Kubernetes secret volume, the file of a secret is its name or its name in lower case
+func NewMountedProvider(dir string) *mountedProvider {
+	return &mountedProvider{dir: dir}
+}
+
+func (m *mountedProvider) Get(_ context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name != filepath.Base(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	for _, file := range []string{name, strings.ToLower(name)} {
+		b, err := os.ReadFile(filepath.Join(m.dir, file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("read mounted secret: %w", err)
+		}
+		// editors and kubectl leave a trailing newline
+		if value := strings.TrimRight(string(b), "\r\n"); value != "" {
+			return value, nil
+		}
+	}
+	return "", ErrNotFound
+}
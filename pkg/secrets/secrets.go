@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a provider has no secret of the name
+var ErrNotFound = errors.New("secret not found")
+
+// Providers of New
+const (
+	Env     = "env"
+	File    = "file"
+	Mounted = "mounted"
+	AWS     = "aws"
+)
+
+// SecretProvider looks up secrets by name, e.g. DB_PASSWORD
+type SecretProvider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Options of the providers chained by New
+type Options struct {
+	File       string        // JSON object of the file provider, names to values
+	Dir        string        // directory of the mounted provider, a file per secret
+	AWSRegion  string        // region of the aws provider
+	AWSSecrets []string      // secret ids of the aws provider, see NewAWSProvider. The provider is left out without them
+	Refresh    time.Duration // values are looked up again after Refresh, 0 keeps them
+}
+
+// New chains the named providers in order behind a cache
+func New(providers []string, opts Options) (SecretProvider, error) {
+	var chain []SecretProvider
+	for _, name := range providers {
+		switch name {
+		case Env:
+			chain = append(chain, NewEnvProvider())
+		case File:
+			if opts.File == "" {
+				return nil, errors.New("secrets: file provider without a file")
+			}
+			chain = append(chain, NewFileProvider(opts.File))
+		case Mounted:
+			chain = append(chain, NewMountedProvider(opts.Dir))
+		case AWS:
+			// without secret ids there is nothing to look up
+			if len(opts.AWSSecrets) > 0 {
+				chain = append(chain, NewAWSProvider(opts.AWSRegion, opts.AWSSecrets))
+			}
+		case "":
+		default:
+			return nil, fmt.Errorf("secrets: unknown provider %q", name)
+		}
+	}
+	return NewCache(NewChain(chain...), opts.Refresh), nil
+}
+
+type chain struct {
+	providers []SecretProvider
+}
+
+// NewChain looks a secret up in providers in order, the first one having it wins.
+// A failing provider does not hide the secret of the next ones
+func NewChain(providers ...SecretProvider) *chain {
+	return &chain{providers: providers}
+}
+
+func (c *chain) Get(ctx context.Context, name string) (string, error) {
+	var errs []error
+	for _, provider := range c.providers {
+		value, err := provider.Get(ctx, name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("secret %s: %w", name, errors.Join(errs...))
+	}
+	return "", fmt.Errorf("secret %s: %w", name, ErrNotFound)
+}
+
+var (
+	mu           sync.RWMutex
+	defaultChain SecretProvider = NewEnvProvider()
+)
+
+// SetDefault replaces the provider of Get, the environment until set
+func SetDefault(provider SecretProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultChain = provider
+}
+
+// Get looks name up in the default provider
+func Get(ctx context.Context, name string) (string, error) {
+	mu.RLock()
+	provider := defaultChain
+	mu.RUnlock()
+	return provider.Get(ctx, name)
+}
+
+// GetAll looks every name up in the default provider, all of them are needed
+func GetAll(ctx context.Context, names ...string) ([]string, error) {
+	values := make([]string, len(names))
+	for i, name := range names {
+		value, err := Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secrets.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"DB_PASSWORD":"from-file","MORALIS_API_KEY":"moralis"}`), 0o600))
+	mounted := filepath.Join(dir, "run")
+	require.NoError(t, os.Mkdir(mounted, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(mounted, "service_role_key"), []byte("mounted\n"), 0o600))
+	t.Setenv("DB_PASSWORD", "from-env")
+
+	provider, err := New([]string{Env, File, Mounted}, Options{File: file, Dir: mounted})
+	require.NoError(t, err)
+
+	value, err := provider.Get(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+	value, err = provider.Get(ctx, "MORALIS_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "moralis", value)
+	value, err = provider.Get(ctx, "SERVICE_ROLE_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "mounted", value)
+
+	_, err = provider.Get(ctx, "SUPABASE_URL")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = provider.Get(ctx, "../secrets.json")
+	assert.Error(t, err)
+
+	// no AWS lookup without secret ids
+	provider, err = New([]string{AWS}, Options{AWSRegion: "us-east-2"})
+	require.NoError(t, err)
+	_, err = provider.Get(ctx, "DB_PASSWORD")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = New([]string{"vault"}, Options{})
+	assert.Error(t, err)
+	_, err = New([]string{File}, Options{})
+	assert.Error(t, err)
+}
+
+type fakeProvider struct {
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (f *fakeProvider) Get(_ context.Context, name string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	if value, ok := f.values[name]; ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func TestChain(t *testing.T) {
+	down := &fakeProvider{err: errors.New("down")}
+	chain := NewChain(down, &fakeProvider{values: map[string]string{"KEY": "value"}})
+
+	// a failing provider does not hide the next ones
+	value, err := chain.Get(context.Background(), "KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	_, err = chain.Get(context.Background(), "OTHER")
+	assert.ErrorContains(t, err, "down")
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{values: map[string]string{"KEY": "v1"}}
+	c := NewCache(provider, time.Minute)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+
+	value, _ := c.Get(ctx, "KEY")
+	assert.Equal(t, "v1", value)
+	provider.values["KEY"] = "v2"
+	value, _ = c.Get(ctx, "KEY")
+	assert.Equal(t, "v1", value)
+	assert.Equal(t, 1, provider.calls)
+
+	// rotated
+	now = now.Add(time.Minute)
+	value, _ = c.Get(ctx, "KEY")
+	assert.Equal(t, "v2", value)
+
+	// the known value is served while the provider is down
+	now = now.Add(time.Minute)
+	provider.err = errors.New("down")
+	value, err := c.Get(ctx, "KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	// but not once it is gone
+	provider.err = nil
+	delete(provider.values, "KEY")
+	_, err = c.Get(ctx, "KEY")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+type fakeSecretsManager map[string]string
+
+func (f fakeSecretsManager) GetSecretValue(_ context.Context, in *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, ok := f[*in.SecretId]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(secret)}, nil
+}
+
+func TestAWSProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewAWSProvider("us-east-2", []string{"prod/supabase/coinbase", "DB_=rds!db-1"})
+	provider.client = fakeSecretsManager{
+		"prod/supabase/coinbase": `{"COINBASE_WS_API_KEY":"key","SUPABASE_URL":"https://supabase.co"}`,
+		"rds!db-1":               `{"username":"admin","password":"secret"}`,
+	}
+
+	value, err := provider.Get(ctx, "COINBASE_WS_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "key", value)
+	value, err = provider.Get(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = provider.Get(ctx, "MORALIS_API_KEY")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// a missing secret doesn't hide the next one
+	provider = NewAWSProvider("us-east-2", []string{"prod/missing", "prod/supabase/coinbase"})
+	provider.client = fakeSecretsManager{"prod/supabase/coinbase": `{"COINBASE_WS_API_KEY":"key"}`}
+	value, err = provider.Get(ctx, "COINBASE_WS_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "key", value)
+
+	_, err = provider.Get(ctx, "MORALIS_API_KEY")
+	assert.ErrorContains(t, err, "get aws secret prod/missing")
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestParseAWSSecret(t *testing.T) {
+	assert.Equal(t, AWSSecret{ID: "rds!db-1", Prefix: "DB_"}, ParseAWSSecret("DB_=rds!db-1"))
+	assert.Equal(t, AWSSecret{ID: "prod/coinbase"}, ParseAWSSecret("prod/coinbase"))
+	assert.Equal(t, AWSSecret{ID: "name=with=equals"}, ParseAWSSecret("name=with=equals"))
+	assert.Equal(t, AWSSecret{ID: "arn:aws:secretsmanager:us-east-2:1:secret:db", Prefix: "DB_"},
+		ParseAWSSecret("DB_=arn:aws:secretsmanager:us-east-2:1:secret:db"))
+}
@@ -0,0 +1,222 @@
+package sequence
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+)
+
+// windowSize is the number of recent sequences remembered per product
+// to tell duplicates from late deliveries
+const windowSize = 4096
+
+// Status of an observed sequence
+type Status int
+
+const (
+	// InOrder is the next expected sequence
+	InOrder Status = iota
+	// First is the first sequence seen for the product
+	First
+	// Gap skipped one or more sequences
+	Gap
+	// Duplicate was already delivered
+	Duplicate
+	// OutOfOrder is an older sequence delivered late
+	OutOfOrder
+)
+
+var statusNames = [...]string{
+	"in_order",
+	"first",
+	"gap",
+	"duplicate",
+	"out_of_order",
+}
+
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// Stats counters of a product feed
+type Stats struct {
+	Messages   int64
+	Gaps       int64
+	Missing    int64
+	Duplicates int64
+	OutOfOrder int64
+	Last       int64
+}
+
+type product struct {
+	started  bool
+	last     int64
+	lastTime int64
+	seen     map[int64]struct{}
+	ring     []int64
+	next     int
+	stats    Stats
+}
+
+// Tracker tracks the sequence of every product feed
+type Tracker struct {
+	mu       sync.Mutex
+	products map[string]*product
+	now      func() time.Time
+}
+
+// NewTracker init sequence tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		products: make(map[string]*product),
+		now:      time.Now,
+	}
+}
+
+// Observe checks a sequence delivered for product at time t (unix nano),
+// a gap returns the missing range
+func (t *Tracker) Observe(productID string, seq int64, at int64, source string) (Status, *entity.FeedGap) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.products[productID]
+	if !ok {
+		p = newProduct()
+		t.products[productID] = p
+	}
+	p.stats.Messages++
+
+	if !p.started {
+		p.started = true
+		p.advance(seq, at)
+		return First, nil
+	}
+
+	switch {
+	case seq == p.last+1:
+		p.advance(seq, at)
+		return InOrder, nil
+
+	case seq > p.last+1:
+		gap := &entity.FeedGap{
+			ProductID:    productID,
+			FromSequence: p.last + 1,
+			ToSequence:   seq - 1,
+			Missing:      seq - 1 - p.last,
+			StartTime:    p.lastTime,
+			EndTime:      at,
+			Source:       source,
+			DetectedAt:   t.now().UnixNano(),
+		}
+		p.stats.Gaps++
+		p.stats.Missing += gap.Missing
+		p.advance(seq, at)
+		return Gap, gap
+
+	default:
+		if _, seen := p.seen[seq]; seen || seq == p.last {
+			p.stats.Duplicates++
+			return Duplicate, nil
+		}
+		p.stats.OutOfOrder++
+		p.remember(seq)
+		return OutOfOrder, nil
+	}
+}
+
+// Checkpoint checks the latest sequence announced for product (e.g. by a heartbeat),
+// everything up to seq should have been delivered already. Products without an
+// observed message are not checked since their channels may skip sequences.
+func (t *Tracker) Checkpoint(productID string, seq int64, at int64, source string) *entity.FeedGap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.products[productID]
+	if !ok || !p.started || seq <= p.last {
+		return nil
+	}
+
+	gap := &entity.FeedGap{
+		ProductID:    productID,
+		FromSequence: p.last + 1,
+		ToSequence:   seq,
+		Missing:      seq - p.last,
+		StartTime:    p.lastTime,
+		EndTime:      at,
+		Source:       source,
+		DetectedAt:   t.now().UnixNano(),
+	}
+	p.stats.Gaps++
+	p.stats.Missing += gap.Missing
+	p.last = seq
+	p.lastTime = at
+	p.stats.Last = seq
+
+	return gap
+}
+
+// Reset forgets the sequence of product, e.g. after a reconnect
+func (t *Tracker) Reset(productID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.products[productID]; ok {
+		// counters survive the reset, the sequence does not
+		reset := newProduct()
+		reset.stats = p.stats
+		t.products[productID] = reset
+	}
+}
+
+// Stats returns the counters of product
+func (t *Tracker) Stats(productID string) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.products[productID]; ok {
+		return p.stats
+	}
+
+	return Stats{}
+}
+
+// Products returns the sorted tracked products
+func (t *Tracker) Products() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	products := make([]string, 0, len(t.products))
+	for id := range t.products {
+		products = append(products, id)
+	}
+	sort.Strings(products)
+
+	return products
+}
+
+func newProduct() *product {
+	return &product{
+		seen: make(map[int64]struct{}, windowSize),
+		ring: make([]int64, 0, windowSize),
+	}
+}
+
+func (p *product) advance(seq int64, at int64) {
+	p.last = seq
+	p.lastTime = at
+	p.stats.Last = seq
+	p.remember(seq)
+}
+
+func (p *product) remember(seq int64) {
+	if len(p.ring) < windowSize {
+		p.ring = append(p.ring, seq)
+	} else {
+		delete(p.seen, p.ring[p.next])
+		p.ring[p.next] = seq
+		p.next = (p.next + 1) % windowSize
+	}
+	p.seen[seq] = struct{}{}
+}
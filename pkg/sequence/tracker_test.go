@@ -0,0 +1,99 @@
+package sequence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nel349/bz-findata/pkg/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker() *Tracker {
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return time.Unix(0, 999) }
+
+	return tracker
+}
+
+func TestTracker_Observe(t *testing.T) {
+	tracker := newTestTracker()
+
+	tests := []struct {
+		name    string
+		product string
+		seq     int64
+		want    Status
+		wantGap *entity.FeedGap
+	}{
+		{name: "first", product: "BTC-USD", seq: 10, want: First},
+		{name: "in order", product: "BTC-USD", seq: 11, want: InOrder},
+		{name: "other product is independent", product: "ETH-USD", seq: 500, want: First},
+		{name: "duplicate of last", product: "BTC-USD", seq: 11, want: Duplicate},
+		{name: "gap", product: "BTC-USD", seq: 15, want: Gap, wantGap: &entity.FeedGap{
+			ProductID: "BTC-USD", FromSequence: 12, ToSequence: 14, Missing: 3, StartTime: 11, EndTime: 15, Source: "match", DetectedAt: 999,
+		}},
+		{name: "late delivery", product: "BTC-USD", seq: 13, want: OutOfOrder},
+		{name: "duplicate of late delivery", product: "BTC-USD", seq: 13, want: Duplicate},
+		{name: "duplicate of older", product: "BTC-USD", seq: 10, want: Duplicate},
+		{name: "in order after gap", product: "BTC-USD", seq: 16, want: InOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the sequence doubles as message time to check the gap window
+			status, gap := tracker.Observe(tt.product, tt.seq, tt.seq, "match")
+			assert.Equal(t, tt.want, status)
+			assert.Equal(t, tt.wantGap, gap)
+		})
+	}
+
+	assert.Equal(t, Stats{Messages: 8, Gaps: 1, Missing: 3, Duplicates: 3, OutOfOrder: 1, Last: 16}, tracker.Stats("BTC-USD"))
+	assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, tracker.Products())
+}
+
+func TestTracker_Checkpoint(t *testing.T) {
+	tracker := newTestTracker()
+
+	// nothing observed yet, channels like ticker skip sequences
+	assert.Nil(t, tracker.Checkpoint("BTC-USD", 100, 1, "heartbeat"))
+
+	tracker.Observe("BTC-USD", 100, 1, "open")
+	assert.Nil(t, tracker.Checkpoint("BTC-USD", 100, 2, "heartbeat"))
+
+	gap := tracker.Checkpoint("BTC-USD", 103, 3, "heartbeat")
+	require.NotNil(t, gap)
+	assert.Equal(t, int64(101), gap.FromSequence)
+	assert.Equal(t, int64(103), gap.ToSequence)
+	assert.Equal(t, int64(3), gap.Missing)
+
+	status, _ := tracker.Observe("BTC-USD", 104, 4, "open")
+	assert.Equal(t, InOrder, status)
+}
+
+func TestTracker_Reset(t *testing.T) {
+	tracker := newTestTracker()
+	tracker.Observe("BTC-USD", 100, 1, "open")
+	tracker.Observe("BTC-USD", 102, 2, "open")
+
+	tracker.Reset("BTC-USD")
+
+	status, gap := tracker.Observe("BTC-USD", 5000, 3, "open")
+	assert.Equal(t, First, status)
+	assert.Nil(t, gap)
+	assert.Equal(t, int64(1), tracker.Stats("BTC-USD").Gaps)
+}
+
+func TestTracker_Window(t *testing.T) {
+	tracker := newTestTracker()
+	for seq := int64(1); seq <= windowSize+10; seq++ {
+		tracker.Observe("BTC-USD", seq, seq, "open")
+	}
+
+	// sequences older than the window are no longer known
+	status, _ := tracker.Observe("BTC-USD", 5, 5, "open")
+	assert.Equal(t, OutOfOrder, status)
+
+	status, _ = tracker.Observe("BTC-USD", windowSize, windowSize, "open")
+	assert.Equal(t, Duplicate, status)
+}